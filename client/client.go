@@ -0,0 +1,235 @@
+// Package client is a typed Go client for the college API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// on network errors, 5xx and 429 responses; POST requests, which aren't
+// idempotent, are only retried on 429 and when the connection couldn't be
+// made, as any later error may come after the server acted on them.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first, 1 disables retries
+	BaseDelay   time.Duration // delay before the first retry, doubled for each retry
+	MaxDelay    time.Duration // cap on the delay between attempts
+}
+
+// DefaultRetryPolicy makes up to 3 attempts starting 200ms apart.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// Client calls the college API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retry      RetryPolicy
+	authorize  func(ctx context.Context, req *http.Request) error
+	userAgent  string
+	term       int // term of rosters and people's courses, 0 for the current term
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the http client used to send requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithAPIKey sends key in the X-API-Key header on every request.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.authorize = func(_ context.Context, req *http.Request) error {
+			req.Header.Set("X-API-Key", key)
+			return nil
+		}
+	}
+}
+
+// WithBearerToken sends the token returned by source as a bearer token on
+// every request, so short-lived tokens can be refreshed between calls.
+func WithBearerToken(source func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.authorize = func(ctx context.Context, req *http.Request) error {
+			token, err := source(ctx)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// New returns a client for the API at baseURL, e.g. http://localhost:8000.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, errors.New("client: base url must be absolute, e.g. http://localhost:8000")
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+		userAgent:  "college-api-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// InTerm returns a copy of c whose rosters, waitlists, enrollments and
+// people's courses are in the term with termID rather than the current term.
+// InTerm(0) goes back to the current term.
+func (c *Client) InTerm(termID int) *Client {
+	copied := *c
+	copied.term = termID
+	return &copied
+}
+
+// Add the term set by InTerm to query, which may be nil.
+func (c *Client) termQuery(query url.Values) url.Values {
+	if c.term <= 0 {
+		return query
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("term", strconv.Itoa(c.term))
+	return query
+}
+
+// Build the url for path, which must already be escaped, and query.
+func (c *Client) url(path string, query url.Values) string {
+	u := c.baseURL.String() + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// Delay before retry number attempt (1 based), honoring Retry-After.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	delay := c.retry.BaseDelay << (attempt - 1)
+	if c.retry.MaxDelay > 0 && (delay > c.retry.MaxDelay || delay <= 0) {
+		delay = c.retry.MaxDelay
+	}
+	// up to 20% jitter so clients don't retry in lockstep
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	}
+	return delay
+}
+
+func retryable(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		return method != http.MethodPost || notSent(err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode >= 500 && method != http.MethodPost
+}
+
+// Report whether err shows the request never reached the server, because
+// connecting to it failed.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Send a request with body encoded as JSON, retrying per the retry policy,
+// and decode a successful JSON response into out. Error responses are
+// returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.authorize != nil {
+			if err := c.authorize(ctx, req); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if attempt < attempts && retryable(method, resp, err) {
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			timer := time.NewTimer(c.backoff(attempt, resp))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return parseError(resp)
+		}
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return &Error{StatusCode: resp.StatusCode, Message: "decoding response: " + err.Error()}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,501 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/routes"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+)
+
+const testAdminKey = "test-admin-key"
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+// Start the real router on an httptest server backed by a mock database.
+// wrap, when set, wraps the router so tests can inject failures.
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	r := chi.NewRouter()
+	routes.GetRoutes(r, &handlers.RequestHandler{DB: db, AdminKey: testAdminKey})
+	var handler http.Handler = r
+	if wrap != nil {
+		handler = wrap(r)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, mock
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, opts ...Option) *Client {
+	c, err := New(server.URL, append([]Option{WithAPIKey(testAdminKey), fastRetries}, opts...)...)
+	assert.NoError(t, err)
+	return c
+}
+
+// TestCourses tests the course methods and the pagination iterator.
+func TestCourses(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	capacity, credits := 30, 3
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course$").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false).AddRow(2, "Art", 30, nil, false))
+	courses, err := c.ListCourses(ctx, Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Course{{1, "Math", nil, nil, false}, {2, "Art", &capacity, nil, false}}, courses)
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course ORDER BY id LIMIT \\$1$").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false).AddRow(2, "Art", nil, nil, false))
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course ORDER BY id LIMIT \\$1 OFFSET \\$2").WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(3, "Music", nil, nil, false))
+	var names []string
+	for course, err := range c.Courses(ctx, 2) {
+		assert.NoError(t, err)
+		names = append(names, course.Name)
+	}
+	assert.Equal(t, []string{"Math", "Art", "Music"}, names)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO course \\(name, capacity, credits, requires_instructor\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id").WithArgs("History", 30, 3, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	storetest.ExpectEvent(mock, "course.created")
+	mock.ExpectCommit()
+	course, err := c.CreateCourse(ctx, "History", &capacity, &credits, false)
+	assert.NoError(t, err)
+	assert.Equal(t, Course{4, "History", &capacity, &credits, false}, course)
+
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 4, nil)
+	mock.ExpectQuery("DELETE FROM person_course WHERE course_id = \\$1 RETURNING person_id, term_id").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "term_id"}))
+	mock.ExpectQuery("DELETE FROM course WHERE id = \\$1").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("History"))
+	storetest.ExpectEvent(mock, "course.deleted")
+	mock.ExpectCommit()
+	assert.NoError(t, c.DeleteCourse(ctx, 4))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPeople tests the person methods and typed validation errors.
+func TestPeople(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
+		WithArgs("Ada Lovelace").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(3, "Ada", "Lovelace", "student", 36))
+	storetest.ExpectPersonCourses(mock, sqlmock.NewRows([]string{"person_id", "course_id", "role"}).AddRow(3, 1, "student"), 3)
+	storetest.ExpectPersonWaitlist(mock, sqlmock.NewRows([]string{"person_id", "course_id", "position"}).AddRow(3, 2, 1), 3)
+	person, err := c.GetPerson(ctx, "Ada Lovelace")
+	assert.NoError(t, err)
+	assert.Equal(t, Person{ID: 3, FirstName: "Ada", LastName: "Lovelace", Type: "student", Age: 36, Courses: []int{1}, Roles: []string{"student"}, Waitlisted: []int{2}, TermID: 2}, person)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO person").WithArgs("Alan", "Turing", "professor", 41).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectEvent(mock, "person.created")
+	storetest.ExpectLockCourse(mock, 2, nil)
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(6, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	storetest.ExpectNoClashes(mock, 2, 6)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(6, 2, 2, "instructor").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+	id, err := c.CreatePerson(ctx, PersonInput{FirstName: "Alan", LastName: "Turing", Type: "professor", Age: 41, Courses: []int{2}})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, id)
+
+	// rejected by validation before reaching the database
+	_, err = c.CreatePerson(ctx, PersonInput{FirstName: "Alan", LastName: "Turing", Type: "janitor", Age: 41})
+	assert.ErrorIs(t, err, ErrBadRequest)
+	var apiErr *Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, []FieldError{{In: "body", Pointer: "/type", Detail: "value must be one of 'professor', 'student'"}}, apiErr.Fields)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestEnrollment tests the roster and waitlist helpers and not found
+// errors.
+func TestEnrollment(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 1, 1)
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(3, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	mock.ExpectQuery("FROM course_prerequisite cp").WithArgs(1, 3, "2026-01-12").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}))
+	storetest.ExpectNoClashes(mock, 1, 3)
+	mock.ExpectQuery("SELECT \\(SELECT count").WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"enrolled", "waiting"}).AddRow(1, 0))
+	mock.ExpectExec("INSERT INTO waitlist").WithArgs(3, 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "waitlist.added")
+	mock.ExpectCommit()
+	enrollment, err := c.Enroll(ctx, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, Enrollment{PersonID: 3, CourseID: 1, TermID: 2, Role: "student", Status: "waitlisted", Position: 1}, enrollment)
+	assert.True(t, enrollment.Waitlisted())
+
+	created := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("FROM waitlist wl").WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "created_at"}).
+			AddRow(3, "Ada", "Lovelace", "student", 36, created))
+	waitlist, err := c.Waitlist(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []WaitlistEntry{{Position: 1, Person: Person{ID: 3, FirstName: "Ada", LastName: "Lovelace", Type: "student", Age: 36}, CreatedAt: created}}, waitlist)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	// a past term's roster
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end"}).AddRow(1, "Fall 2025", "2025-09-01", "2025-12-19"))
+	mock.ExpectQuery("SELECT p.id, p.first_name").WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "role"}).AddRow(3, "Ada", "Lovelace", "student", 36, "student"))
+	roster, err := c.InTerm(1).Roster(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, roster, 1)
+	assert.Equal(t, "Ada Lovelace", roster[0].FullName())
+	assert.Equal(t, RoleStudent, roster[0].Role)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 1, 1)
+	mock.ExpectRollback()
+	_, err = c.EnrollAs(ctx, 1, 3, RoleInstructor)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	assert.Contains(t, err.Error(), "Only professors can be instructors")
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 1, 1)
+	mock.ExpectExec("DELETE FROM person_course").WithArgs(4, 1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM waitlist").WithArgs(4, 1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	err = c.Unenroll(ctx, 1, 4)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "Person is not on the course roster")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPrerequisites tests managing prerequisites, the conflict of a cycle and
+// enrolling past missing prerequisites.
+func TestPrerequisites(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT cp.course_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor FROM course_prerequisite cp").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"course_id", "id", "name", "capacity", "credits", "requires_instructor"}).AddRow(2, 1, "Programming", nil, nil, false))
+	courses, err := c.Prerequisites(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []Course{{ID: 1, Name: "Programming"}}, courses)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE course_prerequisite").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\), EXISTS").WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"course", "prerequisite"}).AddRow(true, true))
+	mock.ExpectQuery("WITH RECURSIVE required").WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	err = c.AddPrerequisite(ctx, 1, 2)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, nil)
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(3, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	storetest.ExpectNoClashes(mock, 2, 3)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(3, 2, 2, "student").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+	enrollment, err := c.EnrollOverride(ctx, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "enrolled", enrollment.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMeetings tests listing a course's schedule and a clashing meeting
+// failing with ErrConflict.
+func TestMeetings(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("FROM course_meeting WHERE course_id IN").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "course_id", "day", "start", "end", "location"}).
+			AddRow(1, 1, "monday", "09:00", "10:30", "Room 101"))
+	meetings, err := c.Meetings(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Meeting{{ID: 1, CourseID: 1, Day: "monday", Start: "09:00", End: "10:30", Location: "Room 101"}}, meetings)
+
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 3, 3)
+	mock.ExpectExec("LOCK TABLE course_meeting").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("WITH courses").WithArgs(3, "monday", "10:00", "11:00").
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "id", "name", "day", "start", "end"}).
+			AddRow(4, 1, "Programming", "monday", "09:00", "10:30"))
+	mock.ExpectRollback()
+	_, err = c.AddMeeting(ctx, 3, Meeting{Day: "monday", Start: "10:00", End: "11:00"})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTerms tests the term methods and the conflict of overlapping terms.
+func TestTerms(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	storetest.ExpectTerm(mock, 0)
+	term, err := c.CurrentTerm(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Term{ID: 2, Name: "Spring 2026", Start: "2026-01-12", End: "2026-05-15"}, term)
+
+	fall := Term{Name: "Fall 2026", Start: "2026-09-01", End: "2026-12-18"}
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE term").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM term WHERE id <> \\$1").WithArgs(0, fall.Name, fall.Start, fall.End).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end"}))
+	mock.ExpectQuery("INSERT INTO term").WithArgs(fall.Name, fall.Start, fall.End).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	storetest.ExpectEvent(mock, "term.created")
+	mock.ExpectCommit()
+	created, err := c.CreateTerm(ctx, fall)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, created.ID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM term WHERE id = \\$1 FOR UPDATE").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "start", "end"}).AddRow("Spring 2026", "2026-01-12", "2026-05-15"))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person_course WHERE term_id = \\$1\\)").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(true))
+	mock.ExpectRollback()
+	err = c.DeleteTerm(ctx, 2)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGrades tests replacing the grade scale, that admins can't grade and
+// reading a GPA and transcript.
+func TestGrades(t *testing.T) {
+	server, mock := newTestServer(t, nil)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM grade_scale").WillReturnResult(sqlmock.NewResult(0, 11))
+	mock.ExpectExec("INSERT INTO grade_scale").WithArgs("PASS", 1.0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO grade_scale").WithArgs("FAIL", 0.0).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "grade_scale.updated")
+	mock.ExpectCommit()
+	scale, err := c.SetGradeScale(ctx, []GradeStep{{"fail", 0}, {"pass", 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, []GradeStep{{"PASS", 1}, {"FAIL", 0}}, scale)
+
+	_, err = c.SetGrade(ctx, 1, 3, "PASS")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(2, "Spring 2026", 4, 1.0).
+			AddRow(nil, nil, 4, 1.0))
+	gpa, err := c.GPA(ctx, 3)
+	assert.NoError(t, err)
+	one := 1.0
+	assert.Equal(t, GPA{
+		PersonID:   3,
+		Terms:      []TermGPA{{TermID: 2, Term: "Spring 2026", Credits: 4, GPA: &one}},
+		Cumulative: TermGPA{Credits: 4, GPA: &one},
+	}, gpa)
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE id = \\$1").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(3, "Ann", "Lee", "student", 20))
+	mock.ExpectQuery("FROM person_course pc").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end", "course_id", "course", "credits", "grade", "points"}).
+			AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15", 1, "Logic", 4, "PASS", 1.0))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(2, "Spring 2026", 4, 1.0).
+			AddRow(nil, nil, 4, 1.0))
+	transcript, err := c.Transcript(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, Transcript{
+		Person: Person{ID: 3, FirstName: "Ann", LastName: "Lee", Type: "student", Age: 20},
+		Terms: []TranscriptTerm{{
+			TermID: 2, Term: "Spring 2026", Start: "2026-01-12", End: "2026-05-15",
+			Courses: []TranscriptCourse{{CourseID: 1, Name: "Logic", Credits: 4, Grade: "PASS", Points: &one}},
+			Credits: 4, GradedCredits: 4, GPA: &one,
+		}},
+		Totals: TranscriptTotals{Courses: 1, Credits: 4, GradedCredits: 4, GPA: &one},
+	}, transcript)
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(1, "Ada", "Byron", "professor", 36))
+	_, err = c.Transcript(ctx, 1)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthentication tests api key and bearer token injection.
+func TestAuthentication(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+
+	c, err := New(server.URL, fastRetries)
+	assert.NoError(t, err)
+	_, err = c.ListCourses(context.Background(), Page{})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	var header string
+	capture := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		w.Write([]byte("[]"))
+	}))
+	defer capture.Close()
+	c, err = New(capture.URL, WithBearerToken(func(context.Context) (string, error) { return "token-1", nil }))
+	assert.NoError(t, err)
+	_, err = c.ListCourses(context.Background(), Page{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", header)
+}
+
+// TestRetries tests backoff on 5xx and 429 responses.
+func TestRetries(t *testing.T) {
+	var failures, calls atomic.Int32
+	flaky := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			switch failures.Add(-1) {
+			case 1:
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			case 0:
+				http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+	server, mock := newTestServer(t, flaky)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	// a 429 then a 503 before succeeding
+	failures.Store(2)
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false))
+	courses, err := c.ListCourses(ctx, Page{})
+	assert.NoError(t, err)
+	assert.Len(t, courses, 1)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// attempts run out
+	failures.Store(3)
+	calls.Store(0)
+	_, err = c.ListCourses(ctx, Page{})
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// POST isn't retried on a 5xx
+	failures.Store(1)
+	calls.Store(0)
+	_, err = c.CreateCourse(ctx, "History", nil, nil, false)
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// a canceled context stops retrying
+	failures.Store(2)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = c.ListCourses(canceled, Page{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRetriesAfterSending tests that a POST whose connection drops after the
+// server read it isn't sent again, while a GET is, and that a POST that
+// couldn't connect is.
+func TestRetriesAfterSending(t *testing.T) {
+	var calls atomic.Int32
+	hangUp := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			io.Copy(io.Discard, r.Body)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			conn.Close()
+		})
+	}
+	server, mock := newTestServer(t, hangUp)
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	_, err := c.CreateCourse(ctx, "History", nil, nil, false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	_, err = c.ListCourses(ctx, Page{})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// nothing listens on a closed server, so each attempt fails to connect
+	var dials atomic.Int32
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}}
+	c = newTestClient(t, closed, WithHTTPClient(&http.Client{Transport: transport}))
+	_, err = c.CreateCourse(ctx, "History", nil, nil, false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), dials.Load())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
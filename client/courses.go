@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// Page selects a slice of a list ordered by id. The zero Page lists
+// everything.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+func (p Page) query(q url.Values) url.Values {
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		q.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return q
+}
+
+// default page size of the iterators
+const defaultPageSize = 100
+
+// Fetch pages of pageSize until a short page, yielding each item.
+func paginate[T any](ctx context.Context, pageSize int, fetch func(context.Context, Page) ([]T, error)) iter.Seq2[T, error] {
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	return func(yield func(T, error) bool) {
+		for offset := 0; ; offset += pageSize {
+			items, err := fetch(ctx, Page{Limit: pageSize, Offset: offset})
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < pageSize {
+				return
+			}
+		}
+	}
+}
+
+// ListCourses returns the courses in page.
+func (c *Client) ListCourses(ctx context.Context, page Page) ([]Course, error) {
+	var courses []Course
+	err := c.do(ctx, "GET", "/api/course", page.query(url.Values{}), nil, &courses)
+	return courses, err
+}
+
+// Courses iterates over every course, fetching pageSize at a time. Iteration
+// stops after the first error.
+func (c *Client) Courses(ctx context.Context, pageSize int) iter.Seq2[Course, error] {
+	return paginate(ctx, pageSize, c.ListCourses)
+}
+
+// GetCourse returns the course with id.
+func (c *Client) GetCourse(ctx context.Context, id int) (Course, error) {
+	var course Course
+	err := c.do(ctx, "GET", "/api/course/"+strconv.Itoa(id), nil, nil, &course)
+	return course, err
+}
+
+// CreateCourse adds a course and returns it with its new id. capacity limits
+// how many students may enroll, nil for no limit, credits weigh its grades
+// in a GPA, nil counting 1, and requiresInstructor keeps students out of
+// terms nobody instructs it.
+func (c *Client) CreateCourse(ctx context.Context, name string, capacity, credits *int, requiresInstructor bool) (Course, error) {
+	var course Course
+	body := Course{Name: name, Capacity: capacity, Credits: credits, RequiresInstructor: requiresInstructor}
+	err := c.do(ctx, "POST", "/api/course", nil, body, &course)
+	return course, err
+}
+
+// UpdateCourse renames the course with id and replaces its capacity,
+// credits and whether it requires an instructor. A nil capacity removes the
+// limit, enrolling everyone on its waitlist.
+func (c *Client) UpdateCourse(ctx context.Context, id int, name string, capacity, credits *int, requiresInstructor bool) (Course, error) {
+	var course Course
+	body := Course{Name: name, Capacity: capacity, Credits: credits, RequiresInstructor: requiresInstructor}
+	err := c.do(ctx, "PUT", "/api/course/"+strconv.Itoa(id), nil, body, &course)
+	return course, err
+}
+
+// DeleteCourse removes the course with id.
+func (c *Client) DeleteCourse(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", "/api/course/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+// Roster returns everyone enrolled in or teaching the course in the term
+// with their role.
+func (c *Client) Roster(ctx context.Context, courseID int) ([]RosterMember, error) {
+	var people []RosterMember
+	err := c.do(ctx, "GET", "/api/course/"+strconv.Itoa(courseID)+"/roster", c.termQuery(nil), nil, &people)
+	return people, err
+}
+
+// Waitlist returns the people waiting for a seat in the course in the term,
+// first in line first.
+func (c *Client) Waitlist(ctx context.Context, courseID int) ([]WaitlistEntry, error) {
+	var waitlist []WaitlistEntry
+	err := c.do(ctx, "GET", "/api/course/"+strconv.Itoa(courseID)+"/waitlist", c.termQuery(nil), nil, &waitlist)
+	return waitlist, err
+}
+
+// Enroll adds the person to the course's roster in the term with the
+// default role of their type, or to its waitlist when the course is full;
+// see Enrollment.Waitlisted. Enrolling someone already on either succeeds
+// without change. Students missing any of the course's prerequisites, or the
+// instructor it requires, fail with ErrConflict.
+func (c *Client) Enroll(ctx context.Context, courseID, personID int) (Enrollment, error) {
+	var enrollment Enrollment
+	err := c.do(ctx, "POST", "/api/course/"+strconv.Itoa(courseID)+"/roster", c.termQuery(nil), Enrollment{PersonID: personID}, &enrollment)
+	return enrollment, err
+}
+
+// EnrollAs enrolls like Enroll with role, one of RoleInstructor, RoleStudent
+// or RoleTA. Only professors can be instructors. Someone already on the
+// roster with another role fails with ErrConflict.
+func (c *Client) EnrollAs(ctx context.Context, courseID, personID int, role string) (Enrollment, error) {
+	var enrollment Enrollment
+	err := c.do(ctx, "POST", "/api/course/"+strconv.Itoa(courseID)+"/roster", c.termQuery(nil), Enrollment{PersonID: personID, Role: role}, &enrollment)
+	return enrollment, err
+}
+
+// EnrollOverride enrolls like Enroll, but even when a student is missing
+// the course's prerequisites, which only admins may do.
+func (c *Client) EnrollOverride(ctx context.Context, courseID, personID int) (Enrollment, error) {
+	var enrollment Enrollment
+	query := c.termQuery(url.Values{"override_prerequisites": {"true"}})
+	err := c.do(ctx, "POST", "/api/course/"+strconv.Itoa(courseID)+"/roster", query, Enrollment{PersonID: personID}, &enrollment)
+	return enrollment, err
+}
+
+// Unenroll removes the person from the course's roster or waitlist in the
+// term.
+func (c *Client) Unenroll(ctx context.Context, courseID, personID int) error {
+	return c.do(ctx, "DELETE", "/api/course/"+strconv.Itoa(courseID)+"/roster/"+strconv.Itoa(personID), c.termQuery(nil), nil, nil)
+}
+
+// Prerequisites returns the courses students must complete before enrolling
+// in the course.
+func (c *Client) Prerequisites(ctx context.Context, courseID int) ([]Course, error) {
+	var courses []Course
+	err := c.do(ctx, "GET", "/api/course/"+strconv.Itoa(courseID)+"/prerequisites", nil, nil, &courses)
+	return courses, err
+}
+
+// AddPrerequisite requires the course prerequisiteID to be completed before
+// courseID. Prerequisites that would form a cycle fail with ErrConflict.
+func (c *Client) AddPrerequisite(ctx context.Context, courseID, prerequisiteID int) error {
+	return c.do(ctx, "POST", "/api/course/"+strconv.Itoa(courseID)+"/prerequisites", nil, Prerequisite{PrerequisiteID: prerequisiteID}, nil)
+}
+
+// RemovePrerequisite stops requiring the course prerequisiteID before
+// courseID.
+func (c *Client) RemovePrerequisite(ctx context.Context, courseID, prerequisiteID int) error {
+	return c.do(ctx, "DELETE", "/api/course/"+strconv.Itoa(courseID)+"/prerequisites/"+strconv.Itoa(prerequisiteID), nil, nil, nil)
+}
+
+// Meetings returns the course's weekly meetings in schedule order.
+func (c *Client) Meetings(ctx context.Context, courseID int) ([]Meeting, error) {
+	var meetings []Meeting
+	err := c.do(ctx, "GET", "/api/course/"+strconv.Itoa(courseID)+"/meetings", nil, nil, &meetings)
+	return meetings, err
+}
+
+// AddMeeting adds a weekly meeting to the course and returns it with its id.
+// Meetings that clash with the schedule of anyone on the course fail with
+// ErrConflict.
+func (c *Client) AddMeeting(ctx context.Context, courseID int, meeting Meeting) (Meeting, error) {
+	var added Meeting
+	err := c.do(ctx, "POST", "/api/course/"+strconv.Itoa(courseID)+"/meetings", nil, meeting, &added)
+	return added, err
+}
+
+// RemoveMeeting removes a weekly meeting from the course.
+func (c *Client) RemoveMeeting(ctx context.Context, courseID, meetingID int) error {
+	return c.do(ctx, "DELETE", "/api/course/"+strconv.Itoa(courseID)+"/meetings/"+strconv.Itoa(meetingID), nil, nil, nil)
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors matched by errors.Is against an *Error's status code.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// FieldError is one problem reported by the API's request validation.
+type FieldError struct {
+	In      string `json:"in"` // body, path or query
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// Error is an error response from the API.
+type Error struct {
+	StatusCode int
+	Message    string       // the plain text error, or the problem title
+	Fields     []FieldError // set for request validation failures
+	RetryAfter int          // seconds, set on 429 responses
+}
+
+func (e *Error) Error() string {
+	msg := "college api: " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	for _, field := range e.Fields {
+		msg += "; " + field.In + " " + field.Pointer + ": " + field.Detail
+	}
+	return msg
+}
+
+// Is reports whether target is the sentinel error for e's status code.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Build an *Error from a plain text or problem+json error response.
+func parseError(resp *http.Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	e.RetryAfter, _ = strconv.Atoi(resp.Header.Get("Retry-After"))
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/problem+json" {
+		var problem struct {
+			Title  string       `json:"title"`
+			Errors []FieldError `json:"errors"`
+		}
+		if json.Unmarshal(body, &problem) == nil {
+			e.Message = problem.Title
+			e.Fields = problem.Errors
+			return e
+		}
+	}
+	e.Message = strings.TrimSpace(string(body))
+	return e
+}
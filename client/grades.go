@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"strconv"
+)
+
+// GradeScale returns the letters grades can be given in, best first.
+func (c *Client) GradeScale(ctx context.Context) ([]GradeStep, error) {
+	var scale []GradeStep
+	err := c.do(ctx, "GET", "/api/grade-scale", nil, nil, &scale)
+	return scale, err
+}
+
+// SetGradeScale replaces the grade scale and returns it. Grades already
+// given keep their points.
+func (c *Client) SetGradeScale(ctx context.Context, scale []GradeStep) ([]GradeStep, error) {
+	var updated []GradeStep
+	err := c.do(ctx, "PUT", "/api/grade-scale", nil, scale, &updated)
+	return updated, err
+}
+
+// SetGrade grades the student on the course's roster in the term with a
+// letter from the grade scale. Only the course's professors may.
+func (c *Client) SetGrade(ctx context.Context, courseID, personID int, letter string) (Grade, error) {
+	var grade Grade
+	path := "/api/course/" + strconv.Itoa(courseID) + "/roster/" + strconv.Itoa(personID) + "/grade"
+	err := c.do(ctx, "PUT", path, c.termQuery(nil), Grade{Letter: letter}, &grade)
+	return grade, err
+}
+
+// GPA returns the person's GPA in each term they were graded in and across
+// all of them, weighted by course credits.
+func (c *Client) GPA(ctx context.Context, personID int) (GPA, error) {
+	var gpa GPA
+	err := c.do(ctx, "GET", "/api/person/"+strconv.Itoa(personID)+"/gpa", nil, nil, &gpa)
+	return gpa, err
+}
+
+// Transcript returns every course the student took by term with their
+// grades, credits and GPAs. Professors have no transcript.
+func (c *Client) Transcript(ctx context.Context, personID int) (Transcript, error) {
+	var transcript Transcript
+	err := c.do(ctx, "GET", "/api/person/"+strconv.Itoa(personID)+"/transcript", nil, nil, &transcript)
+	return transcript, err
+}
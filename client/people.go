@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// path for the person with fullName
+func personPath(fullName string) string {
+	return "/api/person/" + url.PathEscape(fullName)
+}
+
+// ListPeople returns the people matching filter in page, with their courses
+// in the term.
+func (c *Client) ListPeople(ctx context.Context, filter PersonFilter, page Page) ([]Person, error) {
+	query := c.termQuery(url.Values{})
+	if filter.Name != "" {
+		query.Set("name", filter.Name)
+	}
+	if filter.Age != nil {
+		query.Set("age", strconv.Itoa(*filter.Age))
+	}
+
+	var people []Person
+	err := c.do(ctx, "GET", "/api/person", page.query(query), nil, &people)
+	return people, err
+}
+
+// People iterates over everyone matching filter, fetching pageSize at a
+// time. Iteration stops after the first error.
+func (c *Client) People(ctx context.Context, filter PersonFilter, pageSize int) iter.Seq2[Person, error] {
+	return paginate(ctx, pageSize, func(ctx context.Context, page Page) ([]Person, error) {
+		return c.ListPeople(ctx, filter, page)
+	})
+}
+
+// GetPerson returns the person named fullName ("first last") with their
+// courses in the term.
+func (c *Client) GetPerson(ctx context.Context, fullName string) (Person, error) {
+	var person Person
+	err := c.do(ctx, "GET", personPath(fullName), c.termQuery(nil), nil, &person)
+	return person, err
+}
+
+// CreatePerson adds a person, enrolling them in input.Courses in the term,
+// and returns their new id.
+func (c *Client) CreatePerson(ctx context.Context, input PersonInput) (int, error) {
+	var created struct {
+		ID int `json:"id"`
+	}
+	err := c.do(ctx, "POST", "/api/person", c.termQuery(nil), input, &created)
+	return created.ID, err
+}
+
+// UpdatePerson replaces the person named fullName, including their courses
+// in the term.
+func (c *Client) UpdatePerson(ctx context.Context, fullName string, input PersonInput) (Person, error) {
+	var person Person
+	err := c.do(ctx, "PUT", personPath(fullName), c.termQuery(nil), input, &person)
+	return person, err
+}
+
+// DeletePerson removes the person named fullName and their enrollments.
+func (c *Client) DeletePerson(ctx context.Context, fullName string) error {
+	return c.do(ctx, "DELETE", personPath(fullName), nil, nil, nil)
+}
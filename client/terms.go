@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"strconv"
+)
+
+// Terms returns every term in date order.
+func (c *Client) Terms(ctx context.Context) ([]Term, error) {
+	var terms []Term
+	err := c.do(ctx, "GET", "/api/term", nil, nil, &terms)
+	return terms, err
+}
+
+// CurrentTerm returns the current term, the latest to have started.
+func (c *Client) CurrentTerm(ctx context.Context) (Term, error) {
+	var term Term
+	err := c.do(ctx, "GET", "/api/term/current", nil, nil, &term)
+	return term, err
+}
+
+// GetTerm returns the term with id.
+func (c *Client) GetTerm(ctx context.Context, id int) (Term, error) {
+	var term Term
+	err := c.do(ctx, "GET", "/api/term/"+strconv.Itoa(id), nil, nil, &term)
+	return term, err
+}
+
+// CreateTerm adds a term and returns it with its new id. Terms sharing a
+// name with or overlapping another fail with ErrConflict.
+func (c *Client) CreateTerm(ctx context.Context, term Term) (Term, error) {
+	var created Term
+	err := c.do(ctx, "POST", "/api/term", nil, term, &created)
+	return created, err
+}
+
+// UpdateTerm replaces the name and dates of the term with id.
+func (c *Client) UpdateTerm(ctx context.Context, id int, term Term) (Term, error) {
+	var updated Term
+	err := c.do(ctx, "PUT", "/api/term/"+strconv.Itoa(id), nil, term, &updated)
+	return updated, err
+}
+
+// DeleteTerm removes the term with id. Terms anyone is enrolled in fail with
+// ErrConflict.
+func (c *Client) DeleteTerm(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", "/api/term/"+strconv.Itoa(id), nil, nil, nil)
+}
@@ -0,0 +1,190 @@
+package client
+
+import "time"
+
+// Course is a course offered by the college.
+type Course struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Capacity *int   `json:"capacity,omitempty"` // most students enrolled at once, nil for no limit
+	Credits  *int   `json:"credits,omitempty"`  // weight of its grades in a GPA, nil counts 1
+
+	// students can only enroll in terms someone instructs the course
+	RequiresInstructor bool `json:"requires_instructor,omitempty"`
+}
+
+// Person is a student or professor. Courses holds the ids of the courses
+// they are enrolled in or teach in the term with TermID; it is empty on
+// roster listings. Waitlisted holds the full courses they're waiting for a
+// seat in, and is only set by GetPerson and UpdatePerson.
+type Person struct {
+	ID         int      `json:"id"`
+	FirstName  string   `json:"first_name"`
+	LastName   string   `json:"last_name"`
+	Type       string   `json:"type"` // student or professor
+	Age        int      `json:"age"`
+	Courses    []int    `json:"courses,omitempty"`
+	Roles      []string `json:"roles,omitempty"` // role in each of Courses
+	Waitlisted []int    `json:"waitlisted,omitempty"`
+	TermID     int      `json:"term_id,omitempty"`
+}
+
+// FullName is the name people are addressed by in the API's paths.
+func (p Person) FullName() string {
+	return p.FirstName + " " + p.LastName
+}
+
+// PersonInput is the body for creating or updating a person. Courses
+// replaces the person's course enrollments.
+type PersonInput struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Type      string `json:"type"`
+	Age       int    `json:"age"`
+	Courses   []int  `json:"courses"`
+
+	// role in each of Courses by position, or empty for the default of
+	// Type: professors instruct and students study
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Enrollment roles. Only professors can be instructors, and only students
+// take a seat in a course and are graded.
+const (
+	RoleInstructor = "instructor"
+	RoleStudent    = "student"
+	RoleTA         = "ta"
+)
+
+// RosterMember is someone on a course's roster and their role in it.
+type RosterMember struct {
+	Person
+	Role string `json:"role"`
+}
+
+// Enrollment links a person to a course in a term, or to its waitlist when
+// the course is full.
+type Enrollment struct {
+	PersonID int    `json:"person_id"`
+	CourseID int    `json:"course_id"`
+	TermID   int    `json:"term_id,omitempty"`
+	Role     string `json:"role,omitempty"`     // instructor, student or ta
+	Status   string `json:"status,omitempty"`   // enrolled or waitlisted
+	Position int    `json:"position,omitempty"` // place on the waitlist, from 1
+}
+
+// Waitlisted reports whether the person is waiting for a seat rather than
+// enrolled.
+func (e Enrollment) Waitlisted() bool {
+	return e.Status == "waitlisted"
+}
+
+// Prerequisite is a course that must be completed before another can be
+// taken.
+type Prerequisite struct {
+	CourseID       int `json:"course_id,omitempty"`
+	PrerequisiteID int `json:"prerequisite_id"`
+}
+
+// Meeting is a weekly meeting of a course. Day is monday to sunday and Start
+// and End are times like 09:00.
+type Meeting struct {
+	ID       int    `json:"id,omitempty"`
+	CourseID int    `json:"course_id,omitempty"`
+	Day      string `json:"day"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Location string `json:"location,omitempty"`
+}
+
+// Term is an academic term. Start and End are dates like 2026-09-01.
+type Term struct {
+	ID    int    `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// GradeStep is a letter on the grade scale and the points it's worth.
+type GradeStep struct {
+	Letter string  `json:"letter"`
+	Points float64 `json:"points"`
+}
+
+// Grade is a student's grade for a course in a term. Points come from the
+// grade scale when the grade is set.
+type Grade struct {
+	PersonID int     `json:"person_id,omitempty"`
+	CourseID int     `json:"course_id,omitempty"`
+	TermID   int     `json:"term_id,omitempty"`
+	Letter   string  `json:"letter"`
+	Points   float64 `json:"points,omitempty"`
+}
+
+// TermGPA is the credit weighted GPA of a person's graded courses in a term,
+// or in every term when TermID is 0. GPA is nil without graded courses.
+type TermGPA struct {
+	TermID  int      `json:"term_id,omitempty"`
+	Term    string   `json:"term,omitempty"`
+	Credits int      `json:"credits"`
+	GPA     *float64 `json:"gpa"`
+}
+
+// GPA is a person's GPA in each term they were graded in, in date order,
+// and across all of them.
+type GPA struct {
+	PersonID   int       `json:"person_id"`
+	Terms      []TermGPA `json:"terms"`
+	Cumulative TermGPA   `json:"cumulative"`
+}
+
+// TranscriptCourse is a course on a transcript. Grade is empty and Points
+// nil until it's graded.
+type TranscriptCourse struct {
+	CourseID int      `json:"course_id"`
+	Name     string   `json:"name"`
+	Credits  int      `json:"credits"`
+	Grade    string   `json:"grade,omitempty"`
+	Points   *float64 `json:"points"`
+}
+
+// TranscriptTerm is a term on a transcript. Credits counts every course and
+// GradedCredits the ones in GPA.
+type TranscriptTerm struct {
+	TermID        int                `json:"term_id"`
+	Term          string             `json:"term"`
+	Start         string             `json:"start"`
+	End           string             `json:"end"`
+	Courses       []TranscriptCourse `json:"courses"`
+	Credits       int                `json:"credits"`
+	GradedCredits int                `json:"graded_credits"`
+	GPA           *float64           `json:"gpa"`
+}
+
+// TranscriptTotals sums a transcript over every term.
+type TranscriptTotals struct {
+	Courses       int      `json:"courses"`
+	Credits       int      `json:"credits"`
+	GradedCredits int      `json:"graded_credits"`
+	GPA           *float64 `json:"gpa"`
+}
+
+// Transcript is every course a student took, by term in date order.
+type Transcript struct {
+	Person Person           `json:"person"`
+	Terms  []TranscriptTerm `json:"terms"`
+	Totals TranscriptTotals `json:"totals"`
+}
+
+// WaitlistEntry is a person waiting for a seat in a course.
+type WaitlistEntry struct {
+	Position  int       `json:"position"`
+	Person    Person    `json:"person"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PersonFilter narrows ListPeople. Zero values are ignored.
+type PersonFilter struct {
+	Name string // matches the first or last name exactly
+	Age  *int
+}
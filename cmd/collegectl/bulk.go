@@ -0,0 +1,109 @@
+// reading the csv and json files that drive bulk operations
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Read the records of a bulk file as field maps. Files ending in .json hold
+// an array of objects; anything else is csv with a header row. "-" reads
+// csv from stdin.
+func readRecords(path string, stdin io.Reader) ([]map[string]string, error) {
+	var r io.Reader = stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return readJSONRecords(r)
+	}
+	return readCSVRecords(r)
+}
+
+func readCSVRecords(r io.Reader) ([]map[string]string, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := map[string]string{}
+		for i, name := range header {
+			record[strings.TrimSpace(name)] = strings.TrimSpace(row[i])
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readJSONRecords(r io.Reader) ([]map[string]string, error) {
+	var objects []map[string]interface{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("bulk json file must be an array of objects: %w", err)
+	}
+
+	records := make([]map[string]string, len(objects))
+	for i, object := range objects {
+		record := map[string]string{}
+		for name, value := range object {
+			switch v := value.(type) {
+			case nil:
+			case []interface{}: // lists such as courses are joined like csv values
+				parts := make([]string, len(v))
+				for j, part := range v {
+					parts[j] = fmt.Sprint(part)
+				}
+				record[name] = strings.Join(parts, ";")
+			default:
+				record[name] = fmt.Sprint(v)
+			}
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+// Parse a required integer field of a record.
+func intField(record map[string]string, name string) (int, error) {
+	value, ok := record[name]
+	if !ok || value == "" {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+// Parse a list of ids separated by ";" or ",".
+func parseIDs(value string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.FieldsFunc(value, func(c rune) bool { return c == ';' || c == ',' }) {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid course id %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,837 @@
+// course, person, enrollment, term, grade and profile subcommands
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/client"
+)
+
+// Parse a course capacity or credits, where empty or 0 means unset.
+func parseCount(what, value string) (*int, error) {
+	if value == "" || value == "0" {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return nil, usagef("%s must be a non-negative integer, got %q", what, value)
+	}
+	return &count, nil
+}
+
+// Parse a positional id argument.
+func parseID(what, value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 1 {
+		return 0, usagef("%s must be a positive integer, got %q", what, value)
+	}
+	return id, nil
+}
+
+func (a *app) course(args []string) error {
+	if len(args) == 0 {
+		return usagef("course needs a subcommand: list, get, create, update, delete, roster, waitlist, prerequisites, require, unrequire, meetings, meet or unmeet")
+	}
+	fs := a.flags("course " + args[0])
+	file := fs.String("f", "", "csv or json file of courses for create (name, capacity, credits, requires_instructor) or delete (id)")
+	capacityFlag := fs.Int("capacity", 0, "most students enrolled by create and update, 0 for no limit")
+	creditsFlag := fs.Int("credits", 0, "weight of the course's grades in a GPA for create and update, 0 to count 1")
+	requiresInstructor := fs.Bool("requires-instructor", false, "only enroll students in terms someone instructs the course, for create and update")
+	pageSize := fs.Int("page-size", 100, "courses fetched per request by list")
+	location := fs.String("location", "", "where the meeting added by meet is held")
+	rest, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	c, out, err := a.setup()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	capacity, err := parseCount("capacity", strconv.Itoa(*capacityFlag))
+	if err != nil {
+		return err
+	}
+	credits, err := parseCount("credits", strconv.Itoa(*creditsFlag))
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		var courses []client.Course
+		for course, err := range c.Courses(ctx, *pageSize) {
+			if err != nil {
+				return err
+			}
+			courses = append(courses, course)
+		}
+		return out.courses(courses)
+
+	case "get":
+		if len(rest) != 1 {
+			return usagef("usage: course get ID")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		course, err := c.GetCourse(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.courses([]client.Course{course})
+
+	case "create":
+		if *file != "" {
+			return a.bulk(*file, out,
+				func(record map[string]string) string { return record["name"] },
+				func(ctx context.Context, record map[string]string) error {
+					if record["name"] == "" {
+						return fmt.Errorf("missing name")
+					}
+					capacity, err := parseCount("capacity", record["capacity"])
+					if err != nil {
+						return err
+					}
+					credits, err := parseCount("credits", record["credits"])
+					if err != nil {
+						return err
+					}
+					requires := false
+					if value := record["requires_instructor"]; value != "" {
+						if requires, err = strconv.ParseBool(value); err != nil {
+							return fmt.Errorf("requires_instructor must be true or false, got %q", value)
+						}
+					}
+					_, err = c.CreateCourse(ctx, record["name"], capacity, credits, requires)
+					return err
+				})
+		}
+		if len(rest) != 1 {
+			return usagef("usage: course create [-capacity N] [-credits N] [-requires-instructor] NAME | -f FILE")
+		}
+		course, err := c.CreateCourse(ctx, rest[0], capacity, credits, *requiresInstructor)
+		if err != nil {
+			return err
+		}
+		return out.courses([]client.Course{course})
+
+	case "update":
+		if len(rest) != 2 {
+			return usagef("usage: course update [-capacity N] [-credits N] [-requires-instructor] ID NAME")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		course, err := c.UpdateCourse(ctx, id, rest[1], capacity, credits, *requiresInstructor)
+		if err != nil {
+			return err
+		}
+		return out.courses([]client.Course{course})
+
+	case "delete":
+		if *file != "" {
+			return a.bulk(*file, out,
+				func(record map[string]string) string { return record["id"] },
+				func(ctx context.Context, record map[string]string) error {
+					id, err := intField(record, "id")
+					if err != nil {
+						return err
+					}
+					return c.DeleteCourse(ctx, id)
+				})
+		}
+		if len(rest) == 0 {
+			return usagef("usage: course delete ID... | -f FILE")
+		}
+		ids := make([]int, len(rest))
+		for i, arg := range rest {
+			if ids[i], err = parseID("course id", arg); err != nil {
+				return err
+			}
+		}
+		for _, id := range ids {
+			if err := c.DeleteCourse(ctx, id); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stderr, "deleted course %d\n", id)
+		}
+		return nil
+
+	case "roster":
+		if len(rest) != 1 {
+			return usagef("usage: course roster ID")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		members, err := c.Roster(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.roster(members)
+
+	case "waitlist":
+		if len(rest) != 1 {
+			return usagef("usage: course waitlist ID")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		waitlist, err := c.Waitlist(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.waitlist(waitlist)
+
+	case "prerequisites":
+		if len(rest) != 1 {
+			return usagef("usage: course prerequisites ID")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		courses, err := c.Prerequisites(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.courses(courses)
+
+	case "require", "unrequire":
+		if len(rest) != 2 {
+			return usagef("usage: course %s ID PREREQUISITE_ID", args[0])
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		prerequisiteID, err := parseID("prerequisite id", rest[1])
+		if err != nil {
+			return err
+		}
+		if args[0] == "unrequire" {
+			if err := c.RemovePrerequisite(ctx, id, prerequisiteID); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stderr, "course %d no longer requires course %d\n", id, prerequisiteID)
+			return nil
+		}
+		if err := c.AddPrerequisite(ctx, id, prerequisiteID); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "course %d requires course %d\n", id, prerequisiteID)
+		return nil
+
+	case "meetings":
+		if len(rest) != 1 {
+			return usagef("usage: course meetings ID")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		meetings, err := c.Meetings(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.meetings(meetings)
+
+	case "meet":
+		if len(rest) != 4 {
+			return usagef("usage: course meet [-location PLACE] ID DAY START END")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		meeting, err := c.AddMeeting(ctx, id, client.Meeting{Day: rest[1], Start: rest[2], End: rest[3], Location: *location})
+		if err != nil {
+			return err
+		}
+		return out.meetings([]client.Meeting{meeting})
+
+	case "unmeet":
+		if len(rest) != 2 {
+			return usagef("usage: course unmeet ID MEETING_ID")
+		}
+		id, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		meetingID, err := parseID("meeting id", rest[1])
+		if err != nil {
+			return err
+		}
+		if err := c.RemoveMeeting(ctx, id, meetingID); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "removed meeting %d from course %d\n", meetingID, id)
+		return nil
+	}
+	return usagef("unknown course subcommand %q", args[0])
+}
+
+// personFlags are the fields person create and update accept as flags.
+type personFlags struct {
+	first, last, kind, courses, roles string
+	age                               int
+}
+
+func (p *personFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&p.first, "first", "", "first name")
+	fs.StringVar(&p.last, "last", "", "last name")
+	fs.StringVar(&p.kind, "type", "", "student or professor")
+	fs.IntVar(&p.age, "age", 0, "age")
+	fs.StringVar(&p.courses, "courses", "", "comma separated course ids, replacing the current ones")
+	fs.StringVar(&p.roles, "roles", "", "comma separated role in each of courses: instructor, student or ta")
+}
+
+// Split a comma or semicolon separated list of roles.
+func parseRoles(value string) []string {
+	var roles []string
+	for _, part := range strings.FieldsFunc(value, func(c rune) bool { return c == ';' || c == ',' }) {
+		roles = append(roles, strings.TrimSpace(part))
+	}
+	return roles
+}
+
+// Build a person input from a bulk record's first_name, last_name, type,
+// age, courses and roles fields.
+func personRecord(record map[string]string) (client.PersonInput, error) {
+	input := client.PersonInput{
+		FirstName: record["first_name"],
+		LastName:  record["last_name"],
+		Type:      record["type"],
+	}
+	var err error
+	if input.Age, err = intField(record, "age"); err != nil {
+		return input, err
+	}
+	if input.Courses, err = parseIDs(record["courses"]); err != nil {
+		return input, err
+	}
+	input.Roles = parseRoles(record["roles"])
+	return input, nil
+}
+
+func (a *app) person(args []string) error {
+	if len(args) == 0 {
+		return usagef("person needs a subcommand: list, get, create, update, delete, gpa or transcript")
+	}
+	fs := a.flags("person " + args[0])
+	file := fs.String("f", "", "csv or json file of people for create (first_name, last_name, type, age, courses, roles) or delete (name)")
+	var pageSize, age int
+	var name string
+	var fields personFlags
+	switch args[0] {
+	case "list":
+		fs.IntVar(&pageSize, "page-size", 100, "people fetched per request")
+		fs.StringVar(&name, "name", "", "only people with this first or last name")
+		fs.IntVar(&age, "age", 0, "only people of this age")
+	case "create", "update":
+		fields.register(fs)
+	}
+	rest, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	c, out, err := a.setup()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list":
+		filter := client.PersonFilter{Name: name}
+		if set["age"] {
+			filter.Age = &age
+		}
+		var people []client.Person
+		for person, err := range c.People(ctx, filter, pageSize) {
+			if err != nil {
+				return err
+			}
+			people = append(people, person)
+		}
+		return out.people(people)
+
+	case "get":
+		if len(rest) != 1 {
+			return usagef(`usage: person get "FIRST LAST"`)
+		}
+		person, err := c.GetPerson(ctx, rest[0])
+		if err != nil {
+			return err
+		}
+		return out.people([]client.Person{person})
+
+	case "create":
+		if *file != "" {
+			return a.bulk(*file, out,
+				func(record map[string]string) string {
+					return strings.TrimSpace(record["first_name"] + " " + record["last_name"])
+				},
+				func(ctx context.Context, record map[string]string) error {
+					input, err := personRecord(record)
+					if err != nil {
+						return err
+					}
+					_, err = c.CreatePerson(ctx, input)
+					return err
+				})
+		}
+		if len(rest) != 0 {
+			return usagef("usage: person create --first FIRST --last LAST --type TYPE --age AGE [--courses IDS [--roles ROLES]] | -f FILE")
+		}
+		input := client.PersonInput{FirstName: fields.first, LastName: fields.last, Type: fields.kind, Age: fields.age}
+		if input.Courses, err = parseIDs(fields.courses); err != nil {
+			return usagef("%s", err)
+		}
+		input.Roles = parseRoles(fields.roles)
+		id, err := c.CreatePerson(ctx, input)
+		if err != nil {
+			return err
+		}
+		return out.people([]client.Person{{
+			ID: id, FirstName: input.FirstName, LastName: input.LastName,
+			Type: input.Type, Age: input.Age, Courses: input.Courses, Roles: input.Roles,
+		}})
+
+	case "update":
+		if len(rest) != 1 {
+			return usagef(`usage: person update "FIRST LAST" [--first --last --type --age --courses --roles]`)
+		}
+		// start from the current person so only the flags given change
+		current, err := c.GetPerson(ctx, rest[0])
+		if err != nil {
+			return err
+		}
+		input := client.PersonInput{
+			FirstName: current.FirstName,
+			LastName:  current.LastName,
+			Type:      current.Type,
+			Age:       current.Age,
+			Courses:   current.Courses,
+			Roles:     current.Roles,
+		}
+		if set["first"] {
+			input.FirstName = fields.first
+		}
+		if set["last"] {
+			input.LastName = fields.last
+		}
+		if set["type"] {
+			input.Type = fields.kind
+		}
+		if set["age"] {
+			input.Age = fields.age
+		}
+		if set["courses"] {
+			if input.Courses, err = parseIDs(fields.courses); err != nil {
+				return usagef("%s", err)
+			}
+			input.Roles = nil
+		}
+		if set["roles"] {
+			input.Roles = parseRoles(fields.roles)
+		}
+		person, err := c.UpdatePerson(ctx, rest[0], input)
+		if err != nil {
+			return err
+		}
+		return out.people([]client.Person{person})
+
+	case "delete":
+		if *file != "" {
+			return a.bulk(*file, out,
+				func(record map[string]string) string { return record["name"] },
+				func(ctx context.Context, record map[string]string) error {
+					if record["name"] == "" {
+						return fmt.Errorf("missing name")
+					}
+					return c.DeletePerson(ctx, record["name"])
+				})
+		}
+		if len(rest) == 0 {
+			return usagef(`usage: person delete "FIRST LAST"... | -f FILE`)
+		}
+		for _, fullName := range rest {
+			if err := c.DeletePerson(ctx, fullName); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stderr, "deleted %s\n", fullName)
+		}
+		return nil
+
+	case "gpa":
+		if len(rest) != 1 {
+			return usagef("usage: person gpa ID")
+		}
+		id, err := parseID("person id", rest[0])
+		if err != nil {
+			return err
+		}
+		gpa, err := c.GPA(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.gpa(gpa)
+
+	case "transcript":
+		if len(rest) != 1 {
+			return usagef("usage: person transcript ID")
+		}
+		id, err := parseID("person id", rest[0])
+		if err != nil {
+			return err
+		}
+		transcript, err := c.Transcript(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.transcript(transcript)
+	}
+	return usagef("unknown person subcommand %q", args[0])
+}
+
+// Enroll or unenroll a person, or every course_id, person_id record of a
+// bulk file. Enrolling records may carry a role instead of the -role flag.
+func (a *app) enroll(args []string, enroll bool) error {
+	command := "enroll"
+	if !enroll {
+		command = "unenroll"
+	}
+	fs := a.flags(command)
+	file := fs.String("f", "", "csv or json file of course_id, person_id records")
+	override := fs.Bool("override-prerequisites", false, "enroll students missing the course's prerequisites, admins only")
+	roleFlag := fs.String("role", "", "role to enroll with: instructor, student or ta, the default for the person's type when empty")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, out, err := a.setup()
+	if err != nil {
+		return err
+	}
+
+	if *override && *roleFlag != "" {
+		return usagef("-override-prerequisites and -role can't be combined")
+	}
+
+	var enrollment client.Enrollment
+	apply := func(ctx context.Context, courseID, personID int, role string) error {
+		if enroll {
+			var err error
+			switch {
+			case *override:
+				enrollment, err = c.EnrollOverride(ctx, courseID, personID)
+			case role != "":
+				enrollment, err = c.EnrollAs(ctx, courseID, personID, role)
+			default:
+				enrollment, err = c.Enroll(ctx, courseID, personID)
+			}
+			return err
+		}
+		return c.Unenroll(ctx, courseID, personID)
+	}
+
+	if *file != "" {
+		return a.bulk(*file, out,
+			func(record map[string]string) string {
+				return "course " + record["course_id"] + " person " + record["person_id"]
+			},
+			func(ctx context.Context, record map[string]string) error {
+				courseID, err := intField(record, "course_id")
+				if err != nil {
+					return err
+				}
+				personID, err := intField(record, "person_id")
+				if err != nil {
+					return err
+				}
+				role := *roleFlag
+				if record["role"] != "" {
+					role = record["role"]
+				}
+				return apply(ctx, courseID, personID, role)
+			})
+	}
+
+	if len(rest) != 2 {
+		return usagef("usage: %s COURSE_ID PERSON_ID | -f FILE", command)
+	}
+	courseID, err := parseID("course id", rest[0])
+	if err != nil {
+		return err
+	}
+	personID, err := parseID("person id", rest[1])
+	if err != nil {
+		return err
+	}
+	if err := apply(context.Background(), courseID, personID, *roleFlag); err != nil {
+		return err
+	}
+	if enrollment.Waitlisted() {
+		fmt.Fprintf(a.stderr, "waitlisted person %d for course %d at position %d\n", personID, courseID, enrollment.Position)
+		return nil
+	}
+	fmt.Fprintf(a.stderr, "%sed person %d in course %d\n", command, personID, courseID)
+	return nil
+}
+
+// List and manage academic terms.
+func (a *app) termCmd(args []string) error {
+	if len(args) == 0 {
+		return usagef("term needs a subcommand: list, current, get, create, update or delete")
+	}
+	fs := a.flags("term " + args[0])
+	rest, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	c, out, err := a.setup()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list":
+		terms, err := c.Terms(ctx)
+		if err != nil {
+			return err
+		}
+		return out.terms(terms)
+
+	case "current":
+		term, err := c.CurrentTerm(ctx)
+		if err != nil {
+			return err
+		}
+		return out.terms([]client.Term{term})
+
+	case "get":
+		if len(rest) != 1 {
+			return usagef("usage: term get ID")
+		}
+		id, err := parseID("term id", rest[0])
+		if err != nil {
+			return err
+		}
+		term, err := c.GetTerm(ctx, id)
+		if err != nil {
+			return err
+		}
+		return out.terms([]client.Term{term})
+
+	case "create":
+		if len(rest) != 3 {
+			return usagef("usage: term create NAME START END")
+		}
+		term, err := c.CreateTerm(ctx, client.Term{Name: rest[0], Start: rest[1], End: rest[2]})
+		if err != nil {
+			return err
+		}
+		return out.terms([]client.Term{term})
+
+	case "update":
+		if len(rest) != 4 {
+			return usagef("usage: term update ID NAME START END")
+		}
+		id, err := parseID("term id", rest[0])
+		if err != nil {
+			return err
+		}
+		term, err := c.UpdateTerm(ctx, id, client.Term{Name: rest[1], Start: rest[2], End: rest[3]})
+		if err != nil {
+			return err
+		}
+		return out.terms([]client.Term{term})
+
+	case "delete":
+		if len(rest) != 1 {
+			return usagef("usage: term delete ID")
+		}
+		id, err := parseID("term id", rest[0])
+		if err != nil {
+			return err
+		}
+		if err := c.DeleteTerm(ctx, id); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "deleted term %d\n", id)
+		return nil
+	}
+	return usagef("unknown term subcommand %q", args[0])
+}
+
+// Grade students and manage the grade scale.
+func (a *app) grade(args []string) error {
+	if len(args) == 0 {
+		return usagef("grade needs a subcommand: set, scale or set-scale")
+	}
+	fs := a.flags("grade " + args[0])
+	rest, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	c, out, err := a.setup()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "set":
+		if len(rest) != 3 {
+			return usagef("usage: grade set COURSE_ID PERSON_ID LETTER")
+		}
+		courseID, err := parseID("course id", rest[0])
+		if err != nil {
+			return err
+		}
+		personID, err := parseID("person id", rest[1])
+		if err != nil {
+			return err
+		}
+		grade, err := c.SetGrade(ctx, courseID, personID, rest[2])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "graded person %d %s (%.2f) in course %d\n", personID, grade.Letter, grade.Points, courseID)
+		return nil
+
+	case "scale":
+		scale, err := c.GradeScale(ctx)
+		if err != nil {
+			return err
+		}
+		return out.gradeScale(scale)
+
+	case "set-scale":
+		if len(rest) == 0 {
+			return usagef("usage: grade set-scale LETTER=POINTS...")
+		}
+		scale := make([]client.GradeStep, len(rest))
+		for i, arg := range rest {
+			letter, value, ok := strings.Cut(arg, "=")
+			points, err := strconv.ParseFloat(value, 64)
+			if !ok || err != nil {
+				return usagef("grades must look like A=4.0, got %q", arg)
+			}
+			scale[i] = client.GradeStep{Letter: letter, Points: points}
+		}
+		scale, err := c.SetGradeScale(ctx, scale)
+		if err != nil {
+			return err
+		}
+		return out.gradeScale(scale)
+	}
+	return usagef("unknown grade subcommand %q", args[0])
+}
+
+// Manage the profiles in the config file.
+func (a *app) profileCmd(args []string) error {
+	if len(args) == 0 {
+		return usagef("profile needs a subcommand: list, set, use or delete")
+	}
+	fs := a.flags("profile " + args[0])
+	token := fs.String("token", "", "jwt bearer token, used instead of the api key")
+	rest, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	out, err := a.output()
+	if err != nil {
+		return err
+	}
+	config, path, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		type row struct {
+			Name    string `json:"name"`
+			Server  string `json:"server"`
+			Auth    string `json:"auth"`
+			Current bool   `json:"current"`
+		}
+		var rows []row
+		var table [][]string
+		for _, name := range config.names() {
+			profile := config.Profiles[name]
+			auth := "none"
+			if profile.Token != "" {
+				auth = "token"
+			} else if profile.APIKey != "" {
+				auth = "api key"
+			}
+			rows = append(rows, row{name, profile.Server, auth, name == config.Current})
+			current := ""
+			if name == config.Current {
+				current = "*"
+			}
+			table = append(table, []string{current, name, profile.Server, auth})
+		}
+		return out.print(rows, []string{"current", "name", "server", "auth"}, table)
+
+	case "set":
+		if len(rest) != 1 {
+			return usagef("usage: profile set NAME [--server URL] [--api-key KEY] [--token TOKEN]")
+		}
+		profile := config.Profiles[rest[0]]
+		if a.server != "" {
+			profile.Server = a.server
+		}
+		if a.apiKey != "" {
+			profile.APIKey = a.apiKey
+		}
+		if *token != "" {
+			profile.Token = *token
+		}
+		config.Profiles[rest[0]] = profile
+		if config.Current == "" {
+			config.Current = rest[0]
+		}
+		return config.save(path)
+
+	case "use":
+		if len(rest) != 1 {
+			return usagef("usage: profile use NAME")
+		}
+		if _, ok := config.Profiles[rest[0]]; !ok {
+			return fmt.Errorf("no profile named %q", rest[0])
+		}
+		config.Current = rest[0]
+		return config.save(path)
+
+	case "delete":
+		if len(rest) != 1 {
+			return usagef("usage: profile delete NAME")
+		}
+		if _, ok := config.Profiles[rest[0]]; !ok {
+			return fmt.Errorf("no profile named %q", rest[0])
+		}
+		delete(config.Profiles, rest[0])
+		if config.Current == rest[0] {
+			config.Current = ""
+		}
+		return config.save(path)
+	}
+	return usagef("unknown profile subcommand %q", args[0])
+}
@@ -0,0 +1,82 @@
+// profiles naming the servers and api keys collegectl talks to
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Profile is one server and the credentials used with it.
+type Profile struct {
+	Server string `json:"server"`
+	APIKey string `json:"api_key,omitempty"`
+	Token  string `json:"token,omitempty"` // jwt bearer token, used instead of the api key when set
+}
+
+// Config is the collegectl config file.
+type Config struct {
+	Current  string             `json:"current,omitempty"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Return the config file path, COLLEGECTL_CONFIG or collegectl/config.json
+// in the user config directory.
+func configPath() (string, error) {
+	if path := os.Getenv("COLLEGECTL_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "collegectl", "config.json"), nil
+}
+
+// Load the config file. A missing file is an empty config.
+func loadConfig() (*Config, string, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, "", err
+	}
+	config := &Config{Profiles: map[string]Profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, path, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]Profile{}
+	}
+	return config, path, nil
+}
+
+// Save the config, readable only by the user since it holds api keys.
+func (c *Config) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Names of every profile in order.
+func (c *Config) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,260 @@
+// collegectl administers the people and courses of a college api server
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/client"
+)
+
+// server used when neither a flag nor the profile names one
+const defaultServer = "http://localhost:8000"
+
+const usage = `Usage: collegectl <command> [flags] [args]
+
+Commands:
+  course list|get|create|update|delete|roster|waitlist|prerequisites|require|unrequire|
+         meetings|meet|unmeet
+  person list|get|create|update|delete|gpa|transcript
+  enroll COURSE_ID PERSON_ID | -f FILE
+  unenroll COURSE_ID PERSON_ID | -f FILE
+  term list|current|get|create|update|delete
+  grade set|scale|set-scale
+  profile list|set|use|delete
+
+Global flags, accepted by every command:
+  --profile NAME    profile from the config file (default: the current profile)
+  --server URL      server url, overriding the profile
+  --api-key KEY     api key, overriding the profile and COLLEGE_API_KEY
+  --term ID         term of rosters, waitlists, enrollments and people's courses
+                    (default: the current term)
+  -o FORMAT         output format: table, json or csv (default table)
+
+Run "collegectl <command> -h" for the flags of a command.
+`
+
+// errUsage marks errors caused by how the command was invoked.
+type errUsage struct {
+	msg string
+}
+
+func (e errUsage) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return errUsage{fmt.Sprintf(format, args...)}
+}
+
+// errFailed is returned after a bulk operation has already reported which
+// records failed.
+var errFailed = errors.New("some records failed")
+
+// app holds the global flags and the streams commands read and write.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	profile string
+	server  string
+	apiKey  string
+	format  string
+	term    int
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Run the command in args and return the exit status: 0 on success, 1 when
+// the command failed and 2 when it was invoked wrongly.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, format: "table"}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	var err error
+	switch args[0] {
+	case "course":
+		err = a.course(args[1:])
+	case "person":
+		err = a.person(args[1:])
+	case "enroll":
+		err = a.enroll(args[1:], true)
+	case "unenroll":
+		err = a.enroll(args[1:], false)
+	case "term":
+		err = a.termCmd(args[1:])
+	case "grade":
+		err = a.grade(args[1:])
+	case "profile":
+		err = a.profileCmd(args[1:])
+	default:
+		err = usagef("unknown command %q", args[0])
+	}
+
+	var usageErr errUsage
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "collegectl: %s\nRun \"collegectl -h\" for usage.\n", err)
+		return 2
+	case errors.Is(err, errFailed):
+		return 1
+	default:
+		fmt.Fprintf(stderr, "collegectl: %s\n", err)
+		return 1
+	}
+}
+
+// Return a flag set for a command with the global flags registered on it.
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("collegectl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.profile, "profile", a.profile, "profile from the config file")
+	fs.StringVar(&a.server, "server", a.server, "server url")
+	fs.StringVar(&a.apiKey, "api-key", a.apiKey, "api key")
+	fs.StringVar(&a.format, "o", a.format, "output format: table, json or csv")
+	fs.IntVar(&a.term, "term", a.term, "term id, the current term when 0")
+	return fs
+}
+
+// Parse args allowing flags after positional arguments, so
+// "course get 3 -o json" works as well as "course get -o json 3".
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage{err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Return the output for the -o format.
+func (a *app) output() (output, error) {
+	if !slices.Contains(formats, a.format) {
+		return output{}, usagef("unknown output format %q, want one of %s", a.format, strings.Join(formats, ", "))
+	}
+	return output{format: a.format, w: a.stdout}, nil
+}
+
+// Build an api client from the flags, environment and selected profile.
+// Flags win over the environment, which wins over the profile.
+func (a *app) client() (*client.Client, error) {
+	config, _, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	name := a.profile
+	if name == "" {
+		name = os.Getenv("COLLEGECTL_PROFILE")
+	}
+	explicit := name != ""
+	if name == "" {
+		name = config.Current
+	}
+	profile, ok := config.Profiles[name]
+	if explicit && !ok {
+		return nil, fmt.Errorf("no profile named %q", name)
+	}
+
+	server := a.server
+	if server == "" {
+		server = os.Getenv("COLLEGE_SERVER")
+	}
+	if server == "" {
+		server = profile.Server
+	}
+	if server == "" {
+		server = defaultServer
+	}
+
+	opts := []client.Option{client.WithUserAgent("collegectl")}
+	apiKey := a.apiKey
+	if apiKey == "" {
+		apiKey = os.Getenv("COLLEGE_API_KEY")
+	}
+	switch {
+	case apiKey != "":
+		opts = append(opts, client.WithAPIKey(apiKey))
+	case profile.Token != "":
+		token := profile.Token
+		opts = append(opts, client.WithBearerToken(func(context.Context) (string, error) { return token, nil }))
+	case profile.APIKey != "":
+		opts = append(opts, client.WithAPIKey(profile.APIKey))
+	}
+	return client.New(server, opts...)
+}
+
+// Return the client and output every api command needs, the client in the
+// --term term.
+func (a *app) setup() (*client.Client, output, error) {
+	out, err := a.output()
+	if err != nil {
+		return nil, output{}, err
+	}
+	if a.term < 0 {
+		return nil, output{}, usagef("term must be a positive integer, got %d", a.term)
+	}
+	c, err := a.client()
+	if err != nil {
+		return nil, output{}, err
+	}
+	return c.InTerm(a.term), out, nil
+}
+
+// Run op for every record of a bulk file, carrying on past failures, then
+// print one result per record. target names the record in the results.
+func (a *app) bulk(path string, out output, target func(map[string]string) string, op func(context.Context, map[string]string) error) error {
+	records, err := readRecords(path, a.stdin)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	results := make([]result, len(records))
+	failed := 0
+	for i, record := range records {
+		results[i] = result{Record: i + 1, Target: target(record), Status: "ok"}
+		if err := op(ctx, record); err != nil {
+			results[i].Status = "error"
+			results[i].Detail = err.Error()
+			failed++
+		}
+	}
+	if err := out.results(results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		fmt.Fprintf(a.stderr, "collegectl: %d of %d records failed\n", failed, len(records))
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/routes"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+)
+
+const testAdminKey = "test-admin-key"
+
+// Start the real router backed by a mock database and point collegectl at
+// it through a fresh config file.
+func newTestServer(t *testing.T) (*httptest.Server, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	r := chi.NewRouter()
+	routes.GetRoutes(r, &handlers.RequestHandler{DB: db, AdminKey: testAdminKey})
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	t.Setenv("COLLEGECTL_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+	t.Setenv("COLLEGECTL_PROFILE", "")
+	t.Setenv("COLLEGE_SERVER", "")
+	t.Setenv("COLLEGE_API_KEY", "")
+	return server, mock
+}
+
+// Run collegectl with args and return the exit status, stdout and stderr.
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, bytes.NewBufferString(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestCourseCommands tests listing and creating courses in each output format.
+func TestCourseCommands(t *testing.T) {
+	server, mock := newTestServer(t)
+	global := []string{"--server", server.URL, "--api-key", testAdminKey}
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course ORDER BY id LIMIT \\$1$").WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false).AddRow(2, "Art History", 25, 3, true))
+	code, stdout, _ := runCommand("", append([]string{"course", "list"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ID  NAME         CAPACITY  CREDITS  REQUIRES_INSTRUCTOR\n1   Math                            false\n2   Art History  25        3        true\n", stdout)
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(2, "Art History", nil, nil, false))
+	code, stdout, _ = runCommand("", append([]string{"course", "get", "2", "-o", "csv"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "id,name,capacity,credits,requires_instructor\n2,Art History,,,false\n", stdout)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO course \\(name, capacity, credits, requires_instructor\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id").WithArgs("Music", 12, 4, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	storetest.ExpectEvent(mock, "course.created")
+	mock.ExpectCommit()
+	code, stdout, _ = runCommand("", append([]string{"course", "create", "-capacity", "12", "-credits", "4", "Music", "-o", "json"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `[{"id":3,"name":"Music","capacity":12,"credits":4}]`, stdout)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE course_prerequisite").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\), EXISTS").WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"course", "prerequisite"}).AddRow(true, true))
+	mock.ExpectQuery("WITH RECURSIVE required").WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec("INSERT INTO course_prerequisite").WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "prerequisite.added")
+	mock.ExpectCommit()
+	code, _, stderr := runCommand("", append([]string{"course", "require", "3", "1"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "course 3 requires course 1\n", stderr)
+
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 3, 3)
+	mock.ExpectExec("LOCK TABLE course_meeting").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("WITH courses").WithArgs(3, "tuesday", "09:00", "10:00").
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "id", "name", "day", "start", "end"}))
+	mock.ExpectQuery("INSERT INTO course_meeting").WithArgs(3, "tuesday", "09:00", "10:00", "Studio").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectEvent(mock, "meeting.added")
+	mock.ExpectCommit()
+	code, stdout, _ = runCommand("", append([]string{"course", "meet", "-location", "Studio", "-o", "csv", "3", "tuesday", "09:00", "10:00"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "id,day,start,end,location\n6,tuesday,09:00,10:00,Studio\n", stdout)
+
+	code, _, stderr = runCommand("", append([]string{"course", "get", "abc"}, global...)...)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `course id must be a positive integer, got "abc"`)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBulkEnroll tests that a bulk file carries on past failed records and
+// reports each one, enrolling them in the term given.
+func TestBulkEnroll(t *testing.T) {
+	server, mock := newTestServer(t)
+	file := filepath.Join(t.TempDir(), "enroll.csv")
+	assert.NoError(t, os.WriteFile(file, []byte("course_id,person_id\n1,3\n9,3\nx,3\n"), 0o600))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 2)
+	storetest.ExpectLockCourse(mock, 1, nil)
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(3, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	mock.ExpectQuery("FROM course_prerequisite cp").WithArgs(1, 3, "2026-01-12").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}))
+	storetest.ExpectNoClashes(mock, 1, 3)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(3, 1, 2, "student").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 2)
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity"}))
+	mock.ExpectRollback()
+
+	code, stdout, stderr := runCommand("", "enroll", "-f", file, "-o", "csv", "--term", "2", "--server", server.URL, "--api-key", testAdminKey)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "record,target,status,detail\n"+
+		"1,course 1 person 3,ok,\n"+
+		"2,course 9 person 3,error,college api: 404 Not Found: Course not found\n"+
+		"3,course x person 3,error,\"course_id must be an integer, got \"\"x\"\"\"\n", stdout)
+	assert.Equal(t, "collegectl: 2 of 3 records failed\n", stderr)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBulkCreatePeople tests creating people from a json file, including
+// the courses list.
+func TestBulkCreatePeople(t *testing.T) {
+	server, mock := newTestServer(t)
+	file := filepath.Join(t.TempDir(), "people.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`[
+		{"first_name": "Alan", "last_name": "Turing", "type": "professor", "age": 41, "courses": [2]}
+	]`), 0o600))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO person").WithArgs("Alan", "Turing", "professor", 41).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectEvent(mock, "person.created")
+	storetest.ExpectLockCourse(mock, 2, 1)
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(6, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	storetest.ExpectNoClashes(mock, 2, 6)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(6, 2, 2, "instructor").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+
+	code, stdout, _ := runCommand("", "person", "create", "-f", file, "--server", server.URL, "--api-key", testAdminKey)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "RECORD  TARGET       STATUS  DETAIL\n1       Alan Turing  ok      \n", stdout)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTermCommands tests listing terms and creating one.
+func TestTermCommands(t *testing.T) {
+	server, mock := newTestServer(t)
+	global := []string{"--server", server.URL, "--api-key", testAdminKey}
+
+	mock.ExpectQuery("FROM term ORDER BY start_date").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end"}).
+			AddRow(1, "Fall 2025", "2025-09-01", "2025-12-19").
+			AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15"))
+	code, stdout, _ := runCommand("", append([]string{"term", "list"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "ID  NAME         START       END\n1   Fall 2025    2025-09-01  2025-12-19\n2   Spring 2026  2026-01-12  2026-05-15\n", stdout)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE term").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM term WHERE id <> \\$1").WithArgs(0, "Fall 2026", "2026-09-01", "2026-12-18").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end"}))
+	mock.ExpectQuery("INSERT INTO term").WithArgs("Fall 2026", "2026-09-01", "2026-12-18").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	storetest.ExpectEvent(mock, "term.created")
+	mock.ExpectCommit()
+	code, stdout, _ = runCommand("", append([]string{"term", "create", "-o", "csv", "Fall 2026", "2026-09-01", "2026-12-18"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "id,name,start,end\n3,Fall 2026,2026-09-01,2026-12-18\n", stdout)
+
+	code, _, stderr := runCommand("", append([]string{"term", "get", "fall"}, global...)...)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `term id must be a positive integer, got "fall"`)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGradeCommands tests the grade scale and a person's GPA and transcript
+// tables.
+func TestGradeCommands(t *testing.T) {
+	server, mock := newTestServer(t)
+	global := []string{"--server", server.URL, "--api-key", testAdminKey}
+
+	mock.ExpectQuery("SELECT letter, points::float8 FROM grade_scale").
+		WillReturnRows(sqlmock.NewRows([]string{"letter", "points"}).AddRow("A", 4.0).AddRow("B+", 3.3))
+	code, stdout, _ := runCommand("", append([]string{"grade", "scale", "-o", "csv"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "letter,points\nA,4.00\nB+,3.30\n", stdout)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(1, "Fall 2025", 3, 3.3).
+			AddRow(2, "Spring 2026", 4, 4.0).
+			AddRow(nil, nil, 7, 3.7))
+	code, stdout, _ = runCommand("", append([]string{"person", "gpa", "3"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "TERM_ID  TERM         CREDITS  GPA\n"+
+		"1        Fall 2025    3        3.30\n"+
+		"2        Spring 2026  4        4.00\n"+
+		"         cumulative   7        3.70\n", stdout)
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE id = \\$1").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(3, "Ann", "Lee", "student", 20))
+	mock.ExpectQuery("FROM person_course pc").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end", "course_id", "course", "credits", "grade", "points"}).
+			AddRow(1, "Fall 2025", "2025-09-01", "2025-12-19", 2, "Biology", 3, "B+", 3.3).
+			AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15", 4, "Logic", 4, "", nil))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(1, "Fall 2025", 3, 3.3).
+			AddRow(nil, nil, 3, 3.3))
+	code, stdout, _ = runCommand("", append([]string{"person", "transcript", "3"}, global...)...)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "TERM         COURSE_ID  NAME        CREDITS  GRADE  POINTS\n"+
+		"Fall 2025    2          Biology     3        B+     3.30\n"+
+		"Fall 2025               term gpa    3               3.30\n"+
+		"Spring 2026  4          Logic       4               \n"+
+		"Spring 2026             term gpa    4               \n"+
+		"                        cumulative  7               3.30\n", stdout)
+
+	code, _, stderr := runCommand("", append([]string{"grade", "set-scale", "A"}, global...)...)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `grades must look like A=4.0, got "A"`)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestProfiles tests saving profiles and using the current one's server and
+// api key.
+func TestProfiles(t *testing.T) {
+	server, mock := newTestServer(t)
+
+	code, _, _ := runCommand("", "profile", "set", "local", "--server", server.URL, "--api-key", testAdminKey)
+	assert.Equal(t, 0, code)
+	code, _, _ = runCommand("", "profile", "set", "prod", "--server", "https://college.example.com", "--token", "jwt")
+	assert.Equal(t, 0, code)
+
+	code, stdout, _ := runCommand("", "profile", "list", "-o", "json")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `[
+		{"name": "local", "server": "`+server.URL+`", "auth": "api key", "current": true},
+		{"name": "prod", "server": "https://college.example.com", "auth": "token", "current": false}
+	]`, stdout)
+
+	info, err := os.Stat(os.Getenv("COLLEGECTL_CONFIG"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the first profile saved became current
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 4, nil)
+	mock.ExpectQuery("DELETE FROM person_course WHERE course_id = \\$1 RETURNING person_id, term_id").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "term_id"}))
+	mock.ExpectQuery("DELETE FROM course WHERE id = \\$1").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("History"))
+	storetest.ExpectEvent(mock, "course.deleted")
+	mock.ExpectCommit()
+	code, _, stderr := runCommand("", "course", "delete", "4")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "deleted course 4\n", stderr)
+
+	code, _, stderr = runCommand("", "course", "list", "--profile", "staging")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "collegectl: no profile named \"staging\"\n", stderr)
+
+	code, _, _ = runCommand("", "profile", "use", "prod")
+	assert.Equal(t, 0, code)
+	config, _, err := loadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", config.Current)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
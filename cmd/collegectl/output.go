@@ -0,0 +1,185 @@
+// table, json and csv output
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/client"
+)
+
+// output formats accepted by -o
+var formats = []string{"table", "json", "csv"}
+
+type output struct {
+	format string
+	w      io.Writer
+}
+
+// Print value as indented json, or headers and rows as csv or an aligned
+// table.
+func (o output) print(value interface{}, headers []string, rows [][]string) error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	case "csv":
+		w := csv.NewWriter(o.w)
+		w.Write(headers)
+		w.WriteAll(rows)
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(headers, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
+
+func joinInts(ids []int, sep string) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, sep)
+}
+
+func (o output) courses(courses []client.Course) error {
+	rows := make([][]string, len(courses))
+	for i, course := range courses {
+		capacity, credits := "", ""
+		if course.Capacity != nil {
+			capacity = strconv.Itoa(*course.Capacity)
+		}
+		if course.Credits != nil {
+			credits = strconv.Itoa(*course.Credits)
+		}
+		rows[i] = []string{strconv.Itoa(course.ID), course.Name, capacity, credits, strconv.FormatBool(course.RequiresInstructor)}
+	}
+	return o.print(courses, []string{"id", "name", "capacity", "credits", "requires_instructor"}, rows)
+}
+
+func (o output) people(people []client.Person) error {
+	rows := make([][]string, len(people))
+	for i, person := range people {
+		rows[i] = []string{
+			strconv.Itoa(person.ID), person.FirstName, person.LastName, person.Type,
+			strconv.Itoa(person.Age), joinInts(person.Courses, ";"), strings.Join(person.Roles, ";"),
+		}
+	}
+	return o.print(people, []string{"id", "first_name", "last_name", "type", "age", "courses", "roles"}, rows)
+}
+
+func (o output) roster(members []client.RosterMember) error {
+	rows := make([][]string, len(members))
+	for i, member := range members {
+		rows[i] = []string{
+			strconv.Itoa(member.ID), member.FirstName, member.LastName, member.Type,
+			strconv.Itoa(member.Age), member.Role,
+		}
+	}
+	return o.print(members, []string{"id", "first_name", "last_name", "type", "age", "role"}, rows)
+}
+
+func (o output) waitlist(waitlist []client.WaitlistEntry) error {
+	rows := make([][]string, len(waitlist))
+	for i, entry := range waitlist {
+		rows[i] = []string{
+			strconv.Itoa(entry.Position), strconv.Itoa(entry.Person.ID),
+			entry.Person.FirstName, entry.Person.LastName, entry.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return o.print(waitlist, []string{"position", "person_id", "first_name", "last_name", "created_at"}, rows)
+}
+
+func (o output) meetings(meetings []client.Meeting) error {
+	rows := make([][]string, len(meetings))
+	for i, meeting := range meetings {
+		rows[i] = []string{strconv.Itoa(meeting.ID), meeting.Day, meeting.Start, meeting.End, meeting.Location}
+	}
+	return o.print(meetings, []string{"id", "day", "start", "end", "location"}, rows)
+}
+
+func (o output) terms(terms []client.Term) error {
+	rows := make([][]string, len(terms))
+	for i, term := range terms {
+		rows[i] = []string{strconv.Itoa(term.ID), term.Name, term.Start, term.End}
+	}
+	return o.print(terms, []string{"id", "name", "start", "end"}, rows)
+}
+
+func (o output) gradeScale(scale []client.GradeStep) error {
+	rows := make([][]string, len(scale))
+	for i, step := range scale {
+		rows[i] = []string{step.Letter, strconv.FormatFloat(step.Points, 'f', 2, 64)}
+	}
+	return o.print(scale, []string{"letter", "points"}, rows)
+}
+
+// Print a GPA with a row per term and a last row for the cumulative GPA.
+func (o output) gpa(gpa client.GPA) error {
+	row := func(term client.TermGPA) []string {
+		value := ""
+		if term.GPA != nil {
+			value = strconv.FormatFloat(*term.GPA, 'f', 2, 64)
+		}
+		return []string{strconv.Itoa(term.TermID), term.Term, strconv.Itoa(term.Credits), value}
+	}
+	var rows [][]string
+	for _, term := range gpa.Terms {
+		rows = append(rows, row(term))
+	}
+	cumulative := row(gpa.Cumulative)
+	cumulative[0], cumulative[1] = "", "cumulative"
+	rows = append(rows, cumulative)
+	return o.print(gpa, []string{"term_id", "term", "credits", "gpa"}, rows)
+}
+
+// Print a transcript with a row per course, a row per term for its GPA and a
+// last row for the totals.
+func (o output) transcript(transcript client.Transcript) error {
+	number := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', 2, 64)
+	}
+	var rows [][]string
+	for _, term := range transcript.Terms {
+		for _, course := range term.Courses {
+			rows = append(rows, []string{
+				term.Term, strconv.Itoa(course.CourseID), course.Name,
+				strconv.Itoa(course.Credits), course.Grade, number(course.Points),
+			})
+		}
+		rows = append(rows, []string{term.Term, "", "term gpa", strconv.Itoa(term.Credits), "", number(term.GPA)})
+	}
+	totals := transcript.Totals
+	rows = append(rows, []string{"", "", "cumulative", strconv.Itoa(totals.Credits), "", number(totals.GPA)})
+	return o.print(transcript, []string{"term", "course_id", "name", "credits", "grade", "points"}, rows)
+}
+
+// result of one line of a bulk operation
+type result struct {
+	Record int    `json:"record"` // 1 based position in the file
+	Target string `json:"target"`
+	Status string `json:"status"` // ok or error
+	Detail string `json:"detail,omitempty"`
+}
+
+func (o output) results(results []result) error {
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		rows[i] = []string{strconv.Itoa(r.Record), r.Target, r.Status, r.Detail}
+	}
+	return o.print(results, []string{"record", "target", "status", "detail"}, rows)
+}
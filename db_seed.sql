@@ -1,4 +1,10 @@
+DROP TABLE IF EXISTS course_meeting;
+DROP TYPE IF EXISTS weekday;
+DROP TABLE IF EXISTS course_prerequisite;
+DROP TABLE IF EXISTS waitlist;
 DROP TABLE IF EXISTS person_course;
+DROP TABLE IF EXISTS grade_scale;
+DROP TABLE IF EXISTS term;
 DROP TABLE IF EXISTS course;
 DROP TABLE IF EXISTS person;
 
@@ -20,40 +26,292 @@ VALUES ('Steve', 'Jobs', 'professor', 56),
        ('Elon', 'Musk', 'student', 52);
 
 -- course
+-- capacity limits the students enrolled, NULL for no limit. credits weigh the
+-- course's grades in a GPA, NULL counts as 1. A course requiring an instructor
+-- only takes students in terms someone instructs it
 CREATE TABLE course
 (
-    id   SERIAL PRIMARY KEY,
-    name TEXT NOT NULL
+    id                  SERIAL PRIMARY KEY,
+    name                TEXT                  NOT NULL,
+    capacity            INTEGER CHECK (capacity > 0),
+    credits             INTEGER CHECK (credits > 0),
+    requires_instructor BOOLEAN DEFAULT false NOT NULL
 );
 
-INSERT INTO course (name)
-VALUES ('Programming'),
-       ('Databases'),
-       ('UI Design');
+INSERT INTO course (name, capacity, credits, requires_instructor)
+VALUES ('Programming', NULL, 4, true),
+       ('Databases', 30, 3, true),
+       ('UI Design', 3, NULL, false);
+
+-- term
+-- academic terms, which never overlap. The current term is the latest to have
+-- started, and is the one enrollments default to
+CREATE TABLE term
+(
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL,
+    CHECK (start_date <= end_date)
+);
+
+INSERT INTO term (name, start_date, end_date)
+VALUES ('Fall 2025', '2025-09-01', '2025-12-19'),
+       ('Spring 2026', '2026-01-12', '2026-05-15'),
+       ('Fall 2026', '2026-08-31', '2026-12-18');
+
+-- grade_scale
+-- letters grades are given in and their points, replaceable by registrars
+CREATE TABLE grade_scale
+(
+    letter TEXT PRIMARY KEY,
+    points NUMERIC(4, 2) NOT NULL CHECK (points >= 0)
+);
+
+INSERT INTO grade_scale (letter, points)
+VALUES ('A', 4.0),
+       ('A-', 3.7),
+       ('B+', 3.3),
+       ('B', 3.0),
+       ('B-', 2.7),
+       ('C+', 2.3),
+       ('C', 2.0),
+       ('C-', 1.7),
+       ('D+', 1.3),
+       ('D', 1.0),
+       ('F', 0.0);
 
 -- person_course
+-- role is how the person takes part in the course, only professors instruct.
+-- grade and grade_points are set together by the course's instructors, the
+-- points copied from grade_scale so changing the scale doesn't change them
 CREATE TABLE person_course
 (
-    person_id INTEGER NOT NULL,
-    course_id INTEGER NOT NULL,
-    PRIMARY KEY (person_id, course_id),
+    person_id    INTEGER                                                                NOT NULL,
+    course_id    INTEGER                                                                NOT NULL,
+    term_id      INTEGER                                                                NOT NULL,
+    role         TEXT CHECK (role IN ('instructor', 'student', 'ta')) DEFAULT 'student' NOT NULL,
+    grade        TEXT,
+    grade_points NUMERIC(4, 2),
+    CHECK ((grade IS NULL) = (grade_points IS NULL)),
+    PRIMARY KEY (person_id, course_id, term_id),
     FOREIGN KEY (person_id) REFERENCES person (id),
-    FOREIGN KEY (course_id) REFERENCES course (id)
-);
-
-INSERT INTO person_course (person_id, course_id)
-VALUES (1, 1),
-       (1, 2),
-       (1, 3),
-       (2, 1),
-       (2, 2),
-       (2, 3),
-       (3, 1),
-       (3, 2),
-       (3, 3),
-       (4, 1),
-       (4, 2),
-       (4, 3),
-       (5, 1),
-       (5, 2),
-       (5, 3);
\ No newline at end of file
+    FOREIGN KEY (course_id) REFERENCES course (id),
+    FOREIGN KEY (term_id) REFERENCES term (id)
+);
+
+CREATE INDEX person_course_course ON person_course (course_id, term_id);
+
+INSERT INTO person_course (person_id, course_id, term_id, role, grade, grade_points)
+VALUES (1, 1, 2, 'instructor', NULL, NULL),
+       (3, 1, 2, 'student', 'A', 4.0),
+       (4, 1, 2, 'student', 'B+', 3.3);
+
+INSERT INTO person_course (person_id, course_id, term_id, role)
+VALUES (1, 1, 3, 'instructor'),
+       (1, 2, 3, 'instructor'),
+       (1, 3, 3, 'instructor'),
+       (2, 1, 3, 'instructor'),
+       (2, 2, 3, 'instructor'),
+       (2, 3, 3, 'instructor'),
+       (3, 1, 3, 'ta');
+
+INSERT INTO person_course (person_id, course_id, term_id)
+VALUES (3, 2, 3),
+       (3, 3, 3),
+       (4, 1, 3),
+       (4, 2, 3),
+       (4, 3, 3),
+       (5, 1, 3),
+       (5, 3, 3);
+
+-- waitlist
+-- students waiting for a seat in a full course in a term, first in line has the
+-- lowest id
+CREATE TABLE waitlist
+(
+    id         BIGSERIAL PRIMARY KEY,
+    person_id  INTEGER                   NOT NULL,
+    course_id  INTEGER                   NOT NULL,
+    term_id    INTEGER                   NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    UNIQUE (person_id, course_id, term_id),
+    FOREIGN KEY (person_id) REFERENCES person (id) ON DELETE CASCADE,
+    FOREIGN KEY (course_id) REFERENCES course (id) ON DELETE CASCADE,
+    FOREIGN KEY (term_id) REFERENCES term (id)
+);
+
+CREATE INDEX waitlist_course ON waitlist (course_id, term_id, id);
+
+-- course_prerequisite
+-- courses a student must have completed before enrolling in course_id, never
+-- forming a cycle
+CREATE TABLE course_prerequisite
+(
+    course_id       INTEGER NOT NULL,
+    prerequisite_id INTEGER NOT NULL,
+    PRIMARY KEY (course_id, prerequisite_id),
+    CHECK (course_id <> prerequisite_id),
+    FOREIGN KEY (course_id) REFERENCES course (id) ON DELETE CASCADE,
+    FOREIGN KEY (prerequisite_id) REFERENCES course (id) ON DELETE CASCADE
+);
+
+CREATE INDEX course_prerequisite_prerequisite ON course_prerequisite (prerequisite_id);
+
+INSERT INTO course_prerequisite (course_id, prerequisite_id)
+VALUES (2, 1);
+
+-- course_meeting
+-- weekly meetings of a course, which nobody may have clash with another of
+-- their courses
+CREATE TYPE weekday AS ENUM ('monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday', 'sunday');
+
+CREATE TABLE course_meeting
+(
+    id         SERIAL PRIMARY KEY,
+    course_id  INTEGER         NOT NULL,
+    day        weekday         NOT NULL,
+    start_time TIME            NOT NULL,
+    end_time   TIME            NOT NULL,
+    location   TEXT DEFAULT '' NOT NULL,
+    CHECK (start_time < end_time),
+    FOREIGN KEY (course_id) REFERENCES course (id) ON DELETE CASCADE
+);
+
+CREATE INDEX course_meeting_course ON course_meeting (course_id);
+
+INSERT INTO course_meeting (course_id, day, start_time, end_time, location)
+VALUES (1, 'monday', '09:00', '10:30', 'Room 101'),
+       (1, 'wednesday', '09:00', '10:30', 'Room 101'),
+       (2, 'tuesday', '13:00', '14:30', 'Lab 2'),
+       (2, 'thursday', '13:00', '14:30', 'Lab 2'),
+       (3, 'friday', '10:00', '12:00', 'Studio');
+
+-- api_keys
+-- keys must survive a reseed, so this table is never dropped
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id         SERIAL PRIMARY KEY,
+    label      TEXT                  NOT NULL,
+    prefix     TEXT                  NOT NULL UNIQUE,
+    salt       TEXT                  NOT NULL,
+    hash       TEXT                  NOT NULL,
+    admin      BOOLEAN DEFAULT false NOT NULL,
+    role       TEXT DEFAULT '' NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- role was added after api_keys was first created
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS role TEXT DEFAULT '' NOT NULL;
+
+-- rate_limits
+-- token buckets shared by every api replica, losing them on a crash is harmless
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limits
+(
+    key        TEXT PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    allowed    BOOLEAN          NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL,
+    expires_at TIMESTAMPTZ      NOT NULL
+);
+
+-- expires_at was added after rate_limits was first created, a bucket is
+-- full again and can be pruned once it passes
+ALTER TABLE rate_limits
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ DEFAULT now() NOT NULL;
+
+CREATE INDEX IF NOT EXISTS rate_limits_expires ON rate_limits (expires_at);
+
+-- events
+-- outbox of every change to courses, people and rosters, written in the same
+-- transaction as the change. ids are handed out on insert, so a lower id can
+-- commit later; commit_seq numbers events in commit order for streams to
+-- resume from. Events older than the event history are pruned
+CREATE TABLE IF NOT EXISTS events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    type       TEXT                      NOT NULL,
+    payload    JSONB                     NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+    commit_seq BIGINT UNIQUE
+);
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS commit_seq BIGINT UNIQUE;
+CREATE INDEX IF NOT EXISTS events_created ON events (created_at);
+CREATE SEQUENCE IF NOT EXISTS events_commit_seq;
+
+UPDATE events e
+SET commit_seq = numbered.seq
+FROM (SELECT id, nextval('events_commit_seq') AS seq
+      FROM (SELECT id FROM events WHERE commit_seq IS NULL ORDER BY id) unnumbered) numbered
+WHERE e.id = numbered.id;
+
+-- numbers an event as its transaction commits. The lock is held until the
+-- commit, so no other transaction can number its events in between
+CREATE OR REPLACE FUNCTION events_number() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('events_commit_seq'));
+    UPDATE events SET commit_seq = nextval('events_commit_seq') WHERE id = NEW.id;
+    RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS events_number ON events;
+CREATE CONSTRAINT TRIGGER events_number
+    AFTER INSERT ON events
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE FUNCTION events_number();
+
+-- webhook_subscriptions
+-- an empty event_types receives every event
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id          SERIAL PRIMARY KEY,
+    url         TEXT                      NOT NULL,
+    secret      TEXT                      NOT NULL,
+    event_types TEXT[]      DEFAULT '{}'  NOT NULL,
+    active      BOOLEAN     DEFAULT true  NOT NULL,
+    created_at  TIMESTAMPTZ DEFAULT now() NOT NULL
+);
+
+-- webhook_deliveries
+-- one row per event and subscription, queued with the event and kept as the
+-- delivery log until its event is pruned
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id               BIGSERIAL PRIMARY KEY,
+    subscription_id  INTEGER                                                 NOT NULL,
+    event_id         BIGINT                                                  NOT NULL,
+    status           TEXT CHECK (status IN ('pending', 'delivered', 'dead')) DEFAULT 'pending' NOT NULL,
+    attempts         INTEGER     DEFAULT 0                                   NOT NULL,
+    next_attempt_at  TIMESTAMPTZ DEFAULT now()                               NOT NULL,
+    last_status_code INTEGER,
+    last_error       TEXT        DEFAULT ''                                  NOT NULL,
+    created_at       TIMESTAMPTZ DEFAULT now()                               NOT NULL,
+    delivered_at     TIMESTAMPTZ,
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    FOREIGN KEY (event_id) REFERENCES events (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_event ON webhook_deliveries (event_id);
+
+-- schema_version
+-- written last so it only matches database.SchemaVersion once the whole seed ran,
+-- bump both whenever this file changes
+CREATE TABLE IF NOT EXISTS schema_version
+(
+    version    INTEGER     NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL
+);
+
+DELETE FROM schema_version;
+INSERT INTO schema_version (version, applied_at)
+VALUES (11, now());
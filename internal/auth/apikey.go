@@ -0,0 +1,102 @@
+// generation and verification of api keys
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// KeyPrefix starts every generated api key so they are easy to recognise.
+const KeyPrefix = "ck"
+
+// ErrMalformedKey is returned when a presented key isn't in the generated format.
+var ErrMalformedKey = errors.New("malformed api key")
+
+// GeneratedKey holds the parts of a new api key. Plaintext is shown to the
+// caller once and never stored; only Salt and Hash are persisted.
+type GeneratedKey struct {
+	Plaintext string
+	Prefix    string
+	Salt      string
+	Hash      string
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateKey creates a new random api key of the form ck_<prefix>_<secret>.
+// The prefix identifies the key in the database and the secret is stored as
+// a salted SHA-256 hash.
+func GenerateKey() (GeneratedKey, error) {
+	prefix, err := randomHex(6)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+	salt, err := randomHex(16)
+	if err != nil {
+		return GeneratedKey{}, err
+	}
+
+	return GeneratedKey{
+		Plaintext: KeyPrefix + "_" + prefix + "_" + secret,
+		Prefix:    prefix,
+		Salt:      salt,
+		Hash:      HashSecret(salt, secret),
+	}, nil
+}
+
+// ParseKey splits a presented api key into its prefix and secret.
+func ParseKey(key string) (prefix, secret string, err error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != KeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrMalformedKey
+	}
+	return parts[1], parts[2], nil
+}
+
+// HashSecret returns the hex encoded SHA-256 of salt and secret. Keys are
+// long random values so a fast hash is sufficient.
+func HashSecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifySecret reports whether secret matches the stored salt and hash
+// using a constant time comparison.
+func VerifySecret(salt, secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(salt, secret)), []byte(hash)) == 1
+}
+
+// EqualKeys compares two plaintext keys in constant time.
+func EqualKeys(a, b string) bool {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+// KeyFromRequest returns the api key sent in the X-API-Key header or as
+// "Authorization: ApiKey <key>".
+func KeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenerateKey tests that a generated key parses and verifies against its
+// stored salt and hash.
+func TestGenerateKey(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key.Plaintext, KeyPrefix+"_"+key.Prefix+"_"))
+	assert.NotContains(t, key.Hash, key.Plaintext)
+
+	prefix, secret, err := ParseKey(key.Plaintext)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Prefix, prefix)
+	assert.True(t, VerifySecret(key.Salt, secret, key.Hash))
+	assert.False(t, VerifySecret(key.Salt, secret+"0", key.Hash))
+
+	other, err := GenerateKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.Salt, other.Salt)
+	assert.NotEqual(t, key.Plaintext, other.Plaintext)
+}
+
+// TestParseKey tests that malformed keys are rejected.
+func TestParseKey(t *testing.T) {
+	for _, key := range []string{"", "abc", "ck_abc", "xx_abc_def", "ck__def", "ck_abc_def_ghi"} {
+		_, _, err := ParseKey(key)
+		assert.ErrorIs(t, err, ErrMalformedKey, key)
+	}
+}
+
+// TestKeyFromRequest tests both supported ways of sending a key.
+func TestKeyFromRequest(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	assert.Equal(t, "", KeyFromRequest(req))
+
+	req.Header.Set("Authorization", "ApiKey ck_a_b")
+	assert.Equal(t, "ck_a_b", KeyFromRequest(req))
+
+	req.Header.Set("X-API-Key", "ck_c_d")
+	assert.Equal(t, "ck_c_d", KeyFromRequest(req))
+}
+
+// TestPrincipalContext tests storing and reading the principal on a context.
+func TestPrincipalContext(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	_, ok := FromContext(req.Context())
+	assert.False(t, ok)
+
+	ctx := WithPrincipal(req.Context(), &Principal{Subject: "ops", Roles: []string{RoleAdmin}})
+	p, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.True(t, p.HasRole(RoleAdmin))
+
+	var missing *Principal
+	assert.False(t, missing.HasRole(RoleAdmin))
+}
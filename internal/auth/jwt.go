@@ -0,0 +1,203 @@
+// verification of jwt bearer tokens issued by an external identity provider
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig configures how bearer tokens are verified. At least one of
+// HMACSecret or JWKSFile must be set.
+type JWTConfig struct {
+	HMACSecret []byte        // shared secret for HS256 tokens
+	JWKSFile   string        // path to a JWKS document with RS256 and ES256 public keys
+	Audience   string        // required aud claim, skipped when empty
+	Issuer     string        // required iss claim, skipped when empty
+	Leeway     time.Duration // allowed clock skew for exp and nbf
+}
+
+// JWTVerifier validates bearer tokens and maps their claims to a Principal.
+type JWTVerifier struct {
+	config  JWTConfig
+	keys    map[string]crypto.PublicKey // jwks keys by kid
+	methods []string
+}
+
+// Claims are the registered claims plus the role claims the API understands.
+// Roles may be sent as a "roles" array or a single "role" string, and
+// person_id links the caller to their row in the person table.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles    []string `json:"roles,omitempty"`
+	Role     string   `json:"role,omitempty"`
+	PersonID uint     `json:"person_id,omitempty"`
+}
+
+// NewJWTVerifier loads the JWKS file, if any, and returns a verifier.
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{config: config, keys: map[string]crypto.PublicKey{}}
+
+	if len(config.HMACSecret) > 0 {
+		v.methods = append(v.methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.JWKSFile != "" {
+		data, err := os.ReadFile(config.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading jwks file: %w", err)
+		}
+		keys, err := ParseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		v.methods = append(v.methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	if len(v.methods) == 0 {
+		return nil, errors.New("jwt verification needs an HMAC secret or a JWKS file")
+	}
+	return v, nil
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA and P-256 keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// ParseJWKS parses a JWKS document into public keys keyed by kid. Keys not
+// meant for signatures or of unsupported types are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing jwks: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %q: invalid modulus: %w", k.Kid, err)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %q: invalid exponent: %w", k.Kid, err)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %q: invalid x coordinate: %w", k.Kid, err)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %q: invalid y coordinate: %w", k.Kid, err)
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+// Pick the verification key for a token based on its alg and kid headers.
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return v.config.HMACSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// Verify checks the token's signature, exp, nbf, aud and iss and returns
+// the principal it describes. Only the roles known to the API are kept.
+func (v *JWTVerifier) Verify(tokenString string) (*Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(v.methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.config.Leeway),
+	}
+	if v.config.Audience != "" {
+		options = append(options, jwt.WithAudience(v.config.Audience))
+	}
+	if v.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.config.Issuer))
+	}
+
+	var claims Claims
+	if _, err := jwt.ParseWithClaims(tokenString, &claims, v.keyFunc, options...); err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	principal := &Principal{Subject: claims.Subject, Method: "jwt", PersonID: claims.PersonID}
+	roles := claims.Roles
+	if claims.Role != "" {
+		roles = append(roles, claims.Role)
+	}
+	for _, role := range roles {
+		if IsRole(role) && !principal.HasRole(role) {
+			principal.Roles = append(principal.Roles, role)
+		}
+	}
+	return principal, nil
+}
+
+// BearerToken returns the token sent as "Authorization: Bearer <token>".
+func BearerToken(r *http.Request) string {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
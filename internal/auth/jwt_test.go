@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func b64(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// Write a JWKS file holding the public halves of an RSA and an EC key.
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y)},
+			{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": b64(rsaKey.N), "e": "AQAB"},
+		},
+	}
+	data, err := json.Marshal(jwks)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func validClaims() Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "jdoe",
+			Issuer:    "sso",
+			Audience:  jwt.ClaimStrings{"college-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: []string{RoleProfessor, "janitor"},
+		Role:  RoleStudent,
+	}
+}
+
+// TestJWTVerifier tests signature, algorithm and claim checks for every
+// supported signing method.
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	secret := []byte("shared-secret")
+
+	verifier, err := NewJWTVerifier(JWTConfig{
+		HMACSecret: secret,
+		JWKSFile:   writeJWKS(t, rsaKey, ecKey),
+		Audience:   "college-api",
+		Issuer:     "sso",
+	})
+	assert.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims Claims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return signed
+	}
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	notYet := validClaims()
+	notYet.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"other-api"}
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"hs256", sign(jwt.SigningMethodHS256, "", secret, validClaims()), true},
+		{"rs256", sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, validClaims()), true},
+		{"es256", sign(jwt.SigningMethodES256, "ec-1", ecKey, validClaims()), true},
+		{"wrong secret", sign(jwt.SigningMethodHS256, "", []byte("other"), validClaims()), false},
+		{"unknown kid", sign(jwt.SigningMethodRS256, "rsa-2", rsaKey, validClaims()), false},
+		{"encryption key", sign(jwt.SigningMethodRS256, "enc-1", rsaKey, validClaims()), false},
+		{"key type mismatch", sign(jwt.SigningMethodES256, "rsa-1", ecKey, validClaims()), false},
+		{"unsupported alg", sign(jwt.SigningMethodHS512, "", secret, validClaims()), false},
+		{"none alg", sign(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()), false},
+		{"expired", sign(jwt.SigningMethodHS256, "", secret, expired), false},
+		{"not yet valid", sign(jwt.SigningMethodHS256, "", secret, notYet), false},
+		{"wrong audience", sign(jwt.SigningMethodHS256, "", secret, wrongAudience), false},
+		{"no expiry", sign(jwt.SigningMethodHS256, "", secret, noExpiry), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.token)
+			if !tt.valid {
+				assert.Error(t, err)
+				assert.Nil(t, principal)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "jdoe", principal.Subject)
+			assert.Equal(t, "jwt", principal.Method)
+			assert.Equal(t, []string{RoleProfessor, RoleStudent}, principal.Roles)
+		})
+	}
+}
+
+// TestNewJWTVerifier tests configuration errors.
+func TestNewJWTVerifier(t *testing.T) {
+	_, err := NewJWTVerifier(JWTConfig{})
+	assert.Error(t, err)
+
+	_, err = NewJWTVerifier(JWTConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`))
+	assert.Error(t, err)
+}
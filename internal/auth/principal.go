@@ -0,0 +1,56 @@
+// identity of the caller attached to each authenticated request
+package auth
+
+import "context"
+
+// Roles understood by the API.
+const (
+	RoleAdmin     = "admin"
+	RoleRegistrar = "registrar"
+	RoleProfessor = "professor"
+	RoleStudent   = "student"
+)
+
+// IsRole reports whether role is one of the roles understood by the API.
+func IsRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleRegistrar, RoleProfessor, RoleStudent:
+		return true
+	}
+	return false
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject  string   // api key label or token subject
+	Method   string   // how the caller authenticated, "api_key" or "jwt"
+	Roles    []string // roles granted to the caller
+	APIKeyID uint     // id of the api key used, 0 if none
+	PersonID uint     // person the caller is, 0 if not linked to a person
+}
+
+// HasRole reports whether the principal was granted role.
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored on ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,77 @@
+// declarative authorization rules applied per route in routes.GetRoutes
+package authz
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+)
+
+// Rule decides whether principal may perform request r. When access is
+// denied, reason explains why. err is set when the decision could not be
+// made, for example because a database lookup failed.
+type Rule func(r *http.Request, principal *auth.Principal) (allowed bool, reason string, err error)
+
+// AnyRole allows principals holding at least one of roles.
+func AnyRole(roles ...string) Rule {
+	return func(r *http.Request, principal *auth.Principal) (bool, string, error) {
+		for _, role := range roles {
+			if principal.HasRole(role) {
+				return true, "", nil
+			}
+		}
+		return false, "requires one of the roles: " + strings.Join(roles, ", "), nil
+	}
+}
+
+// Authenticated allows any identified principal.
+func Authenticated() Rule {
+	return func(r *http.Request, principal *auth.Principal) (bool, string, error) {
+		return true, "", nil
+	}
+}
+
+// Any allows the request when at least one rule does. The reasons of all
+// denying rules are combined.
+func Any(rules ...Rule) Rule {
+	return func(r *http.Request, principal *auth.Principal) (bool, string, error) {
+		var reasons []string
+		for _, rule := range rules {
+			allowed, reason, err := rule(r, principal)
+			if err != nil {
+				return false, "", err
+			}
+			if allowed {
+				return true, "", nil
+			}
+			reasons = append(reasons, reason)
+		}
+		return false, strings.Join(reasons, "; or "), nil
+	}
+}
+
+// Require only lets requests through that rule allows, responding with 403
+// and the reason otherwise.
+func Require(rule Rule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				http.Error(w, "Forbidden: request is not authenticated", http.StatusForbidden)
+				return
+			}
+
+			allowed, reason, err := rule(r, principal)
+			if err != nil {
+				http.Error(w, "Error checking permissions: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, "Forbidden: "+reason, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
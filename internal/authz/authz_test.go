@@ -0,0 +1,60 @@
+package authz
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func deny(reason string) Rule {
+	return func(r *http.Request, p *auth.Principal) (bool, string, error) {
+		return false, reason, nil
+	}
+}
+
+func failing(r *http.Request, p *auth.Principal) (bool, string, error) {
+	return false, "", errors.New("db down")
+}
+
+// TestRequire tests the status codes and reasons returned by Require.
+func TestRequire(t *testing.T) {
+	student := &auth.Principal{Subject: "jdoe", Roles: []string{auth.RoleStudent}}
+	registrar := &auth.Principal{Subject: "reg", Roles: []string{auth.RoleRegistrar}}
+
+	tests := []struct {
+		name         string
+		rule         Rule
+		principal    *auth.Principal
+		expectedCode int
+		expectedBody string
+	}{
+		{"role allowed", AnyRole(auth.RoleAdmin, auth.RoleRegistrar), registrar, http.StatusOK, ""},
+		{"role denied", AnyRole(auth.RoleAdmin, auth.RoleRegistrar), student, http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar\n"},
+		{"any allowed", Any(deny("not owner"), AnyRole(auth.RoleStudent)), student, http.StatusOK, ""},
+		{"any denied", Any(AnyRole(auth.RoleAdmin), deny("not owner")), student, http.StatusForbidden, "Forbidden: requires one of the roles: admin; or not owner\n"},
+		{"authenticated", Authenticated(), student, http.StatusOK, ""},
+		{"unauthenticated", Authenticated(), nil, http.StatusForbidden, "Forbidden: request is not authenticated\n"},
+		{"error", Any(failing), registrar, http.StatusInternalServerError, "Error checking permissions: db down\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/api/person", nil)
+			assert.NoError(t, err)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+
+			rr := httptest.NewRecorder()
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			Require(tt.rule)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// instrumented database connections that report every statement to hooks
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"unicode"
+)
+
+// Hook is called before a statement runs with the statement's name and
+// query. It returns the context to run the statement with and a function
+// called with the statement's error once it finishes.
+type Hook func(ctx context.Context, name, query string) (context.Context, func(error))
+
+// StatementName names a query by its verb and the first table it touches,
+// e.g. "select person" or "insert person_course", so queries can be grouped
+// without using the full sql as a label.
+func StatementName(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.'
+	})
+	if len(words) == 0 {
+		return "unknown"
+	}
+
+	verb := words[0]
+	after := map[string]bool{"from": true, "into": true, "update": true, "table": true}
+	if verb == "update" {
+		after = map[string]bool{"update": true}
+	}
+	skip := map[string]bool{"if": true, "not": true, "exists": true, "only": true}
+	for i, word := range words {
+		if !after[word] {
+			continue
+		}
+		for _, table := range words[i+1:] {
+			if !skip[table] {
+				return verb + " " + table
+			}
+		}
+	}
+	return verb
+}
+
+// Open returns a DB whose connections report every statement to hooks.
+func Open(drv driver.Driver, dsn string, hooks ...Hook) (*sql.DB, error) {
+	var connector driver.Connector
+	if dc, ok := drv.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		connector = c
+	} else {
+		connector = dsnConnector{dsn: dsn, driver: drv}
+	}
+	return sql.OpenDB(&instrumentedConnector{Connector: connector, hooks: hooks}), nil
+}
+
+// dsnConnector adapts a driver without connector support.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+type instrumentedConnector struct {
+	driver.Connector
+	hooks []Hook
+}
+
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, hooks: c.hooks}, nil
+}
+
+// Run every hook for a statement, returning the combined finish function.
+func runHooks(ctx context.Context, hooks []Hook, query string) (context.Context, func(error)) {
+	name := StatementName(query)
+	finishes := make([]func(error), 0, len(hooks))
+	for _, hook := range hooks {
+		var finish func(error)
+		ctx, finish = hook(ctx, name, query)
+		finishes = append(finishes, finish)
+	}
+	return ctx, func(err error) {
+		if err == driver.ErrSkip {
+			return
+		}
+		for i := len(finishes) - 1; i >= 0; i-- {
+			finishes[i](err)
+		}
+	}
+}
+
+// instrumentedConn wraps a driver connection. Statements run through
+// ExecContext, QueryContext and prepared statements are reported; the rest
+// is passed straight to the wrapped connection.
+type instrumentedConn struct {
+	driver.Conn
+	hooks []Hook
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, finish := runHooks(ctx, c.hooks, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	finish(err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, finish := runHooks(ctx, c.hooks, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	finish(err)
+	return rows, err
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query, hooks: c.hooks}, nil
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type instrumentedStmt struct {
+	driver.Stmt
+	query string
+	hooks []Hook
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, finish := runHooks(ctx, s.hooks, s.query)
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedToValues(args))
+	}
+	finish(err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, finish := runHooks(ctx, s.hooks, s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedToValues(args))
+	}
+	finish(err)
+	return rows, err
+}
+
+func (s *instrumentedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
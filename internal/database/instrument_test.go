@@ -0,0 +1,25 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStatementName tests naming queries by verb and table.
+func TestStatementName(t *testing.T) {
+	tests := map[string]string{
+		"SELECT id, name FROM course WHERE id = $1":                        "select course",
+		"SELECT EXISTS(SELECT 1 FROM person WHERE id = $1)":                "select person",
+		"INSERT INTO person_course (person_id, course_id) VALUES ($1, $2)": "insert person_course",
+		"UPDATE api_keys SET revoked_at = now() WHERE id = $1":             "update api_keys",
+		"\n        DELETE FROM course WHERE id = $1":                       "delete course",
+		"DROP TABLE IF EXISTS person_course":                               "drop person_course",
+		"CREATE UNLOGGED TABLE IF NOT EXISTS rate_limits (key TEXT)":       "create rate_limits",
+		"BEGIN": "begin",
+		"":      "unknown",
+	}
+	for query, name := range tests {
+		assert.Equal(t, name, StatementName(query), query)
+	}
+}
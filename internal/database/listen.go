@@ -0,0 +1,45 @@
+// postgres LISTEN/NOTIFY on a connection taken out of the pool
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Listen runs LISTEN channel on a connection of its own and calls notify
+// with the payload of each notification, in the order they were sent, until
+// ctx is done or the connection fails. The connection is closed afterwards
+// rather than returned to the pool still listening.
+func Listen(ctx context.Context, db *sql.DB, channel string, notify func(payload string)) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		if instrumented, ok := driverConn.(*instrumentedConn); ok {
+			driverConn = instrumented.Conn
+		}
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("listen requires a pgx connection")
+		}
+		defer pgxConn.Close()
+
+		if _, err := pgxConn.Conn().Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+		for {
+			notification, err := pgxConn.Conn().WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			notify(notification.Payload)
+		}
+	})
+}
@@ -0,0 +1,275 @@
+// grpc server mirroring the REST api over the shared store, with health and
+// reflection services
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/ratelimit"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	collegev1 "github.com/maya-kuzak/Go-API-Tech-Challenge/proto/college/v1"
+)
+
+// Server implements collegev1.CollegeServiceServer.
+type Server struct {
+	collegev1.UnimplementedCollegeServiceServer
+
+	Store   *store.Store
+	Handler *handlers.RequestHandler // authenticates callers like the REST routes
+	Limiter *ratelimit.Limiter       // the REST route group limits, disabled when nil
+}
+
+// New returns a grpc server for handler's database with the college
+// service, the standard health service and reflection registered. The
+// health service and reflection don't require credentials. College calls
+// take from limiter's buckets like the REST routes: the ip group per peer
+// before authenticating, then the course or person group per caller.
+func New(handler *handlers.RequestHandler, limiter *ratelimit.Limiter) (*grpc.Server, *health.Server) {
+	s := &Server{Store: store.New(handler.DB), Handler: handler, Limiter: limiter}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryLimit(ipLimit), s.unaryAuth, s.unaryLimit(groupLimit)),
+		grpc.ChainStreamInterceptor(s.streamLimit(ipLimit), s.streamAuth, s.streamLimit(groupLimit)),
+	)
+
+	collegev1.RegisterCollegeServiceServer(server, s)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(collegev1.CollegeService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	return server, healthServer
+}
+
+// Methods outside the college service, which are open like /healthz.
+func public(method string) bool {
+	return !strings.HasPrefix(method, "/"+collegev1.CollegeService_ServiceDesc.ServiceName+"/")
+}
+
+// Authenticate the caller from the authorization or x-api-key metadata, the
+// same credentials as the REST api's headers, and store the principal on the
+// context.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+
+	var token, key string
+	scheme, value, ok := strings.Cut(first("authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		token = strings.TrimSpace(value)
+	} else if ok && strings.EqualFold(scheme, "ApiKey") {
+		key = strings.TrimSpace(value)
+	}
+	if k := first("x-api-key"); k != "" {
+		key = k
+	}
+
+	principal, code, message := s.Handler.Identify(ctx, token, key)
+	if principal == nil {
+		if code == http.StatusUnauthorized {
+			return ctx, status.Error(codes.Unauthenticated, message)
+		}
+		return ctx, status.Error(codes.Internal, message)
+	}
+	ctx = logging.With(ctx, "principal", principal.Subject, "auth_method", principal.Method)
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if public(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// serverStream replaces the context of a stream with the authenticated one.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) streamAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if public(info.FullMethod) {
+		return handler(srv, stream)
+	}
+	ctx, err := s.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, serverStream{ServerStream: stream, ctx: ctx})
+}
+
+// Return the route group and key of the ip limit for a call.
+func ipLimit(ctx context.Context, _ string) (string, string) {
+	return "ip", peerKey(ctx)
+}
+
+// Return the route group and key of the REST group limit matching a call,
+// keyed by the authenticated caller.
+func groupLimit(ctx context.Context, method string) (string, string) {
+	key, ok := ratelimit.PrincipalKey(ctx)
+	if !ok {
+		key = peerKey(ctx)
+	}
+	return methodGroup(method), key
+}
+
+// Identify the caller by the IP of their connection.
+func peerKey(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return ratelimit.AddrKey(p.Addr.String())
+	}
+	return "ip:unknown"
+}
+
+// Return the REST route group of a college service method: person for the
+// calls on people, course for the rest like the course, term and roster
+// routes.
+func methodGroup(method string) string {
+	switch method[strings.LastIndex(method, "/")+1:] {
+	case "ListPeople", "GetPerson", "CreatePerson", "UpdatePerson", "DeletePerson", "GetGPA":
+		return "person"
+	}
+	return "course"
+}
+
+// Take a token for a college call from the bucket limit picks, or return
+// ResourceExhausted with a retry-after header when it's empty.
+func (s *Server) limit(ctx context.Context, method string, limit func(context.Context, string) (string, string)) error {
+	if public(method) {
+		return nil
+	}
+	group, key := limit(ctx, method)
+	allowed, wait := s.Limiter.Allow(ctx, group, key)
+	if allowed {
+		return nil
+	}
+	seconds := strconv.Itoa(int(wait.Seconds()))
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", seconds))
+	return status.Error(codes.ResourceExhausted, "Rate limit exceeded, retry in "+seconds+" seconds")
+}
+
+func (s *Server) unaryLimit(limit func(context.Context, string) (string, string)) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := s.limit(ctx, info.FullMethod, limit); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (s *Server) streamLimit(limit func(context.Context, string) (string, string)) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := s.limit(stream.Context(), info.FullMethod, limit); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// roles allowed by the REST route policies
+var (
+	registrarRoles = []string{auth.RoleAdmin, auth.RoleRegistrar}
+	staffRoles     = []string{auth.RoleAdmin, auth.RoleRegistrar, auth.RoleProfessor}
+)
+
+// Return the caller, or PermissionDenied unless they have one of roles.
+func requireRole(ctx context.Context, roles ...string) (*auth.Principal, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "request is not authenticated")
+	}
+	for _, role := range roles {
+		if principal.HasRole(role) {
+			return principal, nil
+		}
+	}
+	return principal, status.Error(codes.PermissionDenied, "Forbidden: requires one of the roles: "+strings.Join(roles, ", "))
+}
+
+// Allow the registrar, or professors assigned to the course in the term,
+// like the REST roster routes.
+func (s *Server) requireTeacher(ctx context.Context, courseID, termID uint) error {
+	principal, err := requireRole(ctx, registrarRoles...)
+	if err == nil || principal == nil {
+		return err
+	}
+	if principal.HasRole(auth.RoleProfessor) && principal.PersonID != 0 {
+		teaches, err := s.Store.Teaches(ctx, principal.PersonID, courseID, termID)
+		if err != nil {
+			return status.Error(codes.Internal, "Error checking course assignment: "+err.Error())
+		}
+		if teaches {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "Forbidden: professors may only manage courses they teach")
+}
+
+// Only let professors teaching the course in the term grade it, like the
+// grader policy of the REST grade route.
+func (s *Server) requireGrader(ctx context.Context, courseID, termID uint) error {
+	principal, err := requireRole(ctx, auth.RoleProfessor)
+	if err != nil {
+		return err
+	}
+	if principal.PersonID != 0 {
+		teaches, err := s.Store.Teaches(ctx, principal.PersonID, courseID, termID)
+		if err != nil {
+			return status.Error(codes.Internal, "Error checking course assignment: "+err.Error())
+		}
+		if teaches {
+			return nil
+		}
+	}
+	return status.Error(codes.PermissionDenied, "Forbidden: professors may only manage courses they teach")
+}
+
+// Only let admins override prerequisites.
+func requireOverride(ctx context.Context, override bool) error {
+	if !override {
+		return nil
+	}
+	_, err := requireRole(ctx, auth.RoleAdmin)
+	return err
+}
+
+// Map a store error to a status, prefixing unexpected errors with doing.
+func storeStatus(err error, doing string) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, store.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, store.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, "Error "+doing+": "+err.Error())
+}
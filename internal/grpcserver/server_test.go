@@ -0,0 +1,441 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/ratelimit"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+	collegev1 "github.com/maya-kuzak/Go-API-Tech-Challenge/proto/college/v1"
+)
+
+const adminKey = "test-admin-key"
+
+var (
+	personColumns = []string{"id", "first_name", "last_name", "type", "age"}
+	termColumns   = []string{"id", "name", "start", "end"}
+)
+
+// Serve the grpc api over an in-memory listener and return a client
+// connection to it.
+func dial(t *testing.T) (*grpc.ClientConn, sqlmock.Sqlmock) {
+	return dialLimited(t, nil)
+}
+
+// dialLimited is dial for a server taking from limiter's buckets.
+func dialLimited(t *testing.T, limiter *ratelimit.Limiter) (*grpc.ClientConn, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	server, _ := New(&handlers.RequestHandler{DB: db, AdminKey: adminKey}, limiter)
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, mock
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+// TestAuthentication tests that college calls need credentials while
+// health checks don't.
+func TestAuthentication(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+
+	_, err := client.ListCourses(context.Background(), &collegev1.ListCoursesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "API key or bearer token required", status.Convert(err).Message())
+
+	_, err = client.ListCourses(withKey("not-a-key"), &collegev1.ListCoursesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: collegev1.CollegeService_ServiceDesc.ServiceName,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false).AddRow(2, "Art", 20, nil, false))
+	courses, err := client.ListCourses(withKey(adminKey), &collegev1.ListCoursesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, courses.Courses, 2)
+	assert.Equal(t, "Art", courses.Courses[1].Name)
+	assert.Nil(t, courses.Courses[0].Capacity)
+	assert.Equal(t, uint32(20), courses.Courses[1].GetCapacity())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRateLimit tests that college calls share the REST route group limits
+// per caller, unary and streaming, while health checks aren't limited.
+func TestRateLimit(t *testing.T) {
+	conn, mock := dialLimited(t, &ratelimit.Limiter{
+		Store: ratelimit.NewMemoryStore(),
+		Limits: map[string]ratelimit.Limit{
+			"course": {Requests: 1, Period: time.Minute},
+			"person": {Requests: 1, Period: time.Minute},
+		},
+	})
+	client := collegev1.NewCollegeServiceClient(conn)
+
+	// only the first call of each group reaches the database
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}))
+	_, err := client.ListCourses(withKey(adminKey), &collegev1.ListCoursesRequest{})
+	assert.NoError(t, err)
+
+	var header metadata.MD
+	_, err = client.GetTerm(withKey(adminKey), &collegev1.GetTermRequest{Id: 1}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "Rate limit exceeded, retry in 60 seconds", status.Convert(err).Message())
+	assert.Equal(t, []string{"60"}, header.Get("retry-after"))
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person").
+		WillReturnRows(sqlmock.NewRows(personColumns))
+	for _, expectedCode := range []codes.Code{codes.OK, codes.ResourceExhausted} {
+		stream, err := client.ListPeople(withKey(adminKey), &collegev1.ListPeopleRequest{})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		if expectedCode == codes.OK {
+			assert.Equal(t, io.EOF, err)
+		} else {
+			assert.Equal(t, expectedCode, status.Code(err))
+		}
+	}
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthorization tests that calls check the REST routes' roles.
+func TestAuthorization(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+
+	generated, err := auth.GenerateKey()
+	assert.NoError(t, err)
+	expectKey := func(role string) {
+		mock.ExpectQuery("FROM api_keys WHERE prefix = \\$1").WithArgs(generated.Prefix).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "label", "salt", "hash", "admin", "role", "expires_at", "revoked_at"}).
+				AddRow(1, "lms", generated.Salt, generated.Hash, false, role, nil, nil))
+	}
+
+	expectKey(auth.RoleStudent)
+	stream, err := client.ListPeople(withKey(generated.Plaintext), &collegev1.ListPeopleRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	expectKey(auth.RoleProfessor)
+	_, err = client.CreateCourse(withKey(generated.Plaintext), &collegev1.CreateCourseRequest{Name: "Art"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "Forbidden: requires one of the roles: admin, registrar", status.Convert(err).Message())
+
+	// professor keys aren't linked to a person, so teach no courses
+	expectKey(auth.RoleProfessor)
+	_, err = client.Enroll(withKey(generated.Plaintext), &collegev1.EnrollRequest{CourseId: 1, PersonId: 3})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "Forbidden: professors may only manage courses they teach", status.Convert(err).Message())
+
+	// only admins override prerequisites
+	expectKey(auth.RoleRegistrar)
+	_, err = client.Enroll(withKey(generated.Plaintext), &collegev1.EnrollRequest{CourseId: 2, PersonId: 3, OverridePrerequisites: true})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "Forbidden: requires one of the roles: admin", status.Convert(err).Message())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestListPeople tests streaming people with their course ids in the
+// current term.
+func TestListPeople(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE age = \\$1 ORDER BY id LIMIT \\$2$").
+		WithArgs(51, 10).
+		WillReturnRows(sqlmock.NewRows(personColumns).
+			AddRow(3, "Larry", "Page", "student", 51).
+			AddRow(4, "Sergey", "Brin", "student", 51))
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT person_id, course_id, role FROM person_course WHERE person_id IN \\(\\$1, \\$2\\) AND term_id = \\$3").WithArgs(3, 4, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "course_id", "role"}).
+			AddRow(4, 1, "student").
+			AddRow(4, 2, "ta"))
+
+	age := int32(51)
+	stream, err := client.ListPeople(withKey(adminKey), &collegev1.ListPeopleRequest{Age: &age, Page: &collegev1.Page{Limit: 10}})
+	assert.NoError(t, err)
+
+	var people []*collegev1.Person
+	for {
+		person, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		people = append(people, person)
+	}
+	assert.Len(t, people, 2)
+	assert.Equal(t, "Larry", people[0].FirstName)
+	assert.Equal(t, collegev1.PersonType_PERSON_TYPE_STUDENT, people[0].Type)
+	assert.Empty(t, people[0].CourseIds)
+	assert.Equal(t, []uint32{1, 2}, people[1].CourseIds)
+	assert.Equal(t, []collegev1.EnrollmentRole{collegev1.EnrollmentRole_ENROLLMENT_ROLE_STUDENT, collegev1.EnrollmentRole_ENROLLMENT_ROLE_TA}, people[1].Roles)
+	assert.Equal(t, uint32(2), people[1].TermId)
+
+	// invalid paging is reported on the stream
+	stream, err = client.ListPeople(withKey(adminKey), &collegev1.ListPeopleRequest{Page: &collegev1.Page{Limit: -1}})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPersonAndEnrollment tests creating a person, the errors for missing
+// records and joining a full course's waitlist.
+func TestPersonAndEnrollment(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+	ctx := withKey(adminKey)
+
+	storetest.ExpectTerm(mock, 0)
+	_, err := client.CreatePerson(ctx, &collegev1.CreatePersonRequest{Person: &collegev1.PersonInput{FirstName: "Ada"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "Missing required fields", status.Convert(err).Message())
+
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO person").WithArgs("Ada", "Lovelace", "student", 36).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectLockTerm(mock, 2)
+	storetest.ExpectEvent(mock, "person.created")
+	mock.ExpectCommit()
+	person, err := client.CreatePerson(ctx, &collegev1.CreatePersonRequest{Person: &collegev1.PersonInput{
+		FirstName: "Ada", LastName: "Lovelace", Type: collegev1.PersonType_PERSON_TYPE_STUDENT, Age: 36,
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(6), person.Id)
+	assert.Equal(t, uint32(2), person.TermId)
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE").WithArgs("Grace Hopper").
+		WillReturnRows(sqlmock.NewRows(personColumns))
+	_, err = client.GetPerson(ctx, &collegev1.GetPersonRequest{Name: "Grace Hopper"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// course 2 is full, so they join its waitlist behind one other student
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(6).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(1))
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(6, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	mock.ExpectQuery("FROM course_prerequisite cp").WithArgs(2, 6, "2026-01-12").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}))
+	storetest.ExpectNoClashes(mock, 2, 6)
+	mock.ExpectQuery("SELECT \\(SELECT count").WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"enrolled", "waiting"}).AddRow(1, 1))
+	mock.ExpectExec("INSERT INTO waitlist").WithArgs(6, 2, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "waitlist.added")
+	mock.ExpectCommit()
+	enrollment, err := client.Enroll(ctx, &collegev1.EnrollRequest{CourseId: 2, PersonId: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), enrollment.CourseId)
+	assert.Equal(t, collegev1.EnrollmentStatus_ENROLLMENT_STATUS_WAITLISTED, enrollment.Status)
+	assert.Equal(t, uint32(2), enrollment.Position)
+	assert.Equal(t, uint32(2), enrollment.TermId)
+
+	// course 3 requires course 1, which they haven't taken
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(6).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(nil))
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(6, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, false))
+	mock.ExpectQuery("FROM course_prerequisite cp").WithArgs(3, 6, "2026-01-12").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false))
+	mock.ExpectRollback()
+	_, err = client.Enroll(ctx, &collegev1.EnrollRequest{CourseId: 3, PersonId: 6})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "Person 6 is missing prerequisites for course 3 in Spring 2026: Math (1)", status.Convert(err).Message())
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity"}))
+	mock.ExpectRollback()
+	_, err = client.Unenroll(ctx, &collegev1.UnenrollRequest{CourseId: 9, PersonId: 6})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMeetings tests listing a course's schedule with weekday enums and a
+// clashing meeting failing with FAILED_PRECONDITION.
+func TestMeetings(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+	ctx := withKey(adminKey)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("FROM course_meeting WHERE course_id IN").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "course_id", "day", "start", "end", "location"}).
+			AddRow(1, 1, "monday", "09:00", "10:30", "Room 101"))
+	meetings, err := client.ListMeetings(ctx, &collegev1.ListMeetingsRequest{CourseId: 1})
+	assert.NoError(t, err)
+	assert.Len(t, meetings.Meetings, 1)
+	assert.Equal(t, collegev1.Weekday_WEEKDAY_MONDAY, meetings.Meetings[0].Day)
+	assert.Equal(t, "09:00", meetings.Meetings[0].Start)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(3))
+	mock.ExpectExec("LOCK TABLE course_meeting").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("WITH courses").WithArgs(3, "monday", "10:00", "11:00").
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "id", "name", "day", "start", "end"}).
+			AddRow(4, 1, "Programming", "monday", "09:00", "10:30"))
+	mock.ExpectRollback()
+	_, err = client.AddMeeting(ctx, &collegev1.AddMeetingRequest{CourseId: 3, Day: collegev1.Weekday_WEEKDAY_MONDAY, Start: "10:00", End: "11:00"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.AddMeeting(ctx, &collegev1.AddMeetingRequest{CourseId: 3, Start: "10:00", End: "11:00"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTerms tests listing terms, the current term and refusing overlapping
+// ones.
+func TestTerms(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+	ctx := withKey(adminKey)
+
+	mock.ExpectQuery("FROM term ORDER BY start_date").
+		WillReturnRows(sqlmock.NewRows(termColumns).
+			AddRow(1, "Fall 2025", "2025-09-01", "2025-12-19").
+			AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15"))
+	terms, err := client.ListTerms(ctx, &collegev1.ListTermsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, terms.Terms, 2)
+	assert.Equal(t, "Fall 2025", terms.Terms[0].Name)
+
+	// id 0 is the current term
+	storetest.ExpectTerm(mock, 0)
+	term, err := client.GetTerm(ctx, &collegev1.GetTermRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), term.Id)
+	assert.Equal(t, "2026-01-12", term.Start)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE term").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM term WHERE id <> \\$1").WithArgs(0, "Summer 2026", "2026-05-01", "2026-08-14").
+		WillReturnRows(storetest.TermRows(storetest.CurrentTerm))
+	mock.ExpectRollback()
+	_, err = client.CreateTerm(ctx, &collegev1.CreateTermRequest{Name: "Summer 2026", Start: "2026-05-01", End: "2026-08-14"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "Term Summer 2026 overlaps Spring 2026 (2), which runs from 2026-01-12 to 2026-05-15", status.Convert(err).Message())
+
+	_, err = client.CreateTerm(ctx, &collegev1.CreateTermRequest{Name: "Summer 2026", Start: "2026-08-14", End: "2026-05-01"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGrades tests the grade scale, that only professors grade and reading a
+// GPA with and without grades.
+func TestGrades(t *testing.T) {
+	conn, mock := dial(t)
+	client := collegev1.NewCollegeServiceClient(conn)
+	ctx := withKey(adminKey)
+
+	mock.ExpectQuery("SELECT letter, points::float8 FROM grade_scale").
+		WillReturnRows(sqlmock.NewRows([]string{"letter", "points"}).AddRow("A", 4.0).AddRow("F", 0.0))
+	scale, err := client.GetGradeScale(ctx, &collegev1.GetGradeScaleRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, scale.Grades, 2)
+	assert.Equal(t, "F", scale.Grades[1].Letter)
+
+	_, err = client.SetGrade(ctx, &collegev1.SetGradeRequest{CourseId: 1, PersonId: 3, Letter: "A"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "Forbidden: requires one of the roles: professor", status.Convert(err).Message())
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(1, "Fall 2025", 3, 3.0).
+			AddRow(2, "Spring 2026", 4, 4.0).
+			AddRow(nil, nil, 7, 3.57))
+	gpa, err := client.GetGPA(ctx, &collegev1.GetGPARequest{PersonId: 3})
+	assert.NoError(t, err)
+	assert.Len(t, gpa.Terms, 2)
+	assert.Equal(t, "Spring 2026", gpa.Terms[1].Term)
+	assert.Equal(t, 3.57, gpa.Cumulative.GetGpa())
+	assert.Equal(t, uint32(7), gpa.Cumulative.Credits)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).AddRow(nil, nil, 0, nil))
+	gpa, err = client.GetGPA(ctx, &collegev1.GetGPARequest{PersonId: 5})
+	assert.NoError(t, err)
+	assert.Empty(t, gpa.Terms)
+	assert.Nil(t, gpa.Cumulative.Gpa)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestReflection tests that reflection lists the college and health services.
+func TestReflection(t *testing.T) {
+	conn, _ := dial(t)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().Service {
+		services = append(services, service.Name)
+	}
+	assert.Contains(t, services, "college.v1.CollegeService")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
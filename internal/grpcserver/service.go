@@ -0,0 +1,569 @@
+// college service methods, checking the same roles as the REST routes
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	collegev1 "github.com/maya-kuzak/Go-API-Tech-Challenge/proto/college/v1"
+)
+
+var personTypes = map[string]collegev1.PersonType{
+	"student":   collegev1.PersonType_PERSON_TYPE_STUDENT,
+	"professor": collegev1.PersonType_PERSON_TYPE_PROFESSOR,
+}
+
+var enrollmentStatuses = map[string]collegev1.EnrollmentStatus{
+	store.StatusEnrolled:   collegev1.EnrollmentStatus_ENROLLMENT_STATUS_ENROLLED,
+	store.StatusWaitlisted: collegev1.EnrollmentStatus_ENROLLMENT_STATUS_WAITLISTED,
+}
+
+var enrollmentRoles = map[string]collegev1.EnrollmentRole{
+	store.RoleInstructor: collegev1.EnrollmentRole_ENROLLMENT_ROLE_INSTRUCTOR,
+	store.RoleStudent:    collegev1.EnrollmentRole_ENROLLMENT_ROLE_STUDENT,
+	store.RoleTA:         collegev1.EnrollmentRole_ENROLLMENT_ROLE_TA,
+}
+
+// Return the store name of role, empty when it's unspecified.
+func fromEnrollmentRole(role collegev1.EnrollmentRole) string {
+	for name, value := range enrollmentRoles {
+		if role == value {
+			return name
+		}
+	}
+	return ""
+}
+
+func toCourse(course store.Course) *collegev1.Course {
+	msg := &collegev1.Course{Id: uint32(course.ID), Name: course.Name, RequiresInstructor: course.RequiresInstructor}
+	if course.Capacity != nil {
+		capacity := uint32(*course.Capacity)
+		msg.Capacity = &capacity
+	}
+	if course.Credits != nil {
+		credits := uint32(*course.Credits)
+		msg.Credits = &credits
+	}
+	return msg
+}
+
+// Convert an optional capacity or credits to the store's.
+func fromOptional(value *uint32) *uint {
+	if value == nil {
+		return nil
+	}
+	converted := uint(*value)
+	return &converted
+}
+
+// Weekday values follow store.Weekdays from 1, leaving 0 unspecified.
+func toMeeting(meeting store.Meeting) *collegev1.Meeting {
+	msg := &collegev1.Meeting{
+		Id:       uint32(meeting.ID),
+		CourseId: uint32(meeting.CourseID),
+		Start:    meeting.Start,
+		End:      meeting.End,
+		Location: meeting.Location,
+	}
+	for i, day := range store.Weekdays {
+		if meeting.Day == day {
+			msg.Day = collegev1.Weekday(i + 1)
+		}
+	}
+	return msg
+}
+
+// Return the store name of day, empty when it's unspecified.
+func fromWeekday(day collegev1.Weekday) string {
+	if day < 1 || int(day) > len(store.Weekdays) {
+		return ""
+	}
+	return store.Weekdays[day-1]
+}
+
+// Convert a person and where they stand on their courses in the term with
+// termID.
+func toPerson(person store.Person, termID uint, enrollments []store.Enrollment) *collegev1.Person {
+	msg := &collegev1.Person{
+		Id:        uint32(person.ID),
+		FirstName: person.FirstName,
+		LastName:  person.LastName,
+		Type:      personTypes[person.Type],
+		Age:       uint32(person.Age),
+		CourseIds: []uint32{},
+		TermId:    uint32(termID),
+	}
+	for _, enrollment := range enrollments {
+		if enrollment.Status == store.StatusWaitlisted {
+			msg.WaitlistedCourseIds = append(msg.WaitlistedCourseIds, uint32(enrollment.CourseID))
+		} else {
+			msg.CourseIds = append(msg.CourseIds, uint32(enrollment.CourseID))
+			msg.Roles = append(msg.Roles, enrollmentRoles[enrollment.Role])
+		}
+	}
+	return msg
+}
+
+func fromPersonInput(input *collegev1.PersonInput) store.PersonInput {
+	person := store.PersonInput{
+		FirstName: input.GetFirstName(),
+		LastName:  input.GetLastName(),
+		Age:       uint(input.GetAge()),
+		Courses:   []uint{},
+	}
+	for name, value := range personTypes {
+		if input.GetType() == value {
+			person.Type = name
+		}
+	}
+	for _, id := range input.GetCourseIds() {
+		person.Courses = append(person.Courses, uint(id))
+	}
+	for _, role := range input.GetRoles() {
+		person.Roles = append(person.Roles, fromEnrollmentRole(role))
+	}
+	return person
+}
+
+func fromPage(page *collegev1.Page) store.Page {
+	return store.Page{Limit: int(page.GetLimit()), Offset: int(page.GetOffset())}
+}
+
+func toTerm(term store.Term) *collegev1.Term {
+	return &collegev1.Term{Id: uint32(term.ID), Name: term.Name, Start: term.Start, End: term.End}
+}
+
+// Convert people to messages with their course ids and roles in the term
+// with termID, the current term when 0, loaded in one query.
+func (s *Server) withCourses(ctx context.Context, termID uint, people []store.Person) ([]*collegev1.Person, error) {
+	msgs := make([]*collegev1.Person, 0, len(people))
+	if len(people) == 0 {
+		return msgs, nil
+	}
+	term, err := s.Store.Term(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(people))
+	for i, person := range people {
+		ids[i] = person.ID
+	}
+	enrollments, err := s.Store.EnrollmentsByPerson(ctx, term.ID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, person := range people {
+		msgs = append(msgs, toPerson(person, term.ID, enrollments[person.ID]))
+	}
+	return msgs, nil
+}
+
+func (s *Server) ListCourses(ctx context.Context, req *collegev1.ListCoursesRequest) (*collegev1.ListCoursesResponse, error) {
+	courses, err := s.Store.ListCourses(ctx, fromPage(req.GetPage()))
+	if err != nil {
+		return nil, storeStatus(err, "querying courses")
+	}
+	resp := &collegev1.ListCoursesResponse{}
+	for _, course := range courses {
+		resp.Courses = append(resp.Courses, toCourse(course))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetCourse(ctx context.Context, req *collegev1.GetCourseRequest) (*collegev1.Course, error) {
+	course, err := s.Store.GetCourse(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying course")
+	}
+	return toCourse(course), nil
+}
+
+func (s *Server) CreateCourse(ctx context.Context, req *collegev1.CreateCourseRequest) (*collegev1.Course, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	course, err := s.Store.CreateCourse(ctx, req.GetName(), fromOptional(req.Capacity), fromOptional(req.Credits), req.GetRequiresInstructor())
+	if err != nil {
+		return nil, storeStatus(err, "creating course")
+	}
+	return toCourse(course), nil
+}
+
+func (s *Server) UpdateCourse(ctx context.Context, req *collegev1.UpdateCourseRequest) (*collegev1.Course, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	course, err := s.Store.UpdateCourse(ctx, uint(req.GetId()), req.GetName(), fromOptional(req.Capacity), fromOptional(req.Credits), req.GetRequiresInstructor())
+	if err != nil {
+		return nil, storeStatus(err, "updating course")
+	}
+	return toCourse(course), nil
+}
+
+func (s *Server) DeleteCourse(ctx context.Context, req *collegev1.DeleteCourseRequest) (*collegev1.DeleteCourseResponse, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := s.Store.DeleteCourse(ctx, uint(req.GetId())); err != nil {
+		return nil, storeStatus(err, "deleting course")
+	}
+	return &collegev1.DeleteCourseResponse{}, nil
+}
+
+func (s *Server) ListRoster(ctx context.Context, req *collegev1.ListRosterRequest) (*collegev1.ListRosterResponse, error) {
+	if _, err := requireRole(ctx, staffRoles...); err != nil {
+		return nil, err
+	}
+	roster, err := s.Store.Roster(ctx, uint(req.GetCourseId()), uint(req.GetTermId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying roster")
+	}
+	members := make([]store.Person, len(roster))
+	resp := &collegev1.ListRosterResponse{}
+	for i, member := range roster {
+		members[i] = member.Person
+		resp.Roles = append(resp.Roles, enrollmentRoles[member.Role])
+	}
+	if resp.People, err = s.withCourses(ctx, uint(req.GetTermId()), members); err != nil {
+		return nil, storeStatus(err, "querying courses")
+	}
+	return resp, nil
+}
+
+func (s *Server) Enroll(ctx context.Context, req *collegev1.EnrollRequest) (*collegev1.Enrollment, error) {
+	if err := s.requireTeacher(ctx, uint(req.GetCourseId()), uint(req.GetTermId())); err != nil {
+		return nil, err
+	}
+	if err := requireOverride(ctx, req.GetOverridePrerequisites()); err != nil {
+		return nil, err
+	}
+	opts := store.EnrollOptions{
+		Term:                  uint(req.GetTermId()),
+		Role:                  fromEnrollmentRole(req.GetRole()),
+		OverridePrerequisites: req.GetOverridePrerequisites(),
+	}
+	enrollment, err := s.Store.Enroll(ctx, uint(req.GetCourseId()), uint(req.GetPersonId()), opts)
+	if err != nil {
+		return nil, storeStatus(err, "adding to roster")
+	}
+	return &collegev1.Enrollment{
+		CourseId: uint32(enrollment.CourseID),
+		PersonId: uint32(enrollment.PersonID),
+		Status:   enrollmentStatuses[enrollment.Status],
+		Position: uint32(enrollment.Position),
+		TermId:   uint32(enrollment.TermID),
+		Role:     enrollmentRoles[enrollment.Role],
+	}, nil
+}
+
+func (s *Server) Unenroll(ctx context.Context, req *collegev1.UnenrollRequest) (*collegev1.UnenrollResponse, error) {
+	if err := s.requireTeacher(ctx, uint(req.GetCourseId()), uint(req.GetTermId())); err != nil {
+		return nil, err
+	}
+	if err := s.Store.Unenroll(ctx, uint(req.GetCourseId()), uint(req.GetPersonId()), uint(req.GetTermId())); err != nil {
+		return nil, storeStatus(err, "removing from roster")
+	}
+	return &collegev1.UnenrollResponse{}, nil
+}
+
+func (s *Server) ListPrerequisites(ctx context.Context, req *collegev1.ListPrerequisitesRequest) (*collegev1.ListPrerequisitesResponse, error) {
+	prerequisites, err := s.Store.Prerequisites(ctx, uint(req.GetCourseId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying prerequisites")
+	}
+	resp := &collegev1.ListPrerequisitesResponse{}
+	for _, course := range prerequisites {
+		resp.Courses = append(resp.Courses, toCourse(course))
+	}
+	return resp, nil
+}
+
+func (s *Server) AddPrerequisite(ctx context.Context, req *collegev1.AddPrerequisiteRequest) (*collegev1.AddPrerequisiteResponse, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := s.Store.AddPrerequisite(ctx, uint(req.GetCourseId()), uint(req.GetPrerequisiteId())); err != nil {
+		return nil, storeStatus(err, "adding prerequisite")
+	}
+	return &collegev1.AddPrerequisiteResponse{}, nil
+}
+
+func (s *Server) RemovePrerequisite(ctx context.Context, req *collegev1.RemovePrerequisiteRequest) (*collegev1.RemovePrerequisiteResponse, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := s.Store.RemovePrerequisite(ctx, uint(req.GetCourseId()), uint(req.GetPrerequisiteId())); err != nil {
+		return nil, storeStatus(err, "removing prerequisite")
+	}
+	return &collegev1.RemovePrerequisiteResponse{}, nil
+}
+
+func (s *Server) ListMeetings(ctx context.Context, req *collegev1.ListMeetingsRequest) (*collegev1.ListMeetingsResponse, error) {
+	meetings, err := s.Store.Meetings(ctx, uint(req.GetCourseId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying meetings")
+	}
+	resp := &collegev1.ListMeetingsResponse{}
+	for _, meeting := range meetings {
+		resp.Meetings = append(resp.Meetings, toMeeting(meeting))
+	}
+	return resp, nil
+}
+
+func (s *Server) AddMeeting(ctx context.Context, req *collegev1.AddMeetingRequest) (*collegev1.Meeting, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	meeting, err := s.Store.AddMeeting(ctx, uint(req.GetCourseId()), store.Meeting{
+		Day:      fromWeekday(req.GetDay()),
+		Start:    req.GetStart(),
+		End:      req.GetEnd(),
+		Location: req.GetLocation(),
+	})
+	if err != nil {
+		return nil, storeStatus(err, "adding meeting")
+	}
+	return toMeeting(meeting), nil
+}
+
+func (s *Server) RemoveMeeting(ctx context.Context, req *collegev1.RemoveMeetingRequest) (*collegev1.RemoveMeetingResponse, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := s.Store.RemoveMeeting(ctx, uint(req.GetCourseId()), uint(req.GetMeetingId())); err != nil {
+		return nil, storeStatus(err, "removing meeting")
+	}
+	return &collegev1.RemoveMeetingResponse{}, nil
+}
+
+// ListPeople sends the matching people as they are loaded, one message each.
+func (s *Server) ListPeople(req *collegev1.ListPeopleRequest, stream collegev1.CollegeService_ListPeopleServer) error {
+	ctx := stream.Context()
+	if _, err := requireRole(ctx, staffRoles...); err != nil {
+		return err
+	}
+
+	filter := store.PersonFilter{Name: req.GetName()}
+	if req.Age != nil {
+		age := int(req.GetAge())
+		filter.Age = &age
+	}
+	people, err := s.Store.ListPeople(ctx, filter, fromPage(req.GetPage()))
+	if err != nil {
+		return storeStatus(err, "querying people")
+	}
+	msgs, err := s.withCourses(ctx, uint(req.GetTermId()), people)
+	if err != nil {
+		return storeStatus(err, "querying courses")
+	}
+	for _, msg := range msgs {
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetPerson(ctx context.Context, req *collegev1.GetPersonRequest) (*collegev1.Person, error) {
+	principal, err := requireRole(ctx, staffRoles...)
+	if principal == nil {
+		return nil, err
+	}
+	person, lookupErr := s.Store.GetPerson(ctx, req.GetName())
+	// students may read only their own record, without revealing whether
+	// anyone else exists
+	if err != nil {
+		if !principal.HasRole(auth.RoleStudent) || principal.PersonID == 0 || lookupErr != nil || person.ID != principal.PersonID {
+			return nil, status.Error(codes.PermissionDenied, "Forbidden: students may only access their own record")
+		}
+	} else if lookupErr != nil {
+		return nil, storeStatus(lookupErr, "querying person")
+	}
+
+	msgs, err := s.withCourses(ctx, uint(req.GetTermId()), []store.Person{person})
+	if err != nil {
+		return nil, storeStatus(err, "querying courses")
+	}
+	return msgs[0], nil
+}
+
+func (s *Server) CreatePerson(ctx context.Context, req *collegev1.CreatePersonRequest) (*collegev1.Person, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := requireOverride(ctx, req.GetOverridePrerequisites()); err != nil {
+		return nil, err
+	}
+	// resolved up front so the reply names the term even without courses
+	term, err := s.Store.Term(ctx, uint(req.GetTermId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying term")
+	}
+	input := fromPersonInput(req.GetPerson())
+	input.OverridePrerequisites = req.GetOverridePrerequisites()
+	input.Term = term.ID
+	person, enrollments, err := s.Store.CreatePerson(ctx, input)
+	if err != nil {
+		return nil, storeStatus(err, "creating person")
+	}
+	return toPerson(person, term.ID, enrollments), nil
+}
+
+func (s *Server) UpdatePerson(ctx context.Context, req *collegev1.UpdatePersonRequest) (*collegev1.Person, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := requireOverride(ctx, req.GetOverridePrerequisites()); err != nil {
+		return nil, err
+	}
+	term, err := s.Store.Term(ctx, uint(req.GetTermId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying term")
+	}
+	input := fromPersonInput(req.GetPerson())
+	input.OverridePrerequisites = req.GetOverridePrerequisites()
+	input.Term = term.ID
+	person, enrollments, err := s.Store.UpdatePerson(ctx, req.GetName(), input)
+	if err != nil {
+		return nil, storeStatus(err, "updating person")
+	}
+	return toPerson(person, term.ID, enrollments), nil
+}
+
+func (s *Server) DeletePerson(ctx context.Context, req *collegev1.DeletePersonRequest) (*collegev1.DeletePersonResponse, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := s.Store.DeletePerson(ctx, req.GetName()); err != nil {
+		return nil, storeStatus(err, "deleting person")
+	}
+	return &collegev1.DeletePersonResponse{}, nil
+}
+
+func (s *Server) ListTerms(ctx context.Context, req *collegev1.ListTermsRequest) (*collegev1.ListTermsResponse, error) {
+	terms, err := s.Store.ListTerms(ctx)
+	if err != nil {
+		return nil, storeStatus(err, "querying terms")
+	}
+	resp := &collegev1.ListTermsResponse{}
+	for _, term := range terms {
+		resp.Terms = append(resp.Terms, toTerm(term))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetTerm(ctx context.Context, req *collegev1.GetTermRequest) (*collegev1.Term, error) {
+	term, err := s.Store.Term(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying term")
+	}
+	return toTerm(term), nil
+}
+
+func (s *Server) CreateTerm(ctx context.Context, req *collegev1.CreateTermRequest) (*collegev1.Term, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	term, err := s.Store.CreateTerm(ctx, store.Term{Name: req.GetName(), Start: req.GetStart(), End: req.GetEnd()})
+	if err != nil {
+		return nil, storeStatus(err, "creating term")
+	}
+	return toTerm(term), nil
+}
+
+func (s *Server) UpdateTerm(ctx context.Context, req *collegev1.UpdateTermRequest) (*collegev1.Term, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	term, err := s.Store.UpdateTerm(ctx, uint(req.GetId()), store.Term{Name: req.GetName(), Start: req.GetStart(), End: req.GetEnd()})
+	if err != nil {
+		return nil, storeStatus(err, "updating term")
+	}
+	return toTerm(term), nil
+}
+
+func (s *Server) DeleteTerm(ctx context.Context, req *collegev1.DeleteTermRequest) (*collegev1.DeleteTermResponse, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := s.Store.DeleteTerm(ctx, uint(req.GetId())); err != nil {
+		return nil, storeStatus(err, "deleting term")
+	}
+	return &collegev1.DeleteTermResponse{}, nil
+}
+
+func toGradeScale(scale []store.GradeStep) *collegev1.GradeScale {
+	msg := &collegev1.GradeScale{}
+	for _, step := range scale {
+		msg.Grades = append(msg.Grades, &collegev1.GradeStep{Letter: step.Letter, Points: step.Points})
+	}
+	return msg
+}
+
+func toTermGPA(gpa store.TermGPA) *collegev1.TermGPA {
+	return &collegev1.TermGPA{TermId: uint32(gpa.TermID), Term: gpa.Term, Credits: uint32(gpa.Credits), Gpa: gpa.GPA}
+}
+
+func (s *Server) GetGradeScale(ctx context.Context, req *collegev1.GetGradeScaleRequest) (*collegev1.GradeScale, error) {
+	scale, err := s.Store.GradeScale(ctx)
+	if err != nil {
+		return nil, storeStatus(err, "querying grade scale")
+	}
+	return toGradeScale(scale), nil
+}
+
+func (s *Server) SetGradeScale(ctx context.Context, req *collegev1.SetGradeScaleRequest) (*collegev1.GradeScale, error) {
+	if _, err := requireRole(ctx, registrarRoles...); err != nil {
+		return nil, err
+	}
+	var scale []store.GradeStep
+	for _, step := range req.GetGrades() {
+		scale = append(scale, store.GradeStep{Letter: step.GetLetter(), Points: step.GetPoints()})
+	}
+	scale, err := s.Store.SetGradeScale(ctx, scale)
+	if err != nil {
+		return nil, storeStatus(err, "updating grade scale")
+	}
+	return toGradeScale(scale), nil
+}
+
+func (s *Server) SetGrade(ctx context.Context, req *collegev1.SetGradeRequest) (*collegev1.Grade, error) {
+	if err := s.requireGrader(ctx, uint(req.GetCourseId()), uint(req.GetTermId())); err != nil {
+		return nil, err
+	}
+	grade, err := s.Store.SetGrade(ctx, uint(req.GetCourseId()), uint(req.GetPersonId()), uint(req.GetTermId()), req.GetLetter())
+	if err != nil {
+		return nil, storeStatus(err, "setting grade")
+	}
+	return &collegev1.Grade{
+		CourseId: uint32(grade.CourseID),
+		PersonId: uint32(grade.PersonID),
+		TermId:   uint32(grade.TermID),
+		Letter:   grade.Letter,
+		Points:   grade.Points,
+	}, nil
+}
+
+func (s *Server) GetGPA(ctx context.Context, req *collegev1.GetGPARequest) (*collegev1.GPA, error) {
+	principal, err := requireRole(ctx, staffRoles...)
+	if principal == nil {
+		return nil, err
+	}
+	if err != nil && (!principal.HasRole(auth.RoleStudent) || principal.PersonID == 0 || uint32(principal.PersonID) != req.GetPersonId()) {
+		return nil, status.Error(codes.PermissionDenied, "Forbidden: students may only access their own record")
+	}
+
+	gpa, err := s.Store.GPA(ctx, uint(req.GetPersonId()))
+	if err != nil {
+		return nil, storeStatus(err, "querying GPA")
+	}
+	msg := &collegev1.GPA{PersonId: uint32(gpa.PersonID), Cumulative: toTermGPA(gpa.Cumulative)}
+	for _, term := range gpa.Terms {
+		msg.Terms = append(msg.Terms, toTermGPA(term))
+	}
+	return msg, nil
+}
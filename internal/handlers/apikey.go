@@ -0,0 +1,239 @@
+// api key lookup and the admin handlers that manage keys
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+)
+
+// Look up and verify a presented api key. On failure the principal is nil
+// and status and message describe the error.
+func (h *RequestHandler) authenticateKey(ctx context.Context, key string) (*auth.Principal, int, string) {
+	if h.AdminKey != "" && auth.EqualKeys(key, h.AdminKey) {
+		return &auth.Principal{Subject: "bootstrap", Method: "api_key", Roles: []string{auth.RoleAdmin}}, 0, ""
+	}
+
+	prefix, secret, err := auth.ParseKey(key)
+	if err != nil {
+		return nil, http.StatusUnauthorized, "Invalid API key"
+	}
+
+	var apiKey APIKey
+	var salt, hash string
+	var expiresAt, revokedAt sql.NullTime
+	err = h.DB.QueryRowContext(ctx,
+		"SELECT id, label, salt, hash, admin, role, expires_at, revoked_at FROM api_keys WHERE prefix = $1", prefix).
+		Scan(&apiKey.ID, &apiKey.Label, &salt, &hash, &apiKey.Admin, &apiKey.Role, &expiresAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, http.StatusUnauthorized, "Invalid API key"
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Error looking up API key: " + err.Error()
+	}
+
+	if !auth.VerifySecret(salt, secret, hash) {
+		return nil, http.StatusUnauthorized, "Invalid API key"
+	}
+	if revokedAt.Valid {
+		return nil, http.StatusUnauthorized, "API key has been revoked"
+	}
+	if expiresAt.Valid && !expiresAt.Time.After(time.Now()) {
+		return nil, http.StatusUnauthorized, "API key has expired"
+	}
+
+	principal := &auth.Principal{Subject: apiKey.Label, Method: "api_key", APIKeyID: apiKey.ID}
+	if apiKey.Admin {
+		principal.Roles = append(principal.Roles, auth.RoleAdmin)
+	}
+	if apiKey.Role != "" {
+		principal.Roles = append(principal.Roles, apiKey.Role)
+	}
+	return principal, 0, ""
+}
+
+// Insert a newly generated key. tx may be nil to use the handler's DB.
+func (h *RequestHandler) insertAPIKey(r *http.Request, tx *sql.Tx, newKey NewAPIKey) (APIKey, error) {
+	generated, err := auth.GenerateKey()
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	apiKey := APIKey{
+		Label:     newKey.Label,
+		Prefix:    generated.Prefix,
+		Admin:     newKey.Admin,
+		Role:      newKey.Role,
+		ExpiresAt: newKey.ExpiresAt,
+		Key:       generated.Plaintext,
+	}
+
+	query := `
+        INSERT INTO api_keys (label, prefix, salt, hash, admin, role, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, created_at
+    `
+	args := []interface{}{apiKey.Label, apiKey.Prefix, generated.Salt, generated.Hash, apiKey.Admin, apiKey.Role, apiKey.ExpiresAt}
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(r.Context(), query, args...)
+	} else {
+		row = h.DB.QueryRowContext(r.Context(), query, args...)
+	}
+	if err := row.Scan(&apiKey.ID, &apiKey.CreatedAt); err != nil {
+		return APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+// Return all api keys without their secrets.
+func (h *RequestHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	apiKeys := []APIKey{}
+
+	rows, err := h.DB.QueryContext(r.Context(),
+		"SELECT id, label, prefix, admin, role, created_at, expires_at, revoked_at FROM api_keys ORDER BY id")
+	if err != nil {
+		http.Error(w, "Error querying API keys: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var apiKey APIKey
+		err := rows.Scan(&apiKey.ID, &apiKey.Label, &apiKey.Prefix, &apiKey.Admin, &apiKey.Role, &apiKey.CreatedAt, &apiKey.ExpiresAt, &apiKey.RevokedAt)
+		if err != nil {
+			http.Error(w, "Error scanning API key data: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, "Error iterating over API keys: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, apiKeys)
+}
+
+// Create a new api key. The plaintext key is only included in this response.
+func (h *RequestHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var newKey NewAPIKey
+	if err := decode(r, &newKey); err != nil {
+		decodeError(w, err)
+		return
+	}
+
+	// Validate the key request
+	newKey.Label = strings.TrimSpace(newKey.Label)
+	if newKey.Label == "" {
+		http.Error(w, "API key label is required", http.StatusBadRequest)
+		return
+	}
+	// keys aren't linked to a person, so the professor and student rules
+	// about their own courses and records could never pass for one
+	if newKey.Role != "" && newKey.Role != auth.RoleRegistrar {
+		http.Error(w, "API key role must be registrar, use admin for admin keys and a bearer token with person_id for professors and students", http.StatusBadRequest)
+		return
+	}
+	if newKey.ExpiresAt != nil && !newKey.ExpiresAt.After(time.Now()) {
+		http.Error(w, "API key expiry must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	apiKey, err := h.insertAPIKey(r, nil, newKey)
+	if err != nil {
+		http.Error(w, "Error creating API key: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusCreated, apiKey)
+}
+
+// Revoke an api key and issue a replacement with the same label, role and
+// expiry. Revoked and expired keys can't be rotated. The plaintext of the new
+// key is only included in this response.
+func (h *RequestHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	intID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid API key ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.DB.BeginTx(r.Context(), nil)
+	if err != nil {
+		http.Error(w, "Error starting transaction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var oldKey NewAPIKey
+	var revokedAt sql.NullTime
+	err = tx.QueryRowContext(r.Context(),
+		"SELECT label, admin, role, expires_at, revoked_at FROM api_keys WHERE id = $1 FOR UPDATE", intID).
+		Scan(&oldKey.Label, &oldKey.Admin, &oldKey.Role, &oldKey.ExpiresAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error finding API key: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if revokedAt.Valid {
+		http.Error(w, "API key has been revoked", http.StatusConflict)
+		return
+	}
+	// its replacement would share the expiry and never authenticate
+	if oldKey.ExpiresAt != nil && !oldKey.ExpiresAt.After(time.Now()) {
+		http.Error(w, "API key has expired", http.StatusConflict)
+		return
+	}
+
+	_, err = tx.ExecContext(r.Context(), "UPDATE api_keys SET revoked_at = now() WHERE id = $1", intID)
+	if err != nil {
+		http.Error(w, "Error revoking API key: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiKey, err := h.insertAPIKey(r, tx, oldKey)
+	if err != nil {
+		http.Error(w, "Error creating API key: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Error committing API key rotation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusCreated, apiKey)
+}
+
+// Revoke an api key so it can no longer be used.
+func (h *RequestHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	intID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid API key ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.DB.ExecContext(r.Context(),
+		"UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", intID)
+	if err != nil {
+		http.Error(w, "Error revoking API key: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		http.Error(w, "API key not found or already revoked", http.StatusNotFound)
+		return
+	}
+
+	render(w, r, http.StatusOK, Message{Message: "API key revoked successfully"})
+}
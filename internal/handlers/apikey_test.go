@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateAPIKey tests that the plaintext key is returned and only its
+// hash is stored.
+func TestCreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	created := time.Now()
+	mock.ExpectQuery("INSERT INTO api_keys \\(label, prefix, salt, hash, admin, role, expires_at\\)").
+		WithArgs("lms", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), false, auth.RoleRegistrar, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, created))
+
+	body, _ := json.Marshal(NewAPIKey{Label: "lms", Role: auth.RoleRegistrar})
+	req, err := http.NewRequest("POST", "/api/admin/keys", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.CreateAPIKey(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var apiKey APIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&apiKey))
+	assert.Equal(t, uint(7), apiKey.ID)
+	prefix, _, err := auth.ParseKey(apiKey.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, apiKey.Prefix, prefix)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreateAPIKeyValidation tests that a label is required and that keys
+// only take roles they can act in without being linked to a person.
+func TestCreateAPIKeyValidation(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	for _, body := range []string{
+		`{"label":"  "}`,
+		`{"label":"lms","role":"admin"}`,
+		`{"label":"lms","role":"professor"}`,
+		`{"label":"lms","role":"student"}`,
+	} {
+		req, err := http.NewRequest("POST", "/api/admin/keys", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.CreateAPIKey(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+// TestRotateAPIKey tests that rotation revokes the old key and issues a new
+// one with the same label in a single transaction.
+func TestRotateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT label, admin, role, expires_at, revoked_at FROM api_keys WHERE id = \\$1 FOR UPDATE").
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"label", "admin", "role", "expires_at", "revoked_at"}).AddRow("lms", true, "", nil, nil))
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = now\\(\\) WHERE id = \\$1").
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO api_keys").
+		WithArgs("lms", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), true, "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, time.Now()))
+	mock.ExpectCommit()
+
+	req, err := http.NewRequest("POST", "/api/admin/keys/3/rotate", nil)
+	assert.NoError(t, err)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "3")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	handler.RotateAPIKey(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var apiKey APIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&apiKey))
+	assert.Equal(t, uint(4), apiKey.ID)
+	assert.True(t, apiKey.Admin)
+	assert.NotEmpty(t, apiKey.Key)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRotateAPIKeyRefused tests that revoked and expired keys aren't
+// rotated.
+func TestRotateAPIKeyRefused(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	tests := []struct {
+		name      string
+		expiresAt interface{}
+		revokedAt interface{}
+		expected  string
+	}{
+		{"revoked", nil, time.Now().Add(-time.Hour), "API key has been revoked\n"},
+		{"expired", time.Now().Add(-time.Minute), nil, "API key has expired\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT label, admin, role, expires_at, revoked_at FROM api_keys WHERE id = \\$1 FOR UPDATE").
+				WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"label", "admin", "role", "expires_at", "revoked_at"}).
+				AddRow("lms", false, "registrar", tt.expiresAt, tt.revokedAt))
+			mock.ExpectRollback()
+
+			req, err := http.NewRequest("POST", "/api/admin/keys/3/rotate", nil)
+			assert.NoError(t, err)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "3")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.RotateAPIKey(rr, req)
+
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Equal(t, tt.expected, rr.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestRevokeAPIKey tests revoking a key and revoking a missing key.
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = now\\(\\) WHERE id = \\$1 AND revoked_at IS NULL").
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = now\\(\\) WHERE id = \\$1 AND revoked_at IS NULL").
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	for _, expectedCode := range []int{http.StatusOK, http.StatusNotFound} {
+		req, err := http.NewRequest("DELETE", "/api/admin/keys/3", nil)
+		assert.NoError(t, err)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "3")
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		rr := httptest.NewRecorder()
+		handler.RevokeAPIKey(rr, req)
+		assert.Equal(t, expectedCode, rr.Code)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,82 @@
+// authentication middleware for api keys and jwt bearer tokens
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+)
+
+// Respond with 401 and the challenges telling the client how to
+// authenticate. bearerError is set when a bearer token was rejected.
+func (h *RequestHandler) unauthorized(w http.ResponseWriter, message, bearerError string) {
+	w.Header().Add("WWW-Authenticate", `ApiKey realm="college-api"`)
+	if h.JWT != nil {
+		challenge := `Bearer realm="college-api"`
+		if bearerError != "" {
+			challenge += `, error="invalid_token", error_description="` + bearerError + `"`
+		}
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// Store the principal on the context and add it to the request's logger.
+func withPrincipal(ctx context.Context, principal *auth.Principal) context.Context {
+	ctx = logging.With(ctx, "principal", principal.Subject, "auth_method", principal.Method)
+	return auth.WithPrincipal(ctx, principal)
+}
+
+// Authenticate requires a valid jwt bearer token or api key on every request
+// and stores the caller's principal on the request context.
+func (h *RequestHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := auth.BearerToken(r); token != "" && h.JWT != nil {
+			principal, err := h.JWT.Verify(token)
+			if err != nil {
+				h.unauthorized(w, "Invalid bearer token: "+err.Error(), "token is invalid or expired")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+			return
+		}
+
+		key := auth.KeyFromRequest(r)
+		if key == "" {
+			h.unauthorized(w, "API key or bearer token required", "")
+			return
+		}
+
+		principal, status, message := h.authenticateKey(r.Context(), key)
+		if principal == nil {
+			if status == http.StatusUnauthorized {
+				h.unauthorized(w, message, "")
+			} else {
+				http.Error(w, message, status)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+	})
+}
+
+// Identify authenticates a bearer token or api key presented outside of an
+// http request, such as in grpc metadata. The token is tried first when
+// bearer tokens are enabled. On failure the principal is nil and status and
+// message describe the error as Authenticate would.
+func (h *RequestHandler) Identify(ctx context.Context, token, key string) (*auth.Principal, int, string) {
+	if token != "" && h.JWT != nil {
+		principal, err := h.JWT.Verify(token)
+		if err != nil {
+			return nil, http.StatusUnauthorized, "Invalid bearer token: " + err.Error()
+		}
+		return principal, 0, ""
+	}
+	if key == "" {
+		return nil, http.StatusUnauthorized, "API key or bearer token required"
+	}
+	return h.authenticateKey(ctx, key)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyAuthColumns = []string{"id", "label", "salt", "hash", "admin", "role", "expires_at", "revoked_at"}
+
+// Return a handler that records the principal it was called with.
+func principalRecorder(principal **auth.Principal) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*principal, _ = auth.FromContext(r.Context())
+	})
+}
+
+// TestAuthenticate tests api key lookup against the database.
+func TestAuthenticate(t *testing.T) {
+	generated, err := auth.GenerateKey()
+	assert.NoError(t, err)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		key          string
+		row          []driver.Value
+		expectedCode int
+	}{
+		{"valid", generated.Plaintext, []driver.Value{1, "lms", generated.Salt, generated.Hash, false, "", nil, nil}, http.StatusOK},
+		{"registrar", generated.Plaintext, []driver.Value{1, "sis", generated.Salt, generated.Hash, false, "registrar", nil, nil}, http.StatusOK},
+		{"admin", generated.Plaintext, []driver.Value{1, "ops", generated.Salt, generated.Hash, true, "", nil, nil}, http.StatusOK},
+		{"wrong secret", generated.Plaintext + "0", []driver.Value{1, "lms", generated.Salt, generated.Hash, false, "", nil, nil}, http.StatusUnauthorized},
+		{"revoked", generated.Plaintext, []driver.Value{1, "lms", generated.Salt, generated.Hash, false, "", nil, past}, http.StatusUnauthorized},
+		{"expired", generated.Plaintext, []driver.Value{1, "lms", generated.Salt, generated.Hash, false, "", past, nil}, http.StatusUnauthorized},
+		{"unknown", generated.Plaintext, nil, http.StatusUnauthorized},
+		{"missing", "", nil, http.StatusUnauthorized},
+		{"malformed", "not-a-key", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			handler := &RequestHandler{DB: db}
+
+			if tt.row != nil {
+				mock.ExpectQuery("SELECT id, label, salt, hash, admin, role, expires_at, revoked_at FROM api_keys WHERE prefix = \\$1").
+					WithArgs(generated.Prefix).WillReturnRows(sqlmock.NewRows(apiKeyAuthColumns).AddRow(tt.row...))
+			} else if tt.name == "unknown" {
+				mock.ExpectQuery("FROM api_keys WHERE prefix = \\$1").
+					WithArgs(generated.Prefix).WillReturnRows(sqlmock.NewRows(apiKeyAuthColumns))
+			}
+
+			req, err := http.NewRequest("GET", "/api/course", nil)
+			assert.NoError(t, err)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+
+			var principal *auth.Principal
+			rr := httptest.NewRecorder()
+			handler.Authenticate(principalRecorder(&principal)).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, tt.row[1], principal.Subject)
+				assert.Equal(t, tt.row[4], principal.HasRole(auth.RoleAdmin))
+				assert.Equal(t, tt.row[5] == auth.RoleRegistrar, principal.HasRole(auth.RoleRegistrar))
+			} else {
+				assert.Nil(t, principal)
+				assert.Equal(t, `ApiKey realm="college-api"`, rr.Header().Get("WWW-Authenticate"))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestAuthenticateBootstrapKey tests that the configured admin key is
+// accepted without a database lookup.
+func TestAuthenticateBootstrapKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db, AdminKey: "bootstrap-key"}
+
+	req, err := http.NewRequest("GET", "/api/admin/keys", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "ApiKey bootstrap-key")
+
+	var principal *auth.Principal
+	rr := httptest.NewRecorder()
+	handler.Authenticate(principalRecorder(&principal)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, principal.HasRole(auth.RoleAdmin))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAuthenticateBearerToken tests that a jwt bearer token is verified and
+// its roles reach the handler.
+func TestAuthenticateBearerToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: []byte("secret"), Audience: "college-api"})
+	assert.NoError(t, err)
+	handler := &RequestHandler{DB: db, JWT: verifier}
+
+	sign := func(claims auth.Claims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		assert.NoError(t, err)
+		return token
+	}
+	valid := sign(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "jdoe",
+			Audience:  jwt.ClaimStrings{"college-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: []string{auth.RoleRegistrar},
+	})
+	expired := sign(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "jdoe",
+			Audience:  jwt.ClaimStrings{"college-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		},
+	})
+
+	req, err := http.NewRequest("GET", "/api/course", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+valid)
+
+	var principal *auth.Principal
+	rr := httptest.NewRecorder()
+	handler.Authenticate(principalRecorder(&principal)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "jdoe", principal.Subject)
+	assert.Equal(t, "jwt", principal.Method)
+	assert.True(t, principal.HasRole(auth.RoleRegistrar))
+
+	req.Header.Set("Authorization", "Bearer "+expired)
+	principal = nil
+	rr = httptest.NewRecorder()
+	handler.Authenticate(principalRecorder(&principal)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Nil(t, principal)
+	assert.Contains(t, rr.Header().Values("WWW-Authenticate")[1], `error="invalid_token"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,7 +2,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 
@@ -13,32 +12,26 @@ import (
 func (h *RequestHandler) GetAllCourses(w http.ResponseWriter, r *http.Request) {
 	var courses []Course
 
-	rows, err := h.DB.Query("SELECT * FROM course")
+	page, err := queryPage(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Error querying courses: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	for rows.Next() {
-		var course Course
-		err := rows.Scan(&course.ID, &course.Name)
-		if err != nil {
-			http.Error(w, "Error scanning course data: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		courses = append(courses, course)
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(courses); err != nil {
-		http.Error(w, "Error encoding response to JSON: "+err.Error(), http.StatusInternalServerError)
+	found, err := h.store().ListCourses(r.Context(), page)
+	if err != nil {
+		storeFailed(w, err, "querying courses")
 		return
 	}
+	for _, course := range found {
+		courses = append(courses, Course(course))
+	}
+
+	render(w, r, http.StatusOK, courses)
 
 }
 
 func (h *RequestHandler) GetCourse(w http.ResponseWriter, r *http.Request) {
-	var course Course
 	id := chi.URLParam(r, "id")
 
 	intID, err := strconv.Atoi(id)
@@ -47,17 +40,12 @@ func (h *RequestHandler) GetCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := h.DB.QueryRow("SELECT * FROM course WHERE id = $1", intID)
-	err = row.Scan(&course.ID, &course.Name)
+	course, err := h.store().GetCourse(r.Context(), uint(intID))
 	if err != nil {
-		http.Error(w, "Error querying course: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(course); err != nil {
-		http.Error(w, "Error encoding response to JSON: "+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "querying course")
 		return
 	}
+	render(w, r, http.StatusOK, Course(course))
 }
 
 func (h *RequestHandler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
@@ -76,65 +64,46 @@ func (h *RequestHandler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = json.NewDecoder(r.Body).Decode(&course)
-	if err != nil {
-		http.Error(w, "Invalid request body"+err.Error(), http.StatusInternalServerError)
+	if err := decode(r, &course); err != nil {
+		decodeError(w, err)
 		return
 	}
 
 	// Validate the Course object
 	if course.Name == "" {
-		http.Error(w, "Course name is required"+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Course name is required", http.StatusBadRequest)
 		return
 	}
 
-	//update course
-	_, err = h.DB.Exec("UPDATE course SET name = $1 WHERE id = $2", course.Name, intID)
+	updated, err := h.store().UpdateCourse(r.Context(), uint(intID), course.Name, course.Capacity, course.Credits, course.RequiresInstructor)
 	if err != nil {
-		http.Error(w, "Error updating course: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	//return updated course
-	row := h.DB.QueryRow("SELECT * FROM course WHERE id = $1", intID)
-	err = row.Scan(&course.ID, &course.Name)
-	if err != nil {
-		http.Error(w, "Invalid course ID"+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(course); err != nil {
-		http.Error(w, "Error encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "updating course")
 		return
 	}
+	render(w, r, http.StatusOK, Course(updated))
 }
 
 func (h *RequestHandler) CreateCourse(w http.ResponseWriter, r *http.Request) {
 	var course Course
 
-	err := json.NewDecoder(r.Body).Decode(&course)
-	if err != nil {
-		http.Error(w, "Invalid request body"+err.Error(), http.StatusInternalServerError)
+	if err := decode(r, &course); err != nil {
+		decodeError(w, err)
 		return
 	}
 
 	// Validate the Course object
 	if course.Name == "" {
-		http.Error(w, "Course name is required"+err.Error(), http.StatusBadRequest)
+		http.Error(w, "Course name is required", http.StatusBadRequest)
 		return
 	}
 
-	err = h.DB.QueryRow("INSERT INTO course (name) VALUES ($1) RETURNING id", course.Name).Scan(&course.ID)
+	created, err := h.store().CreateCourse(r.Context(), course.Name, course.Capacity, course.Credits, course.RequiresInstructor)
 	if err != nil {
-		http.Error(w, "Error creating course: "+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "creating course")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(course); err != nil {
-		http.Error(w, "Error encoding to JSON: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	render(w, r, http.StatusOK, Course(created))
 }
 
 func (h *RequestHandler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
@@ -147,19 +116,11 @@ func (h *RequestHandler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
 	}
 
 	//delete course
-	_, err = h.DB.Exec("DELETE FROM course WHERE id = $1", intID)
-	if err != nil {
-		http.Error(w, "Error deleting course: "+err.Error(), http.StatusInternalServerError)
+	if err := h.store().DeleteCourse(r.Context(), uint(intID)); err != nil {
+		storeFailed(w, err, "deleting course")
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
 
-	// Return a JSON-formatted deletion confirmation message
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	response := map[string]string{"message": "Course deleted successfully"}
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Error encoding to JSON: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	// deletion is confirmed by the status code, a 204 response has no body
+	w.WriteHeader(http.StatusNoContent)
 }
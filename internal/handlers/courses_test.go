@@ -10,6 +10,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,10 +23,10 @@ func TestGetAllCourses(t *testing.T) {
 	handler := &RequestHandler{DB: db}
 
 	// Mock the database response
-	rows := sqlmock.NewRows([]string{"id", "name"}).
-		AddRow(1, "Course 1").
-		AddRow(2, "Course 2")
-	mock.ExpectQuery("SELECT \\* FROM course").WillReturnRows(rows)
+	rows := sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).
+		AddRow(1, "Course 1", nil, nil, false).
+		AddRow(2, "Course 2", 30, 4, false)
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course").WillReturnRows(rows)
 
 	// Create a new HTTP request
 	req, err := http.NewRequest("GET", "/courses", nil)
@@ -43,6 +44,32 @@ func TestGetAllCourses(t *testing.T) {
 	assert.Len(t, courses, 2)
 	assert.Equal(t, "Course 1", courses[0].Name)
 	assert.Equal(t, "Course 2", courses[1].Name)
+	assert.Nil(t, courses[0].Capacity)
+	assert.Equal(t, uint(30), *courses[1].Capacity)
+}
+
+// TestGetAllCoursesPage tests the limit and offset query params.
+func TestGetAllCoursesPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course ORDER BY id LIMIT \\$1 OFFSET \\$2").WithArgs(2, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(5, "Course 5", nil, nil, false).AddRow(6, "Course 6", nil, nil, false))
+
+	rr := httptest.NewRecorder()
+	handler.GetAllCourses(rr, httptest.NewRequest("GET", "/api/course?limit=2&offset=4", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var courses []Course
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&courses))
+	assert.Equal(t, []Course{{ID: 5, Name: "Course 5"}, {ID: 6, Name: "Course 6"}}, courses)
+
+	rr = httptest.NewRecorder()
+	handler.GetAllCourses(rr, httptest.NewRequest("GET", "/api/course?limit=0", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 // TestGetCourse tests the GetCourse handler.
@@ -54,8 +81,8 @@ func TestGetCourse(t *testing.T) {
 	handler := &RequestHandler{DB: db}
 
 	// Mock the database response
-	row := sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Course 1")
-	mock.ExpectQuery("SELECT \\* FROM course WHERE id = \\$1").WithArgs(1).WillReturnRows(row)
+	row := sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Course 1", nil, nil, false)
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).WillReturnRows(row)
 
 	// Create a new HTTP request
 	req, err := http.NewRequest("GET", "/courses/1", nil)
@@ -87,15 +114,26 @@ func TestUpdateCourse(t *testing.T) {
 	handler := &RequestHandler{DB: db}
 
 	// Create a course object and marshal it to JSON
-	course := Course{Name: "Updated Course"}
+	capacity := uint(2)
+	course := Course{Name: "Updated Course", Capacity: &capacity}
 	courseJSON, _ := json.Marshal(course)
 
-	// Mock the database response
-	mock.ExpectExec("UPDATE course SET name = \\$1 WHERE id = \\$2").
-		WithArgs(course.Name, 1).
+	// Mock the database response, raising the capacity from 1 to 2 with a
+	// student waiting in the current term
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 1, 1)
+	mock.ExpectExec("UPDATE course SET name = \\$1, capacity = \\$2, credits = \\$3, requires_instructor = \\$4 WHERE id = \\$5").
+		WithArgs(course.Name, 2, nil, false, 1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
-	row := sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Updated Course")
-	mock.ExpectQuery("SELECT \\* FROM course WHERE id = \\$1").WithArgs(1).WillReturnRows(row)
+	storetest.ExpectEvent(mock, "course.updated")
+	mock.ExpectQuery("SELECT DISTINCT term_id FROM waitlist WHERE course_id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"term_id"}).AddRow(storetest.CurrentTerm.ID))
+	expectSeats(mock, 1, 1, 1)
+	mock.ExpectQuery("WITH promoted AS").WithArgs(1, storetest.CurrentTerm.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id"}).AddRow(4))
+	storetest.ExpectEvent(mock, "waitlist.removed")
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
 
 	// Create a new HTTP request
 	req, err := http.NewRequest("PUT", "/courses/1", bytes.NewBuffer(courseJSON))
@@ -116,6 +154,8 @@ func TestUpdateCourse(t *testing.T) {
 	err = json.NewDecoder(rr.Body).Decode(&updatedCourse)
 	assert.NoError(t, err)
 	assert.Equal(t, "Updated Course", updatedCourse.Name)
+	assert.Equal(t, uint(2), *updatedCourse.Capacity)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 // TestCreateCourse tests the CreateCourse handler.
@@ -131,9 +171,12 @@ func TestCreateCourse(t *testing.T) {
 	courseJSON, _ := json.Marshal(course)
 
 	// Mock the database response
-	mock.ExpectQuery("INSERT INTO course \\(name\\) VALUES \\(\\$1\\) RETURNING id").
-		WithArgs(course.Name).
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO course \\(name, capacity, credits, requires_instructor\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id").
+		WithArgs(course.Name, nil, nil, false).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	storetest.ExpectEvent(mock, "course.created")
+	mock.ExpectCommit()
 
 	// Create a new HTTP request
 	req, err := http.NewRequest("POST", "/courses", bytes.NewBuffer(courseJSON))
@@ -159,10 +202,25 @@ func TestDeleteCourse(t *testing.T) {
 
 	handler := &RequestHandler{DB: db}
 
-	// Mock the database response
-	mock.ExpectExec("DELETE FROM course WHERE id = \\$1").
+	// Mock the database response, clearing the course's roster in every term
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 1, nil)
+	mock.ExpectQuery("DELETE FROM person_course WHERE course_id = \\$1 RETURNING person_id, term_id").
 		WithArgs(1).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "term_id"}).AddRow(4, 2).AddRow(3, 2).AddRow(3, 1))
+	mock.ExpectQuery("DELETE FROM course WHERE id = \\$1 RETURNING name").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Math"))
+	for _, enrollment := range []string{
+		`{"person_id":3,"course_id":1,"term_id":1}`,
+		`{"person_id":3,"course_id":1,"term_id":2}`,
+		`{"person_id":4,"course_id":1,"term_id":2}`,
+	} {
+		mock.ExpectExec("INSERT INTO events").WithArgs("enrollment.removed", enrollment, "college_events").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	storetest.ExpectEvent(mock, "course.deleted")
+	mock.ExpectCommit()
 
 	// Create a new HTTP request
 	req, err := http.NewRequest("DELETE", "/courses/1", nil)
@@ -179,4 +237,15 @@ func TestDeleteCourse(t *testing.T) {
 
 	// Assert the response
 	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// a missing course is not found
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 1, false)
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.DeleteCourse(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Course not found\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,158 @@
+// server-sent event stream of changes to courses, people and rosters
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/stream"
+)
+
+// how often an idle stream sends a comment so proxies keep it open
+var eventHeartbeat = 15 * time.Second
+
+// Build a stream filter from the types, course_id and person_id querys.
+// types takes event types or the resources course, person and enrollment,
+// comma separated.
+func eventFilter(query url.Values) (stream.Filter, error) {
+	filter := stream.Filter{Types: map[string]bool{}}
+	if types := query.Get("types"); types != "" {
+		for _, name := range strings.Split(types, ",") {
+			name = strings.TrimSpace(name)
+			matched := false
+			for _, eventType := range store.EventTypes {
+				if eventType == name || strings.HasPrefix(eventType, name+".") {
+					filter.Types[eventType] = true
+					matched = true
+				}
+			}
+			if !matched {
+				return filter, fmt.Errorf("Unknown event type: %s", name)
+			}
+		}
+	}
+
+	ids := map[string]*uint{"course_id": &filter.CourseID, "person_id": &filter.PersonID}
+	for name, id := range ids {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return filter, fmt.Errorf("Invalid %s: %s", name, value)
+			}
+			*id = uint(parsed)
+		}
+	}
+	return filter, nil
+}
+
+// Write one event in the text/event-stream format.
+func writeEvent(w http.ResponseWriter, event stream.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
+
+// Stream change events as server-sent events until the client disconnects.
+// Takes the querys of eventFilter, and resumes after the Last-Event-ID
+// header (or last_event_id query) from the broker's history. When that's no
+// longer possible a reset event tells the client to refetch what it shows.
+func (h *RequestHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.Events == nil {
+		http.Error(w, "Event stream is not available", http.StatusServiceUnavailable)
+		return
+	}
+	filter, err := eventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var after int64
+	if lastEventID != "" {
+		after, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID: "+lastEventID, http.StatusBadRequest)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// subscribe before reading the history so nothing committed in between
+	// is missed
+	events, unsubscribe := h.Events.Subscribe()
+	defer unsubscribe()
+
+	replay, resumed := []stream.Event{}, true
+	if lastEventID != "" {
+		replay, resumed, err = h.Events.Replay(r.Context(), after)
+		if err != nil {
+			http.Error(w, "Error replaying events: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if !resumed {
+		// the empty id stops the client resuming from the lost position again
+		fmt.Fprint(w, "id\nevent: reset\ndata: {}\n\n")
+	}
+
+	replayed := map[int64]bool{}
+	for _, event := range replay {
+		replayed[event.ID] = true
+		if filter.Match(event) {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			// closed when this stream fell behind or the server is stopping,
+			// the client reconnects and resumes
+			if !ok {
+				return
+			}
+			if replayed[event.ID] || !filter.Match(event) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
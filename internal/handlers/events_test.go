@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/stream"
+)
+
+var eventColumns = []string{"id", "type", "payload", "created_at", "commit_seq"}
+
+// Open a stream on a test server, returning a reader of its body once the
+// handler has subscribed.
+func openStream(t *testing.T, handler *RequestHandler, target string, lastEventID string) *bufio.Reader {
+	server := httptest.NewServer(http.HandlerFunc(handler.StreamEvents))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequest("GET", server.URL+target, nil)
+	assert.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(resp.Body)
+	assert.Equal(t, "retry: 3000\n\n", readEvent(t, body))
+	return body
+}
+
+// Read the next blank line terminated event from a stream.
+func readEvent(t *testing.T, body *bufio.Reader) string {
+	var event strings.Builder
+	for {
+		line, err := body.ReadString('\n')
+		assert.NoError(t, err)
+		event.WriteString(line)
+		if line == "\n" || err != nil {
+			return event.String()
+		}
+	}
+}
+
+func testEvent(id int64, eventType, data string) stream.Event {
+	return stream.Event{ID: id, Type: eventType, CreatedAt: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC), Data: []byte(data), Seq: id}
+}
+
+// TestStreamEvents tests that live events are filtered by type and course.
+func TestStreamEvents(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	broker := stream.NewBroker(db)
+	body := openStream(t, &RequestHandler{DB: db, Events: broker}, "/api/events?types=enrollment,course.deleted&course_id=2", "")
+
+	broker.Broadcast(testEvent(1, "course.created", `{"id":2,"name":"Art"}`))
+	broker.Broadcast(testEvent(2, "enrollment.added", `{"person_id":3,"course_id":5}`))
+	broker.Broadcast(testEvent(3, "enrollment.added", `{"person_id":3,"course_id":2}`))
+	broker.Broadcast(testEvent(4, "course.deleted", `{"id":2,"name":"Art"}`))
+
+	assert.Equal(t, "id: 3\nevent: enrollment.added\n"+
+		`data: {"id":3,"type":"enrollment.added","created_at":"2024-09-01T12:00:00Z","data":{"person_id":3,"course_id":2}}`+"\n\n",
+		readEvent(t, body))
+	assert.Equal(t, "id: 4\nevent: course.deleted\n"+
+		`data: {"id":4,"type":"course.deleted","created_at":"2024-09-01T12:00:00Z","data":{"id":2,"name":"Art"}}`+"\n\n",
+		readEvent(t, body))
+}
+
+// TestStreamEventsResume tests replaying history after Last-Event-ID and
+// the reset sent once it's too old.
+func TestStreamEventsResume(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	broker := stream.NewBroker(db)
+	handler := &RequestHandler{DB: db, Events: broker}
+	created := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	// streams resume in commit order, event 4 committed after event 5
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM events WHERE commit_seq = \\$1").WithArgs(5, 86400.0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("SELECT id, type, payload, created_at, commit_seq FROM events WHERE commit_seq > \\$1 ORDER BY commit_seq LIMIT \\$2").WithArgs(5, 1001).
+		WillReturnRows(sqlmock.NewRows(eventColumns).
+			AddRow(4, "person.created", []byte(`{"id":6}`), created, 6).
+			AddRow(7, "person.deleted", []byte(`{"id":6}`), created, 7))
+	body := openStream(t, handler, "/api/events?types=person", "5")
+	assert.True(t, strings.HasPrefix(readEvent(t, body), "id: 6\nevent: person.created\ndata: {\"id\":4,"))
+	assert.True(t, strings.HasPrefix(readEvent(t, body), "id: 7\nevent: person.deleted\n"))
+
+	// live events already replayed aren't sent twice
+	broker.Broadcast(testEvent(7, "person.deleted", `{"id":6}`))
+	broker.Broadcast(testEvent(8, "person.created", `{"id":8}`))
+	assert.True(t, strings.HasPrefix(readEvent(t, body), "id: 8\nevent: person.created\n"))
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM events WHERE commit_seq = \\$1").WithArgs(1, 86400.0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	body = openStream(t, handler, "/api/events", "1")
+	assert.Equal(t, "id\nevent: reset\ndata: {}\n\n", readEvent(t, body))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestStreamEventsErrors tests the responses before a stream starts.
+func TestStreamEventsErrors(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	tests := []struct {
+		name         string
+		handler      *RequestHandler
+		target       string
+		lastEventID  string
+		expectedCode int
+		expectedBody string
+	}{
+		{"no broker", &RequestHandler{DB: db}, "/api/events", "", http.StatusServiceUnavailable, "Event stream is not available\n"},
+		{"unknown type", &RequestHandler{DB: db, Events: stream.NewBroker(db)}, "/api/events?types=tuition", "", http.StatusBadRequest, "Unknown event type: tuition\n"},
+		{"bad last event id", &RequestHandler{DB: db, Events: stream.NewBroker(db)}, "/api/events", "abc", http.StatusBadRequest, "Invalid Last-Event-ID: abc\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.target, nil)
+			assert.NoError(t, err)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+
+			rr := httptest.NewRecorder()
+			tt.handler.StreamEvents(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// handlers for streaming course and person exports as CSV or NDJSON
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	exportCSV    = "csv"
+	exportNDJSON = "ndjson"
+
+	// number of rows written between flushes to the client
+	exportFlushEvery = 100
+)
+
+// Pick the export format from the format query param, falling back to the
+// Accept header. CSV is used when the client expresses no preference.
+func exportFormat(r *http.Request) (string, bool) {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case exportCSV:
+		return exportCSV, true
+	case exportNDJSON, "jsonl":
+		return exportNDJSON, true
+	case "":
+	default:
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return exportCSV, true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case "text/csv":
+			return exportCSV, true
+		case "application/x-ndjson", "application/jsonl":
+			return exportNDJSON, true
+		case "*/*", "text/*":
+			return exportCSV, true
+		}
+	}
+	return "", false
+}
+
+// exportWriter writes one record at a time to the response in the chosen
+// format and periodically flushes it so rows reach the client as they are
+// read from the database.
+type exportWriter struct {
+	format  string
+	csv     *csv.Writer
+	json    *json.Encoder
+	flusher http.Flusher
+	written int
+}
+
+func newExportWriter(w http.ResponseWriter, format, filename string, header []string) (*exportWriter, error) {
+	ew := &exportWriter{format: format}
+	ew.flusher, _ = w.(http.Flusher)
+
+	switch format {
+	case exportCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		ew.csv = csv.NewWriter(w)
+		if err := ew.csv.Write(header); err != nil {
+			return nil, err
+		}
+	case exportNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.ndjson"`)
+		ew.json = json.NewEncoder(w)
+	}
+	return ew, nil
+}
+
+// Write a single record. record is used for CSV and value for NDJSON.
+func (ew *exportWriter) write(record []string, value interface{}) error {
+	var err error
+	if ew.format == exportCSV {
+		err = ew.csv.Write(record)
+	} else {
+		err = ew.json.Encode(value)
+	}
+	if err != nil {
+		return err
+	}
+
+	ew.written++
+	if ew.written%exportFlushEvery == 0 {
+		return ew.flush()
+	}
+	return nil
+}
+
+func (ew *exportWriter) flush() error {
+	if ew.csv != nil {
+		ew.csv.Flush()
+		if err := ew.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if ew.flusher != nil {
+		ew.flusher.Flush()
+	}
+	return nil
+}
+
+// Stream all Course objects from the database as CSV or NDJSON.
+func (h *RequestHandler) ExportCourses(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		http.Error(w, "Unsupported export format, use csv or ndjson", http.StatusNotAcceptable)
+		return
+	}
+
+	rows, err := h.DB.QueryContext(r.Context(), "SELECT id, name, capacity, credits, requires_instructor FROM course ORDER BY id")
+	if err != nil {
+		http.Error(w, "Error querying courses: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	ew, err := newExportWriter(w, format, "courses", []string{"id", "name", "capacity", "credits", "requires_instructor"})
+	if err != nil {
+		h.logger(r).Error("Error writing course export header", "error", err)
+		return
+	}
+
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.ID, &course.Name, &course.Capacity, &course.Credits, &course.RequiresInstructor); err != nil {
+			h.logger(r).Error("Error scanning course data during export", "error", err)
+			return
+		}
+		capacity, credits := "", ""
+		if course.Capacity != nil {
+			capacity = strconv.FormatUint(uint64(*course.Capacity), 10)
+		}
+		if course.Credits != nil {
+			credits = strconv.FormatUint(uint64(*course.Credits), 10)
+		}
+		record := []string{strconv.FormatUint(uint64(course.ID), 10), course.Name, capacity, credits, strconv.FormatBool(course.RequiresInstructor)}
+		if err := ew.write(record, course); err != nil {
+			h.logger(r).Error("Error writing course export", "error", err)
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		h.logger(r).Error("Error iterating over courses during export", "error", err)
+		return
+	}
+
+	if err := ew.flush(); err != nil {
+		h.logger(r).Error("Error flushing course export", "error", err)
+	}
+}
+
+// Stream all Person objects from the database as CSV or NDJSON. Accepts the
+// same name, age and term filters as GetAllPeople.
+func (h *RequestHandler) ExportPeople(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		http.Error(w, "Unsupported export format, use csv or ndjson", http.StatusNotAcceptable)
+		return
+	}
+	term, ok := h.queryTerm(w, r)
+	if !ok {
+		return
+	}
+
+	// courses and roles are aggregated in the same query so each row can be
+	// written as soon as it is read
+	filter, err := queryPersonFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conditions, args := filter.Conditions("p.", nil)
+	args = append(args, term.ID)
+	query := `
+        SELECT p.id, p.first_name, p.last_name, p.type, p.age, string_agg(pc.course_id::text, ',' ORDER BY pc.course_id),
+               string_agg(pc.role, ',' ORDER BY pc.course_id)
+        FROM person p
+        LEFT JOIN person_course pc ON pc.person_id = p.id AND pc.term_id = $` + strconv.Itoa(len(args))
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " GROUP BY p.id ORDER BY p.id"
+
+	rows, err := h.DB.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		http.Error(w, "Error querying person data: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	ew, err := newExportWriter(w, format, "people", []string{"id", "first_name", "last_name", "type", "age", "courses", "roles"})
+	if err != nil {
+		h.logger(r).Error("Error writing person export header", "error", err)
+		return
+	}
+
+	for rows.Next() {
+		person := CompletePerson{TermID: term.ID}
+		var courses, roles sql.NullString
+		if err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age, &courses, &roles); err != nil {
+			h.logger(r).Error("Error scanning person data during export", "error", err)
+			return
+		}
+
+		person.Courses = []uint{}
+		if courses.Valid && courses.String != "" {
+			for _, id := range strings.Split(courses.String, ",") {
+				courseID, err := strconv.ParseUint(id, 10, 0)
+				if err != nil {
+					h.logger(r).Error("Error parsing course ID during export", "error", err)
+					return
+				}
+				person.Courses = append(person.Courses, uint(courseID))
+			}
+			person.Roles = strings.Split(roles.String, ",")
+		}
+
+		record := []string{
+			strconv.FormatUint(uint64(person.ID), 10),
+			person.FirstName,
+			person.LastName,
+			person.Type,
+			strconv.FormatUint(uint64(person.Age), 10),
+			strings.ReplaceAll(courses.String, ",", ";"),
+			strings.ReplaceAll(roles.String, ",", ";"),
+		}
+		if err := ew.write(record, person); err != nil {
+			h.logger(r).Error("Error writing person export", "error", err)
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		h.logger(r).Error("Error iterating over people during export", "error", err)
+		return
+	}
+
+	if err := ew.flush(); err != nil {
+		h.logger(r).Error("Error flushing person export", "error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExportCoursesCSV tests that ExportCourses writes CSV by default.
+func TestExportCoursesCSV(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).
+		AddRow(1, "Programming", nil, 4, false).
+		AddRow(2, "UI, Design", 3, nil, true)
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course ORDER BY id").WillReturnRows(rows)
+
+	req, err := http.NewRequest("GET", "/api/course/export", nil)
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ExportCourses(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name,capacity,credits,requires_instructor\n1,Programming,,4,false\n2,\"UI, Design\",3,,true\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestExportPeopleNDJSON tests that ExportPeople honors the Accept header and
+// the GetAllPeople filters.
+func TestExportPeopleNDJSON(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "courses", "roles"}).
+		AddRow(1, "John", "Doe", "student", 25, "1,2", "student,ta").
+		AddRow(2, "John", "Smith", "professor", 25, nil, nil)
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("FROM person p LEFT JOIN person_course pc ON pc.person_id = p.id AND pc.term_id = \\$3 "+
+		"WHERE \\(p.first_name = \\$1 OR p.last_name = \\$1\\) AND p.age = \\$2 GROUP BY p.id ORDER BY p.id").
+		WithArgs("John", 25, storetest.CurrentTerm.ID).WillReturnRows(rows)
+
+	req, err := http.NewRequest("GET", "/api/person/export?name=John&age=25", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	rr := httptest.NewRecorder()
+	handler.ExportPeople(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	var people []CompletePerson
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var person CompletePerson
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &person))
+		people = append(people, person)
+	}
+	assert.Len(t, people, 2)
+	assert.Equal(t, []uint{1, 2}, people[0].Courses)
+	assert.Equal(t, []string{"student", "ta"}, people[0].Roles)
+	assert.Equal(t, []uint{}, people[1].Courses)
+	assert.Equal(t, storetest.CurrentTerm.ID, people[0].TermID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestExportPeopleCSV tests the CSV layout of ExportPeople selected via the
+// format query param.
+func TestExportPeopleCSV(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "courses", "roles"}).
+		AddRow(1, "John", "Doe", "student", 25, "1,2", "student,ta")
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("GROUP BY p.id ORDER BY p.id").WillReturnRows(rows)
+
+	req, err := http.NewRequest("GET", "/api/person/export?format=csv", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	rr := httptest.NewRecorder()
+	handler.ExportPeople(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "id,first_name,last_name,type,age,courses,roles\n1,John,Doe,student,25,1;2,student;ta\n", rr.Body.String())
+}
+
+// TestExportUnsupportedFormat tests that an unknown format is rejected
+// before the database is queried.
+func TestExportUnsupportedFormat(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	req, err := http.NewRequest("GET", "/api/course/export", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "application/xml")
+
+	rr := httptest.NewRecorder()
+	handler.ExportCourses(rr, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+// all handlers for grades on enrollments (person_course), the grade scale
+// (grade_scale) and GPAs
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// Return the letters grades can be given in, best first.
+func (h *RequestHandler) GetGradeScale(w http.ResponseWriter, r *http.Request) {
+	scale, err := h.store().GradeScale(r.Context())
+	if err != nil {
+		storeFailed(w, err, "querying grade scale")
+		return
+	}
+
+	steps := make([]GradeStep, len(scale))
+	for i, step := range scale {
+		steps[i] = GradeStep(step)
+	}
+	render(w, r, http.StatusOK, steps)
+}
+
+// Replace the grade scale. Grades already given keep their points.
+func (h *RequestHandler) UpdateGradeScale(w http.ResponseWriter, r *http.Request) {
+	var steps []GradeStep
+	if err := decode(r, &steps); err != nil {
+		decodeError(w, err)
+		return
+	}
+
+	scale := make([]store.GradeStep, len(steps))
+	for i, step := range steps {
+		scale[i] = store.GradeStep(step)
+	}
+	scale, err := h.store().SetGradeScale(r.Context(), scale)
+	if err != nil {
+		storeFailed(w, err, "updating grade scale")
+		return
+	}
+
+	steps = make([]GradeStep, len(scale))
+	for i, step := range scale {
+		steps[i] = GradeStep(step)
+	}
+	render(w, r, http.StatusOK, steps)
+}
+
+// Grade a student on a course's roster in the term named by the term query
+// param, or the current term. Only the course's professors get here.
+func (h *RequestHandler) SetGrade(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	personID, err := strconv.Atoi(chi.URLParam(r, "personID"))
+	if err != nil {
+		http.Error(w, "Invalid person ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	termID, ok := termParam(w, r)
+	if !ok {
+		return
+	}
+
+	var body CourseGrade
+	if err := decode(r, &body); err != nil {
+		decodeError(w, err)
+		return
+	}
+
+	grade, err := h.store().SetGrade(r.Context(), uint(courseID), uint(personID), termID, body.Letter)
+	if err != nil {
+		storeFailed(w, err, "setting grade")
+		return
+	}
+	render(w, r, http.StatusOK, CourseGrade(grade))
+}
+
+// Return a person's GPA in each term they were graded in and across all of
+// them, weighted by course credits.
+func (h *RequestHandler) GetPersonGPA(w http.ResponseWriter, r *http.Request) {
+	personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || personID < 1 {
+		http.Error(w, "Invalid person ID: "+chi.URLParam(r, "id"), http.StatusBadRequest)
+		return
+	}
+
+	gpa, err := h.store().GPA(r.Context(), uint(personID))
+	if err != nil {
+		storeFailed(w, err, "querying GPA")
+		return
+	}
+
+	body := PersonGPA{PersonID: gpa.PersonID, Terms: make([]TermGPA, len(gpa.Terms)), Cumulative: TermGPA(gpa.Cumulative)}
+	for i, term := range gpa.Terms {
+		body.Terms[i] = TermGPA(term)
+	}
+	render(w, r, http.StatusOK, body)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGradeScale tests reading and replacing the grade scale.
+func TestGradeScale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("SELECT letter, points::float8 FROM grade_scale ORDER BY points DESC, letter").
+		WillReturnRows(sqlmock.NewRows([]string{"letter", "points"}).AddRow("A", 4.0).AddRow("B", 3.0))
+	rr := httptest.NewRecorder()
+	handler.GetGradeScale(rr, routeRequest(t, "GET", "/api/grade-scale", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"letter":"A","points":4},{"letter":"B","points":3}]`, rr.Body.String())
+
+	// letters are upper cased, points rounded and the scale sorted best first
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM grade_scale").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO grade_scale \\(letter, points\\) VALUES \\(\\$1, \\$2\\)").WithArgs("P", 1.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO grade_scale \\(letter, points\\) VALUES \\(\\$1, \\$2\\)").WithArgs("F", 0.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "grade_scale.updated")
+	mock.ExpectCommit()
+	rr = httptest.NewRecorder()
+	handler.UpdateGradeScale(rr, routeRequest(t, "PUT", "/api/grade-scale", []GradeStep{{" f ", 0}, {"p", 0.999}}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"letter":"P","points":1},{"letter":"F","points":0}]`, rr.Body.String())
+
+	tests := []struct {
+		name         string
+		scale        []GradeStep
+		expectedBody string
+	}{
+		{"empty", []GradeStep{}, "The grade scale needs at least one letter\n"},
+		{"blank letter", []GradeStep{{" ", 1}}, "Grade letters can't be empty\n"},
+		{"duplicate", []GradeStep{{"A", 4}, {"a", 3}}, "Grade A is on the scale twice\n"},
+		{"negative", []GradeStep{{"F", -1}}, "Grade F must be worth between 0 and 99.99 points\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.UpdateGradeScale(rr, routeRequest(t, "PUT", "/api/grade-scale", tt.scale))
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSetGrade tests grading a student and refusing unknown letters, people
+// off the roster and anyone but students.
+func TestSetGrade(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	target := "/api/course/1/roster/3/grade"
+	expectPoints := func(letter string, points float64) {
+		rows := sqlmock.NewRows([]string{"points"})
+		if points >= 0 {
+			rows.AddRow(points)
+		}
+		mock.ExpectQuery("SELECT points::float8 FROM grade_scale WHERE letter = \\$1").WithArgs(letter).WillReturnRows(rows)
+	}
+	expectUpdate := func(letter string, points float64, role string) {
+		rows := sqlmock.NewRows([]string{"role"})
+		if role != "" {
+			rows.AddRow(role)
+		}
+		mock.ExpectQuery("UPDATE person_course SET grade = \\$1, grade_points = \\$2 "+
+			"WHERE person_id = \\$3 AND course_id = \\$4 AND term_id = \\$5 RETURNING role").
+			WithArgs(letter, points, 3, 1, storetest.CurrentTerm.ID).WillReturnRows(rows)
+	}
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	expectPoints("B+", 3.3)
+	expectUpdate("B+", 3.3, "student")
+	storetest.ExpectEvent(mock, "grade.set")
+	mock.ExpectCommit()
+	rr := httptest.NewRecorder()
+	handler.SetGrade(rr, routeRequest(t, "PUT", target, CourseGrade{Letter: "b+"}, "id", "1", "personID", "3"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"person_id":3,"course_id":1,"term_id":2,"letter":"B+","points":3.3}`, rr.Body.String())
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	expectPoints("E", -1)
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.SetGrade(rr, routeRequest(t, "PUT", target, CourseGrade{Letter: "E"}, "id", "1", "personID", "3"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Grade E is not on the grade scale\n", rr.Body.String())
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	expectPoints("A", 4)
+	expectUpdate("A", 4, "")
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.SetGrade(rr, routeRequest(t, "PUT", target, CourseGrade{Letter: "A"}, "id", "1", "personID", "3"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Person 3 is not on the roster of course 1 in Spring 2026\n", rr.Body.String())
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	expectPoints("A", 4)
+	expectUpdate("A", 4, "ta")
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.SetGrade(rr, routeRequest(t, "PUT", target, CourseGrade{Letter: "A"}, "id", "1", "personID", "3"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Only students are graded, person 3 is the ta of course 1\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.SetGrade(rr, routeRequest(t, "PUT", target, CourseGrade{}, "id", "1", "personID", "3"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Grade letter is required\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetPersonGPA tests the credit-weighted GPA in each term and across
+// terms.
+func TestGetPersonGPA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	gpaQuery := "SELECT t.id, t.name, COALESCE\\(SUM\\(COALESCE\\(c.credits, 1\\)\\), 0\\), .* " +
+		"GROUP BY ROLLUP \\(\\(t.start_date, t.id, t.name\\)\\) ORDER BY t.start_date NULLS LAST"
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(gpaQuery).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(1, "Fall 2025", 4, 4.0).
+			AddRow(2, "Spring 2026", 3, 3.3).
+			AddRow(nil, nil, 7, 3.7))
+	rr := httptest.NewRecorder()
+	handler.GetPersonGPA(rr, routeRequest(t, "GET", "/api/person/3/gpa", nil, "id", "3"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"person_id":3,"terms":[
+		{"term_id":1,"term":"Fall 2025","credits":4,"gpa":4},
+		{"term_id":2,"term":"Spring 2026","credits":3,"gpa":3.3}
+	],"cumulative":{"credits":7,"gpa":3.7}}`, rr.Body.String())
+
+	// without grades only the cumulative row comes back, without a GPA
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(gpaQuery).WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).AddRow(nil, nil, 0, nil))
+	rr = httptest.NewRecorder()
+	handler.GetPersonGPA(rr, routeRequest(t, "GET", "/api/person/5/gpa", nil, "id", "5"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"person_id":5,"terms":[],"cumulative":{"credits":0,"gpa":null}}`, rr.Body.String())
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	rr = httptest.NewRecorder()
+	handler.GetPersonGPA(rr, routeRequest(t, "GET", "/api/person/9/gpa", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Person not found\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.GetPersonGPA(rr, routeRequest(t, "GET", "/api/person/john/gpa", nil, "id", "john"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Invalid person ID: john\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
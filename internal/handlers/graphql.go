@@ -0,0 +1,1168 @@
+// graphql endpoint over courses, people and enrollments
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// gqlError is a resolver error with a machine readable code in its
+// extensions, e.g. {"code": "NOT_FOUND"}.
+type gqlError struct {
+	code    string
+	message string
+}
+
+func (e gqlError) Error() string {
+	return e.message
+}
+
+func (e gqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func badInput(message string) error {
+	return gqlError{code: "BAD_USER_INPUT", message: message}
+}
+
+func notFound(message string) error {
+	return gqlError{code: "NOT_FOUND", message: message}
+}
+
+func conflictError(message string) error {
+	return gqlError{code: "CONFLICT", message: message}
+}
+
+// roles of the REST policies the resolvers mirror
+var (
+	registrarRoles = []string{auth.RoleAdmin, auth.RoleRegistrar}
+	staffRoles     = []string{auth.RoleAdmin, auth.RoleRegistrar, auth.RoleProfessor}
+)
+
+// Return the request's principal if it holds one of roles.
+func requireRole(ctx context.Context, roles ...string) (*auth.Principal, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, gqlError{code: "UNAUTHENTICATED", message: "Forbidden: request is not authenticated"}
+	}
+	for _, role := range roles {
+		if principal.HasRole(role) {
+			return principal, nil
+		}
+	}
+	return principal, gqlError{code: "FORBIDDEN", message: "Forbidden: requires one of the roles: " + strings.Join(roles, ", ")}
+}
+
+// Allow admins, registrars and professors teaching the course in the term
+// to change its roster, like the teacher policy of the roster routes.
+func (h *RequestHandler) requireTeacher(ctx context.Context, courseID, termID uint) error {
+	principal, err := requireRole(ctx, registrarRoles...)
+	if err == nil || principal == nil {
+		return err
+	}
+	if principal.HasRole(auth.RoleProfessor) && principal.PersonID != 0 {
+		teaches, err := h.store().Teaches(ctx, principal.PersonID, courseID, termID)
+		if err != nil {
+			return err
+		}
+		if teaches {
+			return nil
+		}
+	}
+	return gqlError{code: "FORBIDDEN", message: "Forbidden: professors may only manage courses they teach"}
+}
+
+// Return the id of a Course or Person source value.
+func sourceID(source interface{}) uint {
+	switch v := source.(type) {
+	case store.Course:
+		return v.ID
+	case *store.Course:
+		return v.ID
+	case store.Person:
+		return v.ID
+	case *store.Person:
+		return v.ID
+	}
+	return 0
+}
+
+// Read the optional termId argument, 0 for the current term when omitted.
+func termArg(args map[string]interface{}) (uint, error) {
+	id, ok := args["termId"].(int)
+	if !ok {
+		return 0, nil
+	}
+	if id < 1 {
+		return 0, badInput("termId must be a positive integer")
+	}
+	return uint(id), nil
+}
+
+// Resolve the termId argument of a field to the id of its term, the current
+// term when omitted.
+func resolveTermArg(p graphql.ResolveParams) (uint, error) {
+	id, err := termArg(p.Args)
+	if err != nil {
+		return 0, err
+	}
+	termID, err := loadersFrom(p.Context).term(p.Context, id)
+	return termID, storeError(err)
+}
+
+// Schema builds the graphql schema resolved against h's database.
+func (h *RequestHandler) Schema() (graphql.Schema, error) {
+	personTypeEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "PersonType",
+		Description: "Whether a person studies or teaches.",
+		Values: graphql.EnumValueConfigMap{
+			"student":   &graphql.EnumValueConfig{Value: "student"},
+			"professor": &graphql.EnumValueConfig{Value: "professor"},
+		},
+	})
+
+	enrollmentStatusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "EnrollmentStatus",
+		Description: "Whether a person has a seat in a course or is waiting for one.",
+		Values: graphql.EnumValueConfigMap{
+			"enrolled":   &graphql.EnumValueConfig{Value: store.StatusEnrolled},
+			"waitlisted": &graphql.EnumValueConfig{Value: store.StatusWaitlisted},
+		},
+	})
+
+	enrollmentRoleEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "EnrollmentRole",
+		Description: "What a person does in a course. Only professors instruct, and only students take a seat and are graded.",
+		Values: graphql.EnumValueConfigMap{
+			"instructor": &graphql.EnumValueConfig{Value: store.RoleInstructor},
+			"student":    &graphql.EnumValueConfig{Value: store.RoleStudent},
+			"ta":         &graphql.EnumValueConfig{Value: store.RoleTA},
+		},
+	})
+
+	weekdays := graphql.EnumValueConfigMap{}
+	for _, day := range store.Weekdays {
+		weekdays[day] = &graphql.EnumValueConfig{Value: day}
+	}
+	weekdayEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Weekday",
+		Description: "A day a course meets on.",
+		Values:      weekdays,
+	})
+
+	termType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Term",
+		Description: "An academic term, which every enrollment belongs to.",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"start": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "First day as YYYY-MM-DD."},
+			"end":   &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Last day as YYYY-MM-DD."},
+		},
+	})
+	termIDArg := &graphql.ArgumentConfig{Type: graphql.Int, Description: "Id of the term, the current term when omitted."}
+
+	meetingType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Meeting",
+		Description: "A weekly meeting of a course.",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"courseId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"day":      &graphql.Field{Type: graphql.NewNonNull(weekdayEnum)},
+			"start":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Start time as HH:MM."},
+			"end":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "End time as HH:MM."},
+			"location": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	gradeStepType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "GradeStep",
+		Description: "A letter on the grade scale and the points it's worth.",
+		Fields: graphql.Fields{
+			"letter": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"points": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+	gradeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Grade",
+		Description: "A student's grade for a course in a term.",
+		Fields: graphql.Fields{
+			"personId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"courseId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"termId":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"letter":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"points":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "From the grade scale when graded."},
+		},
+	})
+	termGPAType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TermGPA",
+		Description: "A GPA over the graded courses of one term, or of every term.",
+		Fields: graphql.Fields{
+			"termId": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Null for the cumulative GPA.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if id := p.Source.(store.TermGPA).TermID; id > 0 {
+						return id, nil
+					}
+					return nil, nil
+				},
+			},
+			"term": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Name of the term, null for the cumulative GPA.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if term := p.Source.(store.TermGPA).Term; term != "" {
+						return term, nil
+					}
+					return nil, nil
+				},
+			},
+			"credits": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Of the graded courses, courses without credits count 1."},
+			"gpa":     &graphql.Field{Type: graphql.Float, Description: "Credit weighted, null without graded courses."},
+		},
+	})
+	gpaType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "GPA",
+		Fields: graphql.Fields{
+			"terms":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(termGPAType))), Description: "Each term graded in, in date order."},
+			"cumulative": &graphql.Field{Type: graphql.NewNonNull(termGPAType)},
+		},
+	})
+
+	var courseType, personType, enrollmentType *graphql.Object
+	courseType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Course",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"capacity": &graphql.Field{
+					Type:        graphql.Int,
+					Description: "Most students enrolled at once, null for no limit.",
+				},
+				"credits": &graphql.Field{
+					Type:        graphql.Int,
+					Description: "Weight of the course's grades in a GPA, null counts as 1.",
+				},
+				"requiresInstructor": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Boolean),
+					Description: "Whether students can only enroll in terms someone instructs the course.",
+				},
+				"prerequisites": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(courseType))),
+					Description: "Courses students must complete before enrolling.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadersFrom(p.Context).prerequisites.Load(p.Context, sourceID(p.Source)), nil
+					},
+				},
+				"meetings": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(meetingType))),
+					Description: "Weekly meetings in schedule order.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadersFrom(p.Context).meetings.Load(p.Context, sourceID(p.Source)), nil
+					},
+				},
+				"roster": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(personType)),
+					Description: "Everyone enrolled in or teaching the course in a term. Requires a staff role; null with an error otherwise.",
+					Args:        graphql.FieldConfigArgument{"termId": termIDArg},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if _, err := requireRole(p.Context, staffRoles...); err != nil {
+							return nil, err
+						}
+						termID, err := resolveTermArg(p)
+						if err != nil {
+							return nil, err
+						}
+						return loadersFrom(p.Context).rosterByCourse.Load(p.Context, termID, sourceID(p.Source)), nil
+					},
+				},
+				"enrollments": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(enrollmentType)),
+					Description: "Everyone on the roster in a term with their role. Requires a staff role; null with an error otherwise.",
+					Args:        graphql.FieldConfigArgument{"termId": termIDArg},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if _, err := requireRole(p.Context, staffRoles...); err != nil {
+							return nil, err
+						}
+						termID, err := resolveTermArg(p)
+						if err != nil {
+							return nil, err
+						}
+						return loadersFrom(p.Context).enrollmentsByCourse.Load(p.Context, termID, sourceID(p.Source)), nil
+					},
+				},
+			}
+		}),
+	})
+	personType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Person",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"firstName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"lastName":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"type":      &graphql.Field{Type: graphql.NewNonNull(personTypeEnum)},
+				"age":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"fullName": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "First and last name, as used to look people up.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						switch v := p.Source.(type) {
+						case store.Person:
+							return v.FullName(), nil
+						case *store.Person:
+							return v.FullName(), nil
+						}
+						return nil, nil
+					},
+				},
+				"courses": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(courseType))),
+					Description: "Courses the person is enrolled in or teaches in a term.",
+					Args:        graphql.FieldConfigArgument{"termId": termIDArg},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						termID, err := resolveTermArg(p)
+						if err != nil {
+							return nil, err
+						}
+						return loadersFrom(p.Context).coursesByPerson.Load(p.Context, termID, sourceID(p.Source)), nil
+					},
+				},
+				"enrollments": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(enrollmentType))),
+					Description: "The person's courses in a term with their role in each.",
+					Args:        graphql.FieldConfigArgument{"termId": termIDArg},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						termID, err := resolveTermArg(p)
+						if err != nil {
+							return nil, err
+						}
+						return loadersFrom(p.Context).enrollmentsByPerson.Load(p.Context, termID, sourceID(p.Source)), nil
+					},
+				},
+				"gpa": &graphql.Field{
+					Type:        graphql.NewNonNull(gpaType),
+					Description: "GPA in each term graded in and across all of them, weighted by course credits.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						gpa, err := h.store().GPA(p.Context, sourceID(p.Source))
+						return gpa, storeError(err)
+					},
+				},
+			}
+		}),
+	})
+	enrollmentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Enrollment",
+		Fields: graphql.Fields{
+			"personId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"courseId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"termId":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"role":     &graphql.Field{Type: graphql.NewNonNull(enrollmentRoleEnum)},
+			"status":   &graphql.Field{Type: graphql.NewNonNull(enrollmentStatusEnum)},
+			"position": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Place on the waitlist from 1, null once enrolled.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if position := p.Source.(store.Enrollment).Position; position > 0 {
+						return position, nil
+					}
+					return nil, nil
+				},
+			},
+			"person": &graphql.Field{
+				Type: personType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).personByID.Load(p.Context, p.Source.(store.Enrollment).PersonID), nil
+				},
+			},
+			"course": &graphql.Field{
+				Type: courseType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadersFrom(p.Context).courseByID.Load(p.Context, p.Source.(store.Enrollment).CourseID), nil
+				},
+			},
+		},
+	})
+	gradeStepInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "GradeStepInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"letter": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"points": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+		},
+	})
+	personInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "PersonInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"type":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(personTypeEnum)},
+			"age":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"courses": &graphql.InputObjectFieldConfig{
+				Type:        graphql.NewList(graphql.NewNonNull(graphql.Int)),
+				Description: "Ids of the person's courses, replacing their current ones in the term.",
+			},
+			"roles": &graphql.InputObjectFieldConfig{
+				Type:        graphql.NewList(graphql.NewNonNull(enrollmentRoleEnum)),
+				Description: "The person's role in each of courses, in the same order. Defaults to instructor for professors and student for students.",
+			},
+		},
+	})
+
+	pageArgs := graphql.FieldConfigArgument{
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, Description: "Return at most this many, ordered by id."},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Skip this many, ordered by id."},
+	}
+	nonNullInt := graphql.NewNonNull(graphql.Int)
+	nonNullString := graphql.NewNonNull(graphql.String)
+	capacityArg := &graphql.ArgumentConfig{Type: graphql.Int, Description: "Most students enrolled at once, no limit when omitted."}
+	creditsArg := &graphql.ArgumentConfig{Type: graphql.Int, Description: "Weight of the course's grades in a GPA, counting 1 when omitted."}
+	requiresInstructorArg := &graphql.ArgumentConfig{
+		Type:         graphql.Boolean,
+		DefaultValue: false,
+		Description:  "Only enroll students in terms someone instructs the course.",
+	}
+	overrideArg := &graphql.ArgumentConfig{
+		Type:         graphql.Boolean,
+		DefaultValue: false,
+		Description:  "Enroll students missing a course's prerequisites. Admins only.",
+	}
+	termArgs := graphql.FieldConfigArgument{
+		"name":  {Type: nonNullString},
+		"start": {Type: nonNullString, Description: "First day as YYYY-MM-DD."},
+		"end":   {Type: nonNullString, Description: "Last day as YYYY-MM-DD."},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"courses": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(courseType))),
+				Args:    pageArgs,
+				Resolve: h.resolveCourses,
+			},
+			"course": &graphql.Field{
+				Type:    courseType,
+				Args:    graphql.FieldConfigArgument{"id": {Type: nonNullInt}},
+				Resolve: h.resolveCourse,
+			},
+			"people": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(personType))),
+				Description: "Requires a staff role.",
+				Args: graphql.FieldConfigArgument{
+					"name":   {Type: graphql.String, Description: "Matches the first or last name."},
+					"age":    {Type: graphql.Int},
+					"limit":  pageArgs["limit"],
+					"offset": pageArgs["offset"],
+				},
+				Resolve: h.resolvePeople,
+			},
+			"person": &graphql.Field{
+				Type:        personType,
+				Description: "Staff may look up anyone, students only themselves.",
+				Args:        graphql.FieldConfigArgument{"name": {Type: nonNullString, Description: "First and last name."}},
+				Resolve:     h.resolvePerson,
+			},
+			"terms": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(termType))),
+				Description: "Every term in date order.",
+				Resolve:     h.resolveTerms,
+			},
+			"term": &graphql.Field{
+				Type:        termType,
+				Description: "A term, the current one, the latest to have started, when id is omitted.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.Int}},
+				Resolve:     h.resolveTerm,
+			},
+			"gradeScale": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(gradeStepType))),
+				Description: "The letters grades can be given in, best first.",
+				Resolve:     h.resolveGradeScale,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createCourse": &graphql.Field{
+				Type: graphql.NewNonNull(courseType),
+				Args: graphql.FieldConfigArgument{
+					"name":               {Type: nonNullString},
+					"capacity":           capacityArg,
+					"credits":            creditsArg,
+					"requiresInstructor": requiresInstructorArg,
+				},
+				Resolve: h.createCourse,
+			},
+			"updateCourse": &graphql.Field{
+				Type: graphql.NewNonNull(courseType),
+				Args: graphql.FieldConfigArgument{
+					"id":                 {Type: nonNullInt},
+					"name":               {Type: nonNullString},
+					"capacity":           capacityArg,
+					"credits":            creditsArg,
+					"requiresInstructor": requiresInstructorArg,
+				},
+				Resolve: h.updateCourse,
+			},
+			"deleteCourse": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Returns whether a course was deleted.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: nonNullInt}},
+				Resolve:     h.deleteCourse,
+			},
+			"createPerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"input":                 {Type: graphql.NewNonNull(personInput)},
+					"termId":                termIDArg,
+					"overridePrerequisites": overrideArg,
+				},
+				Resolve: h.createPerson,
+			},
+			"updatePerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"name":                  {Type: nonNullString, Description: "Current first and last name."},
+					"input":                 {Type: graphql.NewNonNull(personInput)},
+					"termId":                termIDArg,
+					"overridePrerequisites": overrideArg,
+				},
+				Resolve: h.updatePerson,
+			},
+			"deletePerson": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"name": {Type: nonNullString}},
+				Resolve: h.deletePerson,
+			},
+			"enroll": &graphql.Field{
+				Type:        graphql.NewNonNull(enrollmentType),
+				Description: "Add a person to a course's roster in a term with a role, or its waitlist when it's full. Enrolling someone already on either succeeds without change, unless asking for another role; students missing the course's prerequisites or its instructor, and anyone whose schedule it clashes with, fail with CONFLICT.",
+				Args: graphql.FieldConfigArgument{
+					"courseId":              {Type: nonNullInt},
+					"personId":              {Type: nonNullInt},
+					"role":                  {Type: enrollmentRoleEnum, Description: "Defaults to instructor for professors and student for students."},
+					"termId":                termIDArg,
+					"overridePrerequisites": overrideArg,
+				},
+				Resolve: h.enroll,
+			},
+			"unenroll": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"courseId": {Type: nonNullInt}, "personId": {Type: nonNullInt}, "termId": termIDArg},
+				Resolve: h.unenroll,
+			},
+			"addPrerequisite": &graphql.Field{
+				Type:        graphql.NewNonNull(courseType),
+				Description: "Require a course to be completed before another, returning the course. Fails with CONFLICT when it would form a cycle.",
+				Args:        graphql.FieldConfigArgument{"courseId": {Type: nonNullInt}, "prerequisiteId": {Type: nonNullInt}},
+				Resolve:     h.addPrerequisite,
+			},
+			"removePrerequisite": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"courseId": {Type: nonNullInt}, "prerequisiteId": {Type: nonNullInt}},
+				Resolve: h.removePrerequisite,
+			},
+			"addMeeting": &graphql.Field{
+				Type:        graphql.NewNonNull(meetingType),
+				Description: "Add a weekly meeting to a course. Fails with CONFLICT when it clashes with the schedule of anyone on the course.",
+				Args: graphql.FieldConfigArgument{
+					"courseId": {Type: nonNullInt},
+					"day":      {Type: graphql.NewNonNull(weekdayEnum)},
+					"start":    {Type: nonNullString, Description: "Start time as HH:MM."},
+					"end":      {Type: nonNullString, Description: "End time as HH:MM."},
+					"location": {Type: graphql.String},
+				},
+				Resolve: h.addMeeting,
+			},
+			"removeMeeting": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"courseId": {Type: nonNullInt}, "meetingId": {Type: nonNullInt}},
+				Resolve: h.removeMeeting,
+			},
+			"createTerm": &graphql.Field{
+				Type:        graphql.NewNonNull(termType),
+				Description: "Add a term. Fails with CONFLICT when another has its name or overlaps its dates.",
+				Args:        termArgs,
+				Resolve:     h.createTerm,
+			},
+			"updateTerm": &graphql.Field{
+				Type:        graphql.NewNonNull(termType),
+				Description: "Replace a term's name and dates. Fails with CONFLICT when another has its name or overlaps its dates.",
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: nonNullInt},
+					"name":  termArgs["name"],
+					"start": termArgs["start"],
+					"end":   termArgs["end"],
+				},
+				Resolve: h.updateTerm,
+			},
+			"deleteTerm": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Returns whether a term was deleted. Fails with CONFLICT when anyone is enrolled in it.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: nonNullInt}},
+				Resolve:     h.deleteTerm,
+			},
+			"setGrade": &graphql.Field{
+				Type:        graphql.NewNonNull(gradeType),
+				Description: "Grade a student on a course's roster in a term with a letter from the grade scale. Only the course's professors may.",
+				Args: graphql.FieldConfigArgument{
+					"courseId": {Type: nonNullInt},
+					"personId": {Type: nonNullInt},
+					"termId":   termIDArg,
+					"letter":   {Type: nonNullString},
+				},
+				Resolve: h.setGrade,
+			},
+			"setGradeScale": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(gradeStepType))),
+				Description: "Replace the grade scale. Grades already given keep their points.",
+				Args:        graphql.FieldConfigArgument{"grades": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(gradeStepInput)))}},
+				Resolve:     h.setGradeScale,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// Convert store errors to graphql errors with a matching code.
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return notFound(err.Error())
+	case errors.Is(err, store.ErrInvalid):
+		return badInput(err.Error())
+	case errors.Is(err, store.ErrConflict):
+		return conflictError(err.Error())
+	}
+	return err
+}
+
+// Read the limit and offset arguments, which like the REST query params
+// must be positive and non-negative when given.
+func pageArgs(args map[string]interface{}) (store.Page, error) {
+	var page store.Page
+	if limit, ok := args["limit"].(int); ok {
+		if limit < 1 {
+			return page, badInput("limit must be a positive integer")
+		}
+		page.Limit = limit
+	}
+	if offset, ok := args["offset"].(int); ok {
+		if offset < 0 {
+			return page, badInput("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
+
+func (h *RequestHandler) resolveCourses(p graphql.ResolveParams) (interface{}, error) {
+	page, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	courses, err := h.store().ListCourses(p.Context, page)
+	return courses, storeError(err)
+}
+
+func (h *RequestHandler) resolveCourse(p graphql.ResolveParams) (interface{}, error) {
+	course, err := h.store().GetCourse(p.Context, uint(p.Args["id"].(int)))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return course, nil
+}
+
+func (h *RequestHandler) resolvePeople(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, staffRoles...); err != nil {
+		return nil, err
+	}
+	page, err := pageArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	var filter store.PersonFilter
+	filter.Name, _ = p.Args["name"].(string)
+	if age, ok := p.Args["age"].(int); ok {
+		filter.Age = &age
+	}
+
+	people, err := h.store().ListPeople(p.Context, filter, page)
+	return people, storeError(err)
+}
+
+func (h *RequestHandler) resolvePerson(p graphql.ResolveParams) (interface{}, error) {
+	principal, err := requireRole(p.Context, staffRoles...)
+	if principal == nil {
+		return nil, err
+	}
+	self := err != nil // not staff, so only their own record
+	if self && (!principal.HasRole(auth.RoleStudent) || principal.PersonID == 0) {
+		return nil, err
+	}
+
+	person, err := h.store().GetPerson(p.Context, p.Args["name"].(string))
+	if self && (errors.Is(err, store.ErrNotFound) || err == nil && person.ID != principal.PersonID) {
+		return nil, gqlError{code: "FORBIDDEN", message: "Forbidden: students may only access their own record"}
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return person, nil
+}
+
+// Read the optional capacity or credits argument named name.
+func countFromArgs(args map[string]interface{}, name string) (*uint, error) {
+	value, ok := args[name].(int)
+	if !ok {
+		return nil, nil
+	}
+	if value < 1 {
+		return nil, badInput("Course " + name + " must be at least 1")
+	}
+	count := uint(value)
+	return &count, nil
+}
+
+func (h *RequestHandler) createCourse(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	capacity, err := countFromArgs(p.Args, "capacity")
+	if err != nil {
+		return nil, err
+	}
+	credits, err := countFromArgs(p.Args, "credits")
+	if err != nil {
+		return nil, err
+	}
+	requiresInstructor, _ := p.Args["requiresInstructor"].(bool)
+	course, err := h.store().CreateCourse(p.Context, p.Args["name"].(string), capacity, credits, requiresInstructor)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return course, nil
+}
+
+func (h *RequestHandler) updateCourse(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	capacity, err := countFromArgs(p.Args, "capacity")
+	if err != nil {
+		return nil, err
+	}
+	credits, err := countFromArgs(p.Args, "credits")
+	if err != nil {
+		return nil, err
+	}
+	requiresInstructor, _ := p.Args["requiresInstructor"].(bool)
+	course, err := h.store().UpdateCourse(p.Context, uint(p.Args["id"].(int)), p.Args["name"].(string), capacity, credits, requiresInstructor)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return course, nil
+}
+
+func (h *RequestHandler) deleteCourse(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	err := h.store().DeleteCourse(p.Context, uint(p.Args["id"].(int)))
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Read a PersonInput argument.
+func personFromInput(value interface{}) (store.PersonInput, error) {
+	input := value.(map[string]interface{})
+	person := store.PersonInput{
+		FirstName: input["firstName"].(string),
+		LastName:  input["lastName"].(string),
+		Type:      input["type"].(string),
+	}
+	age := input["age"].(int)
+	if age <= 0 {
+		return person, badInput("Missing required fields")
+	}
+	person.Age = uint(age)
+
+	courses, _ := input["courses"].([]interface{})
+	for _, id := range courses {
+		if id.(int) < 1 {
+			return person, badInput("Course ID does not exist: " + strconv.Itoa(id.(int)))
+		}
+		person.Courses = append(person.Courses, uint(id.(int)))
+	}
+	roles, _ := input["roles"].([]interface{})
+	for _, role := range roles {
+		person.Roles = append(person.Roles, role.(string))
+	}
+	return person, nil
+}
+
+func (h *RequestHandler) createPerson(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	input, err := personFromInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	if input.OverridePrerequisites, err = overridePrerequisitesArg(p); err != nil {
+		return nil, err
+	}
+	if input.Term, err = termArg(p.Args); err != nil {
+		return nil, err
+	}
+	person, _, err := h.store().CreatePerson(p.Context, input)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return person, nil
+}
+
+func (h *RequestHandler) updatePerson(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	input, err := personFromInput(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	if input.OverridePrerequisites, err = overridePrerequisitesArg(p); err != nil {
+		return nil, err
+	}
+	if input.Term, err = termArg(p.Args); err != nil {
+		return nil, err
+	}
+	person, _, err := h.store().UpdatePerson(p.Context, p.Args["name"].(string), input)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return person, nil
+}
+
+func (h *RequestHandler) deletePerson(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	if err := h.store().DeletePerson(p.Context, p.Args["name"].(string)); err != nil {
+		return nil, storeError(err)
+	}
+	return true, nil
+}
+
+// Read the courseId, personId and termId arguments and check the caller may
+// manage the course's roster in the term.
+func (h *RequestHandler) rosterArgs(p graphql.ResolveParams) (uint, uint, uint, error) {
+	courseID, personID := uint(p.Args["courseId"].(int)), uint(p.Args["personId"].(int))
+	termID, err := termArg(p.Args)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return courseID, personID, termID, h.requireTeacher(p.Context, courseID, termID)
+}
+
+func (h *RequestHandler) enroll(p graphql.ResolveParams) (interface{}, error) {
+	courseID, personID, termID, err := h.rosterArgs(p)
+	if err != nil {
+		return nil, err
+	}
+	override, err := overridePrerequisitesArg(p)
+	if err != nil {
+		return nil, err
+	}
+	role, _ := p.Args["role"].(string)
+	opts := store.EnrollOptions{Term: termID, Role: role, OverridePrerequisites: override}
+	enrollment, err := h.store().Enroll(p.Context, courseID, personID, opts)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return enrollment, nil
+}
+
+// Read the overridePrerequisites argument, which only admins may set.
+func overridePrerequisitesArg(p graphql.ResolveParams) (bool, error) {
+	override, _ := p.Args["overridePrerequisites"].(bool)
+	if override {
+		if _, err := requireRole(p.Context, auth.RoleAdmin); err != nil {
+			return false, err
+		}
+	}
+	return override, nil
+}
+
+func (h *RequestHandler) unenroll(p graphql.ResolveParams) (interface{}, error) {
+	courseID, personID, termID, err := h.rosterArgs(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.store().Unenroll(p.Context, courseID, personID, termID); err != nil {
+		return nil, storeError(err)
+	}
+	return true, nil
+}
+
+func (h *RequestHandler) addPrerequisite(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	courseID, prerequisiteID := uint(p.Args["courseId"].(int)), uint(p.Args["prerequisiteId"].(int))
+	if err := h.store().AddPrerequisite(p.Context, courseID, prerequisiteID); err != nil {
+		return nil, storeError(err)
+	}
+	course, err := h.store().GetCourse(p.Context, courseID)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return course, nil
+}
+
+func (h *RequestHandler) removePrerequisite(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	courseID, prerequisiteID := uint(p.Args["courseId"].(int)), uint(p.Args["prerequisiteId"].(int))
+	if err := h.store().RemovePrerequisite(p.Context, courseID, prerequisiteID); err != nil {
+		return nil, storeError(err)
+	}
+	return true, nil
+}
+
+func (h *RequestHandler) addMeeting(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	location, _ := p.Args["location"].(string)
+	meeting, err := h.store().AddMeeting(p.Context, uint(p.Args["courseId"].(int)), store.Meeting{
+		Day:      p.Args["day"].(string),
+		Start:    p.Args["start"].(string),
+		End:      p.Args["end"].(string),
+		Location: location,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return meeting, nil
+}
+
+func (h *RequestHandler) removeMeeting(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	courseID, meetingID := uint(p.Args["courseId"].(int)), uint(p.Args["meetingId"].(int))
+	if err := h.store().RemoveMeeting(p.Context, courseID, meetingID); err != nil {
+		return nil, storeError(err)
+	}
+	return true, nil
+}
+
+func (h *RequestHandler) resolveTerms(p graphql.ResolveParams) (interface{}, error) {
+	terms, err := h.store().ListTerms(p.Context)
+	return terms, storeError(err)
+}
+
+func (h *RequestHandler) resolveTerm(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["id"].(int)
+	if ok && id < 1 {
+		return nil, badInput("id must be a positive integer")
+	}
+	term, err := h.store().Term(p.Context, uint(id))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// Read the name, start and end arguments of a term.
+func termFromArgs(args map[string]interface{}) store.Term {
+	return store.Term{Name: args["name"].(string), Start: args["start"].(string), End: args["end"].(string)}
+}
+
+func (h *RequestHandler) createTerm(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	term, err := h.store().CreateTerm(p.Context, termFromArgs(p.Args))
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return term, nil
+}
+
+func (h *RequestHandler) updateTerm(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	term, err := h.store().UpdateTerm(p.Context, uint(p.Args["id"].(int)), termFromArgs(p.Args))
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return term, nil
+}
+
+func (h *RequestHandler) deleteTerm(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	err := h.store().DeleteTerm(p.Context, uint(p.Args["id"].(int)))
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, storeError(err)
+	}
+	return true, nil
+}
+
+// body of a graphql POST, or the query params of a GET
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Report whether the operation that will run is a mutation. Queries that
+// don't parse report false and fail in graphql.Do instead.
+func isMutation(query, operationName string) bool {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+	for _, definition := range document.Definitions {
+		op, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || op.Name != nil && op.Name.Value == operationName {
+			return op.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
+
+// GraphQL returns the /graphql handler. It accepts queries as POST json
+// bodies or GET query params and mutations only by POST. Every request gets
+// its own batch loaders, so nested fields are fetched with one query per
+// level rather than one per parent.
+func (h *RequestHandler) GraphQL() (http.HandlerFunc, error) {
+	schema, err := h.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if r.Method == http.MethodGet {
+			query := r.URL.Query()
+			req.Query = query.Get("query")
+			req.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					http.Error(w, "Error decoding variables: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Error decoding request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.Query == "" {
+			http.Error(w, "query is required", http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodGet && isMutation(req.Query, req.OperationName) {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Mutations must be sent with POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        withLoaders(r.Context(), h.newLoaders()),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}, nil
+}
+
+func (h *RequestHandler) resolveGradeScale(p graphql.ResolveParams) (interface{}, error) {
+	scale, err := h.store().GradeScale(p.Context)
+	return scale, storeError(err)
+}
+
+// Only professors teaching the course in the term may grade it, like the
+// grader policy of the grade route.
+func (h *RequestHandler) setGrade(p graphql.ResolveParams) (interface{}, error) {
+	principal, err := requireRole(p.Context, auth.RoleProfessor)
+	if err != nil {
+		return nil, err
+	}
+	courseID, personID := uint(p.Args["courseId"].(int)), uint(p.Args["personId"].(int))
+	termID, err := termArg(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	teaches := false
+	if principal.PersonID != 0 {
+		if teaches, err = h.store().Teaches(p.Context, principal.PersonID, courseID, termID); err != nil {
+			return nil, err
+		}
+	}
+	if !teaches {
+		return nil, gqlError{code: "FORBIDDEN", message: "Forbidden: professors may only manage courses they teach"}
+	}
+
+	grade, err := h.store().SetGrade(p.Context, courseID, personID, termID, p.Args["letter"].(string))
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return grade, nil
+}
+
+func (h *RequestHandler) setGradeScale(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireRole(p.Context, registrarRoles...); err != nil {
+		return nil, err
+	}
+	var scale []store.GradeStep
+	for _, value := range p.Args["grades"].([]interface{}) {
+		step := value.(map[string]interface{})
+		scale = append(scale, store.GradeStep{Letter: step["letter"].(string), Points: step["points"].(float64)})
+	}
+	scale, err := h.store().SetGradeScale(p.Context, scale)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return scale, nil
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+)
+
+type graphQLResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string            `json:"message"`
+		Extensions map[string]string `json:"extensions"`
+	} `json:"errors"`
+}
+
+// Post query to the graphql handler as principal.
+func postGraphQL(t *testing.T, handler *RequestHandler, principal *auth.Principal, query string, variables map[string]interface{}) graphQLResult {
+	serve, err := handler.GraphQL()
+	assert.NoError(t, err)
+
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	assert.NoError(t, err)
+	req, err := http.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	assert.NoError(t, err)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+
+	rr := httptest.NewRecorder()
+	serve(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var result graphQLResult
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+	return result
+}
+
+var (
+	admin     = &auth.Principal{Subject: "admin", Roles: []string{auth.RoleAdmin}}
+	registrar = &auth.Principal{Subject: "registrar", Roles: []string{auth.RoleRegistrar}}
+	student   = &auth.Principal{Subject: "larry", Roles: []string{auth.RoleStudent}, PersonID: 3}
+	professor = &auth.Principal{Subject: "steve", Roles: []string{auth.RoleProfessor}, PersonID: 1}
+)
+
+// TestGraphQLNestedBatching tests that people, their courses and the
+// courses' rosters are fetched with one query per level.
+func TestGraphQLNestedBatching(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE age = \\$1 ORDER BY id LIMIT \\$2$").
+		WithArgs(51, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).
+			AddRow(3, "Larry", "Page", "student", 51).
+			AddRow(4, "Sergey", "Brin", "student", 51))
+	// the current term is looked up once for every level
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT pc.person_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor FROM person_course pc JOIN course c ON c.id = pc.course_id "+
+		"WHERE pc.person_id IN \\(\\$1, \\$2\\) AND pc.term_id = \\$3").
+		WithArgs(3, 4, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "id", "name", "capacity", "credits", "requires_instructor"}).
+			AddRow(3, 1, "Math", nil, nil, false).
+			AddRow(4, 1, "Math", nil, nil, false).
+			AddRow(4, 2, "Art", 20, nil, false))
+	mock.ExpectQuery("SELECT pc.course_id, p.id, p.first_name, p.last_name, p.type, p.age FROM person_course pc JOIN person p ON p.id = pc.person_id "+
+		"WHERE pc.course_id IN \\(\\$1, \\$2\\) AND pc.term_id = \\$3").
+		WithArgs(1, 2, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"course_id", "id", "first_name", "last_name", "type", "age"}).
+			AddRow(1, 1, "Steve", "Jobs", "professor", 56).
+			AddRow(1, 3, "Larry", "Page", "student", 51).
+			AddRow(1, 4, "Sergey", "Brin", "student", 51).
+			AddRow(2, 4, "Sergey", "Brin", "student", 51))
+
+	result := postGraphQL(t, handler, registrar, `query($age: Int) {
+		people(age: $age, limit: 10) { fullName courses { name roster { fullName } } }
+	}`, map[string]interface{}{"age": 51})
+
+	assert.Empty(t, result.Errors)
+	people := result.Data["people"].([]interface{})
+	assert.Len(t, people, 2)
+	sergey := people[1].(map[string]interface{})
+	assert.Equal(t, "Sergey Brin", sergey["fullName"])
+	courses := sergey["courses"].([]interface{})
+	assert.Len(t, courses, 2)
+	assert.Equal(t, "Art", courses[1].(map[string]interface{})["name"])
+	assert.Len(t, courses[0].(map[string]interface{})["roster"], 3)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLAuthorization tests that fields apply the REST routes' roles.
+func TestGraphQLAuthorization(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	// students can't list people
+	result := postGraphQL(t, handler, student, `{ people { id } }`, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "FORBIDDEN", result.Errors[0].Extensions["code"])
+
+	// but may read their own record and courses, not the rosters
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
+		WithArgs("Larry Page").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(3, "Larry", "Page", "student", 51))
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT pc.person_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor FROM person_course pc").WithArgs(3, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "id", "name", "capacity", "credits", "requires_instructor"}).AddRow(3, 1, "Math", nil, nil, false))
+	result = postGraphQL(t, handler, student, `{ person(name: "Larry Page") { id type courses { name roster { id } } } }`, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "Forbidden: requires one of the roles: admin, registrar, professor", result.Errors[0].Message)
+	assert.Equal(t, "student", result.Data["person"].(map[string]interface{})["type"])
+
+	// and not anyone else's
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE").WithArgs("Steve Jobs").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(1, "Steve", "Jobs", "professor", 56))
+	result = postGraphQL(t, handler, student, `{ person(name: "Steve Jobs") { id } }`, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "Forbidden: students may only access their own record", result.Errors[0].Message)
+	assert.Nil(t, result.Data["person"])
+
+	// students can't create courses
+	result = postGraphQL(t, handler, student, `mutation { createCourse(name: "Art") { id } }`, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "FORBIDDEN", result.Errors[0].Extensions["code"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLCreatePerson tests creating a person with courses in one
+// transaction and rolling back when a course is missing.
+func TestGraphQLCreatePerson(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	const create = `mutation($input: PersonInput!) { createPerson(input: $input) { id fullName courses { name } } }`
+	input := map[string]interface{}{"firstName": "Ada", "lastName": "Lovelace", "type": "student", "age": 36, "courses": []int{2}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO person").WithArgs("Ada", "Lovelace", "student", 36).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectEvent(mock, "person.created")
+	storetest.ExpectLockCourse(mock, 2, nil)
+	expectStanding(mock, 6, 2, "", 0)
+	expectMissingPrerequisites(mock, 2, 6)
+	storetest.ExpectNoClashes(mock, 2, 6)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(6, 2, storetest.CurrentTerm.ID, "student").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT pc.person_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor FROM person_course pc").WithArgs(6, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "id", "name", "capacity", "credits", "requires_instructor"}).AddRow(6, 2, "Art", nil, nil, false))
+
+	result := postGraphQL(t, handler, registrar, create, map[string]interface{}{"input": input})
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"id": float64(6), "fullName": "Ada Lovelace",
+		"courses": []interface{}{map[string]interface{}{"name": "Art"}},
+	}, result.Data["createPerson"])
+
+	input["courses"] = []int{9}
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO person").WithArgs("Ada", "Lovelace", "student", 36).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectEvent(mock, "person.created")
+	storetest.ExpectLockCourse(mock, 9, false)
+	mock.ExpectRollback()
+
+	result = postGraphQL(t, handler, registrar, create, map[string]interface{}{"input": input})
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "Course ID does not exist: 9", result.Errors[0].Message)
+	assert.Equal(t, "BAD_USER_INPUT", result.Errors[0].Extensions["code"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLEnroll tests professors managing the rosters of courses they
+// teach.
+func TestGraphQLEnroll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	// the person and course loaders run in either order
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc").WithArgs(1, 2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 1)
+	expectStanding(mock, 3, 2, "", 0)
+	expectMissingPrerequisites(mock, 2, 3)
+	storetest.ExpectNoClashes(mock, 2, 3)
+	expectSeats(mock, 2, 1, 0)
+	mock.ExpectExec("INSERT INTO waitlist \\(person_id, course_id, term_id\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs(3, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "waitlist.added")
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE id IN \\(\\$1\\)").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(3, "Larry", "Page", "student", 51))
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id IN \\(\\$1\\)").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(2, "Art", 1, nil, false))
+
+	result := postGraphQL(t, handler, professor, `mutation { enroll(courseId: 2, personId: 3) { status position person { fullName } course { name capacity } } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"status":   "waitlisted",
+		"position": float64(1),
+		"person":   map[string]interface{}{"fullName": "Larry Page"},
+		"course":   map[string]interface{}{"name": "Art", "capacity": float64(1)},
+	}, result.Data["enroll"])
+
+	// a TA takes no seat
+	mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc").WithArgs(1, 2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 1)
+	expectStanding(mock, 4, 2, "", 0)
+	storetest.ExpectNoClashes(mock, 2, 4)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(4, 2, storetest.CurrentTerm.ID, "ta").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+
+	result = postGraphQL(t, handler, professor, `mutation { enroll(courseId: 2, personId: 4, role: ta) { role status } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"role": "ta", "status": "enrolled"}, result.Data["enroll"])
+
+	mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc").WithArgs(1, 5, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	result = postGraphQL(t, handler, professor, `mutation { unenroll(courseId: 5, personId: 3) }`, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "Forbidden: professors may only manage courses they teach", result.Errors[0].Message)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLMeetings tests reading a course's schedule and a clashing
+// meeting failing with CONFLICT.
+func TestGraphQLMeetings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Programming", nil, nil, false))
+	mock.ExpectQuery("FROM course_meeting WHERE course_id IN \\(\\$1\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "course_id", "day", "start", "end", "location"}).
+			AddRow(1, 1, "monday", "09:00", "10:30", "Room 101"))
+
+	result := postGraphQL(t, handler, student, `{ course(id: 1) { meetings { day start end location } } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"meetings": []interface{}{
+		map[string]interface{}{"day": "monday", "start": "09:00", "end": "10:30", "location": "Room 101"},
+	}}, result.Data["course"])
+
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 3, 3)
+	mock.ExpectExec("LOCK TABLE course_meeting").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectMeetingClashes(mock, 3, "monday", "10:00", "11:00", sqlmock.NewRows([]string{"person_id", "id", "name", "day", "start", "end"}).
+		AddRow(4, 1, "Programming", "monday", "09:00", "10:30"))
+	mock.ExpectRollback()
+
+	result = postGraphQL(t, handler, registrar, `mutation { addMeeting(courseId: 3, day: monday, start: "10:00", end: "11:00") { id } }`, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "CONFLICT", result.Errors[0].Extensions["code"])
+	assert.Equal(t, "Course 3 can't meet monday 10:00-11:00, it clashes with the schedules of its people: "+
+		"person 4 has Programming (1) monday 09:00-10:30", result.Errors[0].Message)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLTerms tests reading terms, a past term's roster and refusing
+// overlapping terms with CONFLICT.
+func TestGraphQLTerms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	fall := store.Term{ID: 1, Name: "Fall 2025", Start: "2025-09-01", End: "2025-12-19"}
+	// the term and course fields resolve in either order
+	mock.MatchExpectationsInOrder(false)
+
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(9).WillReturnRows(storetest.TermRows())
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false))
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(1).WillReturnRows(storetest.TermRows(fall))
+	mock.ExpectQuery("FROM person_course pc JOIN person p ON p.id = pc.person_id WHERE pc.course_id IN \\(\\$1\\) AND pc.term_id = \\$2").
+		WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"course_id", "id", "first_name", "last_name", "type", "age"}).
+		AddRow(1, 3, "Larry", "Page", "student", 51))
+
+	result := postGraphQL(t, handler, registrar, `{
+		term { name start }
+		missing: term(id: 9) { name }
+		course(id: 1) { roster(termId: 1) { fullName } }
+	}`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"name": "Spring 2026", "start": "2026-01-12"}, result.Data["term"])
+	assert.Nil(t, result.Data["missing"])
+	assert.Equal(t, map[string]interface{}{"roster": []interface{}{map[string]interface{}{"fullName": "Larry Page"}}}, result.Data["course"])
+
+	// students can't create terms
+	const create = `mutation { createTerm(name: "Summer 2026", start: "2026-05-01", end: "2026-08-14") { id } }`
+	result = postGraphQL(t, handler, student, create, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "FORBIDDEN", result.Errors[0].Extensions["code"])
+
+	mock.ExpectBegin()
+	expectCheckTerm(mock, store.Term{Name: "Summer 2026", Start: "2026-05-01", End: "2026-08-14"}, storetest.CurrentTerm)
+	mock.ExpectRollback()
+	result = postGraphQL(t, handler, registrar, create, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "CONFLICT", result.Errors[0].Extensions["code"])
+	assert.Equal(t, "Term Summer 2026 overlaps Spring 2026 (2), which runs from 2026-01-12 to 2026-05-15", result.Errors[0].Message)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLGrades tests professors grading courses they teach and reading
+// a person's GPA.
+func TestGraphQLGrades(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	const grade = `mutation { setGrade(courseId: 1, personId: 3, letter: "a-") { letter points termId } }`
+
+	mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc").WithArgs(1, 1, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	mock.ExpectQuery("SELECT points::float8 FROM grade_scale WHERE letter = \\$1").WithArgs("A-").
+		WillReturnRows(sqlmock.NewRows([]string{"points"}).AddRow(3.7))
+	mock.ExpectQuery("UPDATE person_course SET grade = \\$1, grade_points = \\$2").WithArgs("A-", 3.7, 3, 1, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("student"))
+	storetest.ExpectEvent(mock, "grade.set")
+	mock.ExpectCommit()
+	result := postGraphQL(t, handler, professor, grade, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{"letter": "A-", "points": 3.7, "termId": float64(2)}, result.Data["setGrade"])
+
+	// not even registrars grade courses
+	result = postGraphQL(t, handler, registrar, grade, nil)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, "FORBIDDEN", result.Errors[0].Extensions["code"])
+
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
+		WithArgs("Larry Page").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(3, "Larry", "Page", "student", 51))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1\\)").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("GROUP BY ROLLUP").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(2, "Spring 2026", 4, 3.7).
+			AddRow(nil, nil, 4, 3.7))
+	result = postGraphQL(t, handler, student, `{ person(name: "Larry Page") { gpa { terms { term credits gpa } cumulative { termId credits gpa } } } }`, nil)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"terms":      []interface{}{map[string]interface{}{"term": "Spring 2026", "credits": float64(4), "gpa": 3.7}},
+		"cumulative": map[string]interface{}{"termId": nil, "credits": float64(4), "gpa": 3.7},
+	}, result.Data["person"].(map[string]interface{})["gpa"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGraphQLGet tests that GET runs queries but refuses mutations.
+func TestGraphQLGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	serve, err := (&RequestHandler{DB: db}).GraphQL()
+	assert.NoError(t, err)
+
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Math", nil, nil, false))
+	query := url.Values{"query": {"query($id: Int!) { course(id: $id) { name } }"}, "variables": {`{"id": 1}`}}
+	req, err := http.NewRequest("GET", "/graphql?"+query.Encode(), nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	serve(rr, req.WithContext(auth.WithPrincipal(req.Context(), student)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data": {"course": {"name": "Math"}}}`, rr.Body.String())
+
+	query = url.Values{"query": {`mutation { deleteCourse(id: 1) }`}}
+	req, err = http.NewRequest("GET", "/graphql?"+query.Encode(), nil)
+	assert.NoError(t, err)
+	rr = httptest.NewRecorder()
+	serve(rr, req.WithContext(auth.WithPrincipal(req.Context(), registrar)))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "POST", rr.Header().Get("Allow"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,163 @@
+// request scoped batch loading for the graphql resolvers
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// loader collects the keys requested while one level of a graphql query is
+// resolved and fetches them with a single query when the first result is
+// needed, so nested fields don't run a query per parent object. Results are
+// cached for the rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	cache   map[K]*loaded[V]
+	pending *batch[K]
+}
+
+type batch[K comparable] struct {
+	keys []K
+	once sync.Once
+}
+
+type loaded[V any] struct {
+	wait  func() // fetches the batch the key is in, once
+	value V
+	err   error
+}
+
+func newLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: map[K]*loaded[V]{}}
+}
+
+// Load queues key for the current batch and returns a thunk that waits for
+// it. Keys missing from the fetched map resolve to the zero value.
+func (l *loader[K, V]) Load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	entry, ok := l.cache[key]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &batch[K]{}
+		}
+		b := l.pending
+		b.keys = append(b.keys, key)
+		entry = &loaded[V]{wait: func() { b.once.Do(func() { l.dispatch(ctx, b) }) }}
+		l.cache[key] = entry
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		entry.wait()
+		return entry.value, entry.err
+	}
+}
+
+// Fetch every key of b and store the results.
+func (l *loader[K, V]) dispatch(ctx context.Context, b *batch[K]) {
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(ctx, b.keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range b.keys {
+		entry := l.cache[key]
+		entry.value, entry.err = values[key], err
+	}
+}
+
+// termLoader keeps a loader per term for fields that take a termId
+// argument, so each term's keys are fetched with their own query.
+type termLoader[V any] struct {
+	fetch func(ctx context.Context, termID uint, keys []uint) (map[uint]V, error)
+
+	mu      sync.Mutex
+	loaders map[uint]*loader[uint, V]
+}
+
+func newTermLoader[V any](fetch func(context.Context, uint, []uint) (map[uint]V, error)) *termLoader[V] {
+	return &termLoader[V]{fetch: fetch, loaders: map[uint]*loader[uint, V]{}}
+}
+
+// Load queues key for the current batch of the term with termID, which must
+// already be resolved, and returns a thunk that waits for it.
+func (l *termLoader[V]) Load(ctx context.Context, termID, key uint) func() (interface{}, error) {
+	l.mu.Lock()
+	byTerm, ok := l.loaders[termID]
+	if !ok {
+		byTerm = newLoader(func(ctx context.Context, keys []uint) (map[uint]V, error) {
+			return l.fetch(ctx, termID, keys)
+		})
+		l.loaders[termID] = byTerm
+	}
+	l.mu.Unlock()
+	return byTerm.Load(ctx, key)
+}
+
+// loaders are the batch loaders of one graphql request.
+type loaders struct {
+	coursesByPerson     *termLoader[[]store.Course]
+	rosterByCourse      *termLoader[[]store.Person]
+	enrollmentsByPerson *termLoader[[]store.Enrollment]
+	enrollmentsByCourse *termLoader[[]store.Enrollment]
+	prerequisites       *loader[uint, []store.Course]
+	meetings            *loader[uint, []store.Meeting]
+	courseByID          *loader[uint, *store.Course]
+	personByID          *loader[uint, *store.Person]
+
+	store *store.Store
+	mu    sync.Mutex
+	terms map[uint]uint // termId argument to the id it resolved to
+}
+
+func (h *RequestHandler) newLoaders() *loaders {
+	s := h.store()
+	return &loaders{
+		coursesByPerson:     newTermLoader(s.CoursesByPerson),
+		rosterByCourse:      newTermLoader(s.RosterByCourse),
+		enrollmentsByPerson: newTermLoader(s.EnrollmentsByPerson),
+		enrollmentsByCourse: newTermLoader(s.EnrollmentsByCourse),
+		prerequisites:       newLoader(s.PrerequisitesByCourse),
+		meetings:            newLoader(s.MeetingsByCourse),
+		courseByID:          newLoader(s.CoursesByID),
+		personByID:          newLoader(s.PeopleByID),
+		store:               s,
+		terms:               map[uint]uint{},
+	}
+}
+
+// Resolve a termId argument, 0 for the current term, to the id of the term.
+// Each is looked up once a request, so every field of a query sees the same
+// current term.
+func (l *loaders) term(ctx context.Context, id uint) (uint, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if resolved, ok := l.terms[id]; ok {
+		return resolved, nil
+	}
+	term, err := l.store.Term(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	l.terms[id] = term.ID
+	return term.ID, nil
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
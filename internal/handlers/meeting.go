@@ -0,0 +1,93 @@
+// all handlers for course meeting schedules (course_meeting)
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+func courseMeeting(meeting store.Meeting) CourseMeeting {
+	return CourseMeeting{
+		ID:       meeting.ID,
+		CourseID: meeting.CourseID,
+		Day:      meeting.Day,
+		Start:    meeting.Start,
+		End:      meeting.End,
+		Location: meeting.Location,
+	}
+}
+
+// Return a course's weekly meetings in schedule order.
+func (h *RequestHandler) GetCourseMeetings(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	meetings, err := h.store().Meetings(r.Context(), uint(courseID))
+	if err != nil {
+		storeFailed(w, err, "querying meetings")
+		return
+	}
+
+	schedule := make([]CourseMeeting, len(meetings))
+	for i, meeting := range meetings {
+		schedule[i] = courseMeeting(meeting)
+	}
+	render(w, r, http.StatusOK, schedule)
+}
+
+// Add a weekly meeting to a course. Meetings that would clash with the
+// schedule of anyone on the course are refused with a 409.
+func (h *RequestHandler) AddMeeting(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var meeting CourseMeeting
+	if err := decode(r, &meeting); err != nil {
+		decodeError(w, err)
+		return
+	}
+
+	added, err := h.store().AddMeeting(r.Context(), uint(courseID), store.Meeting{
+		Day:      meeting.Day,
+		Start:    meeting.Start,
+		End:      meeting.End,
+		Location: meeting.Location,
+	})
+	if err != nil {
+		storeFailed(w, err, "adding meeting")
+		return
+	}
+
+	render(w, r, http.StatusCreated, courseMeeting(added))
+}
+
+// Remove a weekly meeting from a course.
+func (h *RequestHandler) RemoveMeeting(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	meetingID, err := strconv.Atoi(chi.URLParam(r, "meetingID"))
+	if err != nil {
+		http.Error(w, "Invalid meeting ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store().RemoveMeeting(r.Context(), uint(courseID), uint(meetingID)); err != nil {
+		storeFailed(w, err, "removing meeting")
+		return
+	}
+
+	render(w, r, http.StatusOK, Message{Message: "Meeting removed successfully"})
+}
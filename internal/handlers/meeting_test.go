@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetCourseMeetings tests listing a course's schedule.
+func TestGetCourseMeetings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	expectCourseExists(mock, 1, true)
+	mock.ExpectQuery("SELECT id, course_id, day, .* FROM course_meeting WHERE course_id IN \\(\\$1\\) ORDER BY day, start_time, id").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "course_id", "day", "start", "end", "location"}).
+		AddRow(1, 1, "monday", "09:00", "10:30", "Room 101").
+		AddRow(2, 1, "wednesday", "09:00", "10:30", ""))
+
+	rr := httptest.NewRecorder()
+	handler.GetCourseMeetings(rr, routeRequest(t, "GET", "/api/course/1/meetings", nil, "id", "1"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[
+		{"id":1,"course_id":1,"day":"monday","start":"09:00","end":"10:30","location":"Room 101"},
+		{"id":2,"course_id":1,"day":"wednesday","start":"09:00","end":"10:30"}
+	]`, rr.Body.String())
+
+	expectCourseExists(mock, 9, false)
+	rr = httptest.NewRecorder()
+	handler.GetCourseMeetings(rr, routeRequest(t, "GET", "/api/course/9/meetings", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Expect the people on a course to be checked for clashes with a new
+// meeting, finding a row of person id, course id and name, day, start and
+// end for each.
+func expectMeetingClashes(mock sqlmock.Sqlmock, courseID int, day, start, end string, clashes *sqlmock.Rows) {
+	mock.ExpectQuery("WITH courses \\(person_id, course_id, term_id\\) AS").WithArgs(courseID, day, start, end).WillReturnRows(clashes)
+}
+
+// TestAddMeeting tests adding a meeting and refusing ones that clash with
+// the schedules of the people on the course.
+func TestAddMeeting(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	columns := []string{"person_id", "id", "name", "day", "start", "end"}
+
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 3, 3)
+	mock.ExpectExec("LOCK TABLE course_meeting IN SHARE ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectMeetingClashes(mock, 3, "tuesday", "09:00", "10:00", sqlmock.NewRows(columns))
+	mock.ExpectQuery("INSERT INTO course_meeting \\(course_id, day, start_time, end_time, location\\) VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5\\) RETURNING id").
+		WithArgs(3, "tuesday", "09:00", "10:00", "Studio").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectEvent(mock, "meeting.added")
+	mock.ExpectCommit()
+
+	// times are normalized to HH:MM
+	rr := httptest.NewRecorder()
+	handler.AddMeeting(rr, routeRequest(t, "POST", "/api/course/3/meetings", CourseMeeting{Day: "tuesday", Start: "9:00", End: "10:00", Location: "Studio"}, "id", "3"))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.JSONEq(t, `{"id":6,"course_id":3,"day":"tuesday","start":"09:00","end":"10:00","location":"Studio"}`, rr.Body.String())
+
+	mock.ExpectBegin()
+	storetest.ExpectLockCourse(mock, 3, 3)
+	mock.ExpectExec("LOCK TABLE course_meeting").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectMeetingClashes(mock, 3, "monday", "10:00", "11:00", sqlmock.NewRows(columns).
+		AddRow(4, 1, "Programming", "monday", "09:00", "10:30").
+		AddRow(5, 1, "Programming", "monday", "09:00", "10:30"))
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.AddMeeting(rr, routeRequest(t, "POST", "/api/course/3/meetings", CourseMeeting{Day: "monday", Start: "10:00", End: "11:00"}, "id", "3"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Course 3 can't meet monday 10:00-11:00, it clashes with the schedules of its people: "+
+		"person 4 has Programming (1) monday 09:00-10:30; person 5 has Programming (1) monday 09:00-10:30\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.AddMeeting(rr, routeRequest(t, "POST", "/api/course/3/meetings", CourseMeeting{Day: "funday", Start: "10:00", End: "11:00"}, "id", "3"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "day must be one of monday, tuesday, wednesday, thursday, friday, saturday, sunday\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.AddMeeting(rr, routeRequest(t, "POST", "/api/course/3/meetings", CourseMeeting{Day: "monday", Start: "11:00", End: "10:00"}, "id", "3"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "A meeting must start before it ends\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRemoveMeeting tests removing a meeting from a course's schedule.
+func TestRemoveMeeting(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM course_meeting WHERE id = \\$1 AND course_id = \\$2 RETURNING day").WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "start", "end", "location"}).AddRow("wednesday", "09:00", "10:30", ""))
+	storetest.ExpectEvent(mock, "meeting.removed")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.RemoveMeeting(rr, routeRequest(t, "DELETE", "/api/course/1/meetings/2", nil, "id", "1", "meetingID", "2"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM course_meeting").WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "start", "end", "location"}))
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.RemoveMeeting(rr, routeRequest(t, "DELETE", "/api/course/1/meetings/7", nil, "id", "1", "meetingID", "7"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Meeting 7 is not on the schedule of course 1\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAddToRosterClash tests that anyone whose schedule a course clashes
+// with is refused with the clashing courses and times, professors included.
+func TestAddToRosterClash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("professor"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 4, nil)
+	expectStanding(mock, 1, 4, "", 0)
+	storetest.ExpectClashes(mock, 4, 1, sqlmock.NewRows([]string{"day", "start", "end", "id", "name", "other_start", "other_end"}).
+		AddRow("monday", "10:00", "11:00", 1, "Programming", "09:00", "10:30").
+		AddRow("tuesday", "13:30", "14:00", 2, "Databases", "13:00", "14:30"))
+	mock.ExpectRollback()
+
+	rr := httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/4/roster", PersonCourse{PersonID: 1}, "id", "4"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Course 4 clashes with the schedule of person 1 in Spring 2026: "+
+		"monday 10:00-11:00 overlaps Programming (1) monday 09:00-10:30; "+
+		"tuesday 13:30-14:00 overlaps Databases (2) tuesday 13:00-14:30\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreatePersonClash tests that a person can't be created on two courses
+// that meet at the same time, and nothing is kept when they are.
+func TestCreatePersonClash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO person").WithArgs("Ada", "Lovelace", "student", 36).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(6))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectEvent(mock, "person.created")
+	storetest.ExpectLockCourse(mock, 1, nil)
+	storetest.ExpectLockCourse(mock, 4, nil)
+	expectStanding(mock, 6, 1, "", 0)
+	expectMissingPrerequisites(mock, 1, 6)
+	storetest.ExpectNoClashes(mock, 1, 6)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(6, 1, storetest.CurrentTerm.ID, "student").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	expectStanding(mock, 6, 4, "", 0)
+	expectMissingPrerequisites(mock, 4, 6)
+	storetest.ExpectClashes(mock, 4, 6, sqlmock.NewRows([]string{"day", "start", "end", "id", "name", "other_start", "other_end"}).
+		AddRow("monday", "10:00", "11:00", 1, "Programming", "09:00", "10:30"))
+	mock.ExpectRollback()
+
+	body, err := json.Marshal(CompletePerson{FirstName: "Ada", LastName: "Lovelace", Type: "student", Age: 36, Courses: []uint{1, 4}})
+	assert.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/person", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.CreatePerson(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Course 4 clashes with the schedule of person 6 in Spring 2026: monday 10:00-11:00 overlaps Programming (1) monday 09:00-10:30\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
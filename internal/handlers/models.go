@@ -1,32 +1,220 @@
 package handlers
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/ratelimit"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/stream"
+)
 
 type Course struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID                 uint   `json:"id" xml:"id"`
+	Name               string `json:"name" xml:"name"`
+	Capacity           *uint  `json:"capacity,omitempty" xml:"capacity,omitempty"`                       //most students enrolled, unlimited when empty
+	Credits            *uint  `json:"credits,omitempty" xml:"credits,omitempty"`                         //weight of its grades in a GPA, 1 when empty
+	RequiresInstructor bool   `json:"requires_instructor,omitempty" xml:"requires_instructor,omitempty"` //no students in a term until someone instructs it
 }
 
 type Person struct {
-	ID        uint   `json: "id"`
-	FirstName string `json: "first_name"`
-	LastName  string `json: "last_name"`
-	Type      string `json: "type"` //only 'student' or 'professor'
-	Age       uint   `json: "age"`
+	ID        uint   `json:"id" xml:"id"`
+	FirstName string `json:"first_name" xml:"first_name"`
+	LastName  string `json:"last_name" xml:"last_name"`
+	Type      string `json:"type" xml:"type"` //only 'student' or 'professor'
+	Age       uint   `json:"age" xml:"age"`
 }
 
 type CompletePerson struct {
-	ID        uint   `json: "id"`
-	FirstName string `json: "first_name"`
-	LastName  string `json: "last_name"`
-	Type      string `json: "type"` //only 'student' or 'professor'
-	Age       uint   `json: "age"`
-	Courses   []uint `json: "courses"`
+	ID         uint     `json:"id" xml:"id"`
+	FirstName  string   `json:"first_name" xml:"first_name"`
+	LastName   string   `json:"last_name" xml:"last_name"`
+	Type       string   `json:"type" xml:"type"` //only 'student' or 'professor'
+	Age        uint     `json:"age" xml:"age"`
+	Courses    []uint   `json:"courses" xml:"courses>course"`
+	Roles      []string `json:"roles,omitempty" xml:"roles>role,omitempty"`             //role in each of courses, the default for type when empty
+	Waitlisted []uint   `json:"waitlisted,omitempty" xml:"waitlisted>course,omitempty"` //courses they're waiting for a seat in
+	TermID     uint     `json:"term_id,omitempty" xml:"term_id,omitempty"`              //term of courses and waitlisted, set by the term query param
+}
+
+// someone on a course's roster and their role in it
+type RosterMember struct {
+	ID        uint   `json:"id" xml:"id"`
+	FirstName string `json:"first_name" xml:"first_name"`
+	LastName  string `json:"last_name" xml:"last_name"`
+	Type      string `json:"type" xml:"type"`
+	Age       uint   `json:"age" xml:"age"`
+	Role      string `json:"role" xml:"role"` //instructor, student or ta
 }
 
 type PersonCourse struct {
-	PersonID uint `json:"person_id"`
-	CourseID uint `json:"person_id"`
+	PersonID uint   `json:"person_id" xml:"person_id"`
+	CourseID uint   `json:"course_id" xml:"course_id"`
+	TermID   uint   `json:"term_id" xml:"term_id"`
+	Role     string `json:"role,omitempty" xml:"role,omitempty"`         //instructor, student or ta, the default for the person's type when empty
+	Status   string `json:"status,omitempty" xml:"status,omitempty"`     //enrolled or waitlisted
+	Position int    `json:"position,omitempty" xml:"position,omitempty"` //place on the waitlist, from 1
+}
+
+// a course that must be completed before course_id can be taken
+type CoursePrerequisite struct {
+	CourseID       uint `json:"course_id" xml:"course_id"`
+	PrerequisiteID uint `json:"prerequisite_id" xml:"prerequisite_id"`
+}
+
+// a weekly meeting of a course
+type CourseMeeting struct {
+	ID       uint   `json:"id" xml:"id"`
+	CourseID uint   `json:"course_id" xml:"course_id"`
+	Day      string `json:"day" xml:"day"`     //monday to sunday
+	Start    string `json:"start" xml:"start"` //HH:MM
+	End      string `json:"end" xml:"end"`     //HH:MM, after start
+	Location string `json:"location,omitempty" xml:"location,omitempty"`
+}
+
+// an academic term, which every enrollment belongs to
+type Term struct {
+	ID    uint   `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Start string `json:"start" xml:"start"` //YYYY-MM-DD
+	End   string `json:"end" xml:"end"`     //YYYY-MM-DD, on or after start
+}
+
+// a letter on the grade scale and the points it's worth
+type GradeStep struct {
+	Letter string  `json:"letter" xml:"letter"`
+	Points float64 `json:"points" xml:"points"`
+}
+
+// a student's grade for a course in a term, the request body only needs letter
+type CourseGrade struct {
+	PersonID uint    `json:"person_id" xml:"person_id"`
+	CourseID uint    `json:"course_id" xml:"course_id"`
+	TermID   uint    `json:"term_id" xml:"term_id"`
+	Letter   string  `json:"letter" xml:"letter"`
+	Points   float64 `json:"points" xml:"points"` //from the grade scale when graded
+}
+
+// a person's GPA over their graded courses in one term, or every term
+type TermGPA struct {
+	TermID  uint     `json:"term_id,omitempty" xml:"term_id,omitempty"`
+	Term    string   `json:"term,omitempty" xml:"term,omitempty"`
+	Credits uint     `json:"credits" xml:"credits"`   //courses without credits count 1
+	GPA     *float64 `json:"gpa" xml:"gpa,omitempty"` //null without graded courses
+}
+
+type PersonGPA struct {
+	PersonID   uint      `json:"person_id" xml:"person_id"`
+	Terms      []TermGPA `json:"terms" xml:"terms>term"`
+	Cumulative TermGPA   `json:"cumulative" xml:"cumulative"`
+}
+
+type TranscriptCourse struct {
+	CourseID uint     `json:"course_id"`
+	Name     string   `json:"name"`
+	Credits  uint     `json:"credits"`         //courses without credits count 1
+	Grade    string   `json:"grade,omitempty"` //empty until graded
+	Points   *float64 `json:"points"`
+}
+
+// a term on a transcript, credits count every course and graded_credits
+// the ones in gpa
+type TranscriptTerm struct {
+	TermID        uint               `json:"term_id"`
+	Term          string             `json:"term"`
+	Start         string             `json:"start"`
+	End           string             `json:"end"`
+	Courses       []TranscriptCourse `json:"courses"`
+	Credits       uint               `json:"credits"`
+	GradedCredits uint               `json:"graded_credits"`
+	GPA           *float64           `json:"gpa"`
+}
+
+type TranscriptTotals struct {
+	Courses       int      `json:"courses"`
+	Credits       uint     `json:"credits"`
+	GradedCredits uint     `json:"graded_credits"`
+	GPA           *float64 `json:"gpa"`
+}
+
+// every course a student took by term, rendered as json, html or pdf
+type Transcript struct {
+	Person Person           `json:"person"`
+	Terms  []TranscriptTerm `json:"terms"`
+	Totals TranscriptTotals `json:"totals"`
+}
+
+// a person waiting for a seat in a course
+type WaitlistEntry struct {
+	Position  int       `json:"position" xml:"position"`
+	Person    Person    `json:"person" xml:"person"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+}
+
+// confirmation message returned by the delete endpoints
+type Message struct {
+	Message string `json:"message" xml:"message"`
+}
+
+// id of a newly created object
+type NewID struct {
+	ID uint `json:"id" xml:"id"`
+}
+
+type APIKey struct {
+	ID        uint       `json:"id" xml:"id"`
+	Label     string     `json:"label" xml:"label"`
+	Prefix    string     `json:"prefix" xml:"prefix"`
+	Admin     bool       `json:"admin" xml:"admin"`
+	Role      string     `json:"role,omitempty" xml:"role,omitempty"` //registrar or empty, professor and student only on older keys
+	CreatedAt time.Time  `json:"created_at" xml:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" xml:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" xml:"revoked_at,omitempty"`
+	Key       string     `json:"key,omitempty" xml:"key,omitempty"` //plaintext key, only returned on create and rotate
+}
+
+// request body for creating an api key
+type NewAPIKey struct {
+	Label     string     `json:"label" xml:"label"`
+	Admin     bool       `json:"admin" xml:"admin"`
+	Role      string     `json:"role,omitempty" xml:"role,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" xml:"expires_at,omitempty"`
+}
+
+// webhook subscription, managed by admins
+type Webhook struct {
+	ID        uint      `json:"id" xml:"id"`
+	URL       string    `json:"url" xml:"url"`
+	Events    []string  `json:"events" xml:"events>event"` //empty for every event type
+	Active    bool      `json:"active" xml:"active"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	Secret    string    `json:"secret,omitempty" xml:"secret,omitempty"` //signing secret, only returned on create
+}
+
+// request body for creating or updating a webhook subscription
+type NewWebhook struct {
+	URL    string   `json:"url" xml:"url"`
+	Events []string `json:"events" xml:"events>event"`
+	Active *bool    `json:"active,omitempty" xml:"active,omitempty"` //defaults to true
+}
+
+// one event sent, or being sent, to a webhook
+type WebhookDelivery struct {
+	ID             uint64     `json:"id" xml:"id"`
+	EventID        uint64     `json:"event_id" xml:"event_id"`
+	EventType      string     `json:"event_type" xml:"event_type"`
+	Status         string     `json:"status" xml:"status"` //pending, delivered or dead
+	Attempts       int        `json:"attempts" xml:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" xml:"next_attempt_at,omitempty"` //only while pending
+	LastStatusCode *int       `json:"last_status_code,omitempty" xml:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty" xml:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" xml:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" xml:"delivered_at,omitempty"`
 }
 
 // set table name
@@ -42,6 +230,53 @@ func (PersonCourse) TableName() string {
 	return "person_course"
 }
 
+func (Term) TableName() string {
+	return "term"
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (Webhook) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
 type RequestHandler struct {
-	DB *sql.DB
+	DB       *sql.DB
+	AdminKey string             // bootstrap admin api key, disabled when empty
+	JWT      *auth.JWTVerifier  // bearer token verification, disabled when nil
+	Limiter  *ratelimit.Limiter // per route group rate limits, disabled when nil
+	Events   *stream.Broker     // live change events, /api/events is unavailable when nil
+}
+
+// Return the store shared with the grpc server. Every change to courses,
+// people and rosters goes through it so it publishes an event.
+func (h *RequestHandler) store() *store.Store {
+	return store.New(h.DB)
+}
+
+// Write the response for an error from the store: 404 or 400 with its
+// message, or 500 saying what failed.
+func storeFailed(w http.ResponseWriter, err error, doing string) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, store.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Error "+doing+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Return the request's logger, which carries the request id and, once
+// authenticated, the principal.
+func (h *RequestHandler) logger(r *http.Request) *slog.Logger {
+	return logging.FromContext(r.Context())
 }
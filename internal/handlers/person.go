@@ -2,143 +2,161 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
 )
 
-// Return all Person objects from the database.
+// Return all Person objects from the database, with their courses in the
+// term named by the term query param or the current term.
 func (h *RequestHandler) GetAllPeople(w http.ResponseWriter, r *http.Request) {
 	var people []CompletePerson
 
-	//get query params
-	name := r.URL.Query().Get("name")
-	age := r.URL.Query().Get("age")
-
-	query := "SELECT id, first_name, last_name, type, age FROM person"
-	var args []interface{}
-	var conditions []string
-
-	if name != "" {
-		conditions = append(conditions, "first_name = $1 OR last_name = $1")
-		args = append(args, name)
+	term, ok := h.queryTerm(w, r)
+	if !ok {
+		return
+	}
+	filter, err := queryPersonFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := queryPage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if age != "" {
-		placeholder := "$1"
-		if name != "" {
-			placeholder = "$2"
-		}
-		conditions = append(conditions, "age = "+placeholder)
-		args = append(args, age)
+	//get person data
+	found, err := h.store().ListPeople(r.Context(), filter, page)
+	if err != nil {
+		storeFailed(w, err, "querying person data")
+		return
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if len(found) == 0 {
+		render(w, r, http.StatusOK, people)
+		return
 	}
 
-	//get person data
-	rows, err := h.DB.Query(query, args...)
+	//find courses for everyone at once
+	ids := make([]uint, len(found))
+	for i, person := range found {
+		ids[i] = person.ID
+	}
+	enrollments, err := h.store().EnrollmentsByPerson(r.Context(), term.ID, ids)
 	if err != nil {
-		http.Error(w, "Error querying person data: "+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "querying courses")
 		return
 	}
 
-	//insert person data into people slice
-	for rows.Next() {
-		var person CompletePerson
-		err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
-		if err != nil {
-			http.Error(w, "Error scanning person data: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+	for _, person := range found {
+		complete := completePerson(person, term.ID)
+		complete.Courses, complete.Roles, _ = splitEnrollments(enrollments[person.ID])
+		people = append(people, complete)
+	}
 
-		//find courses for each person
-		courseRows, err := h.DB.Query("SELECT course_id FROM person_course WHERE person_id = $1", person.ID)
+	render(w, r, http.StatusOK, people)
+}
+
+// Read the name and age query params shared by GetAllPeople and
+// ExportPeople.
+func queryPersonFilter(query url.Values) (store.PersonFilter, error) {
+	filter := store.PersonFilter{Name: query.Get("name")}
+	if age := query.Get("age"); age != "" {
+		n, err := strconv.Atoi(age)
 		if err != nil {
-			http.Error(w, "Error querying courses for person ID: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		for courseRows.Next() {
-			var courseID uint
-			err := courseRows.Scan(&courseID)
-			if err != nil {
-				http.Error(w, "Error scanning course data for person ID"+err.Error(), http.StatusInternalServerError)
-				return
-			}
-			person.Courses = append(person.Courses, courseID)
+			return filter, errors.New("age must be an integer")
 		}
-		people = append(people, person)
+		filter.Age = &n
 	}
+	return filter, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(people); err != nil {
-		http.Error(w, "Error encoding response to JSON: "+err.Error(), http.StatusInternalServerError)
-		return
+// Read the optional limit and offset query params, which must be positive
+// and non-negative when given.
+func queryPage(query url.Values) (store.Page, error) {
+	var page store.Page
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return page, errors.New("limit must be a positive integer")
+		}
+		page.Limit = n
+	}
+	if offset := query.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return page, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = n
 	}
+	return page, nil
 }
 
-// Return a given Person from the database.
+// Return a given Person from the database, with their courses in the term
+// named by the term query param or the current term.
 func (h *RequestHandler) GetPerson(w http.ResponseWriter, r *http.Request) {
-	var person CompletePerson
+	term, ok := h.queryTerm(w, r)
+	if !ok {
+		return
+	}
 
 	//get query params
 	fullName := chi.URLParam(r, "name")
 
-	query := "SELECT id, first_name, last_name, type, age FROM person WHERE first_name || ' ' || last_name = $1"
-	args := []interface{}{fullName}
-
 	// Get person data
-	row := h.DB.QueryRow(query, args...)
-	err := row.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
+	found, err := h.store().GetPerson(r.Context(), fullName)
 	if err != nil {
-		http.Error(w, "Person not found"+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "querying person")
 		return
 	}
+	person := completePerson(found, term.ID)
 
-	//find courses for each person
-	courseRows, err := h.DB.Query("SELECT course_id FROM person_course WHERE person_id = $1", person.ID)
+	//find their courses and the ones they're waiting for
+	ids := []uint{found.ID}
+	enrollments, err := h.store().EnrollmentsByPerson(r.Context(), term.ID, ids)
 	if err != nil {
-		http.Error(w, "Error querying courses: "+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "querying courses")
 		return
 	}
-	defer courseRows.Close()
-
-	for courseRows.Next() {
-		var courseID uint
-		err := courseRows.Scan(&courseID)
-		if err != nil {
-			http.Error(w, "Error scanning course ID"+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		person.Courses = append(person.Courses, courseID)
-	}
-
-	if err := courseRows.Err(); err != nil {
-		http.Error(w, "Error iterating over courses: "+err.Error(), http.StatusInternalServerError)
+	waitlist, err := h.store().WaitlistByPerson(r.Context(), term.ID, ids)
+	if err != nil {
+		storeFailed(w, err, "querying waitlist")
 		return
 	}
+	person.Courses, person.Roles, person.Waitlisted = splitEnrollments(append(enrollments[found.ID], waitlist[found.ID]...))
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(person); err != nil {
-		http.Error(w, "Error encoding response"+err.Error(), http.StatusInternalServerError)
-		return
+	render(w, r, http.StatusOK, person)
+}
+
+// Convert a store person to a response body for the term with termID,
+// without their courses.
+func completePerson(person store.Person, termID uint) CompletePerson {
+	return CompletePerson{
+		ID:        person.ID,
+		FirstName: person.FirstName,
+		LastName:  person.LastName,
+		Type:      person.Type,
+		Age:       person.Age,
+		TermID:    termID,
 	}
 }
 
-// Update an existing Person in the database.
+// Update an existing Person in the database, replacing their courses in the
+// term named by the term query param or the current term.
 func (h *RequestHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) {
 	// Get path param
 	fullName := chi.URLParam(r, "name")
 
-	// Parse the JSON request body
+	// Parse the request body
 	var updatedPerson CompletePerson
-	if err := json.NewDecoder(r.Body).Decode(&updatedPerson); err != nil {
-		http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+	if err := decode(r, &updatedPerson); err != nil {
+		decodeError(w, err)
 		return
 	}
 
@@ -148,76 +166,40 @@ func (h *RequestHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find the person ID based on the full name
-	var personID int
-	err := h.DB.QueryRow("SELECT id FROM person WHERE first_name || ' ' || last_name = $1", fullName).Scan(&personID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Person not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Error finding person: "+err.Error(), http.StatusInternalServerError)
-		}
+	override, ok := overridePrerequisites(w, r)
+	if !ok {
 		return
 	}
-
-	// Construct the SQL UPDATE query
-	query := `
-        UPDATE person
-        SET first_name = $1, last_name = $2, type = $3, age = $4
-        WHERE id = $5
-    `
-	args := []interface{}{updatedPerson.FirstName, updatedPerson.LastName, updatedPerson.Type, updatedPerson.Age, personID}
-
-	// Execute the UPDATE query
-	_, err = h.DB.Exec(query, args...)
-	if err != nil {
-		http.Error(w, "Error updating person: "+err.Error(), http.StatusInternalServerError)
+	// resolved up front so the response names the term even without courses
+	term, ok := h.queryTerm(w, r)
+	if !ok {
 		return
 	}
 
-	// Update courses for the person
-	_, err = h.DB.Exec("DELETE FROM person_course WHERE person_id = $1", personID)
+	// Update the person and replace their courses
+	input := personInput(updatedPerson)
+	input.OverridePrerequisites = override
+	input.Term = term.ID
+	person, enrollments, err := h.store().UpdatePerson(r.Context(), fullName, input)
 	if err != nil {
-		http.Error(w, "Error deleting old courses: "+err.Error(), http.StatusInternalServerError)
+		storeFailed(w, err, "updating person")
 		return
 	}
+	updatedPerson.ID, updatedPerson.TermID = person.ID, term.ID
+	updatedPerson.Courses, updatedPerson.Roles, updatedPerson.Waitlisted = splitEnrollments(enrollments)
 
-	for _, courseID := range updatedPerson.Courses {
-		// Check if the course_id exists in the course table
-		var exists bool
-		err := h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM course WHERE id = $1)", courseID).Scan(&exists)
-		if err != nil {
-			http.Error(w, "Error checking course existence: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		if !exists {
-			http.Error(w, "Course ID does not exist: "+strconv.FormatUint(uint64(courseID), 10), http.StatusBadRequest)
-			return
-		}
-
-		_, err = h.DB.Exec("INSERT INTO person_course (person_id, course_id) VALUES ($1, $2)", personID, courseID)
-		if err != nil {
-			http.Error(w, "Error inserting new courses: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-
-	// Return the updated Person object as a JSON response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(updatedPerson); err != nil {
-		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	// Return the updated Person object
+	render(w, r, http.StatusOK, updatedPerson)
 }
 
-// Create a new Person in the database.
+// Create a new Person in the database, enrolling them in the term named by
+// the term query param or the current term.
 func (h *RequestHandler) CreatePerson(w http.ResponseWriter, r *http.Request) {
 	var newPerson CompletePerson
 
-	// Parse the JSON request body
-	if err := json.NewDecoder(r.Body).Decode(&newPerson); err != nil {
-		http.Error(w, "Invalid request payload: "+err.Error(), http.StatusBadRequest)
+	// Parse the request body
+	if err := decode(r, &newPerson); err != nil {
+		decodeError(w, err)
 		return
 	}
 
@@ -227,50 +209,28 @@ func (h *RequestHandler) CreatePerson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Construct the SQL INSERT query
-	query := `
-        INSERT INTO person (first_name, last_name, type, age)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id
-    `
-	args := []interface{}{newPerson.FirstName, newPerson.LastName, newPerson.Type, newPerson.Age}
-
-	// Execute the INSERT query and fetch the new person's ID
-	var newPersonID uint
-	err := h.DB.QueryRow(query, args...).Scan(&newPersonID)
-	if err != nil {
-		http.Error(w, "Error creating person: "+err.Error(), http.StatusInternalServerError)
+	override, ok := overridePrerequisites(w, r)
+	if !ok {
 		return
 	}
 
-	// Update courses for the new person
-	for _, courseID := range newPerson.Courses {
-		// Check if the course_id exists in the course table
-		var exists bool
-		err := h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM course WHERE id = $1)", courseID).Scan(&exists)
-		if err != nil {
-			http.Error(w, "Error checking course existence: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		if !exists {
-			http.Error(w, "Course ID does not exist: "+strconv.FormatUint(uint64(courseID), 10), http.StatusBadRequest)
-			return
-		}
-
-		_, err = h.DB.Exec("INSERT INTO person_course (person_id, course_id) VALUES ($1, $2)", newPersonID, courseID)
-		if err != nil {
-			http.Error(w, "Error inserting new courses: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+	termID, ok := termParam(w, r)
+	if !ok {
+		return
 	}
 
-	// Return the new Person object's ID as a JSON response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(map[string]uint{"id": newPersonID}); err != nil {
-		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+	// Insert the person and their courses
+	input := personInput(newPerson)
+	input.OverridePrerequisites = override
+	input.Term = termID
+	person, _, err := h.store().CreatePerson(r.Context(), input)
+	if err != nil {
+		storeFailed(w, err, "creating person")
 		return
 	}
+
+	// Return the new Person object's ID
+	render(w, r, http.StatusCreated, NewID{ID: person.ID})
 }
 
 // Delete a given Person from the database based on name.
@@ -278,32 +238,39 @@ func (h *RequestHandler) DeletePerson(w http.ResponseWriter, r *http.Request) {
 	// Get path param
 	fullName := chi.URLParam(r, "name")
 
-	// Find the person ID based on the full name
-	var personID int
-	err := h.DB.QueryRow("SELECT id FROM person WHERE first_name || ' ' || last_name = $1", fullName).Scan(&personID)
-	if err != nil {
-		http.Error(w, "Error finding person: "+err.Error(), http.StatusInternalServerError)
+	// Delete the person and their enrollments
+	if err := h.store().DeletePerson(r.Context(), fullName); err != nil {
+		storeFailed(w, err, "deleting person")
 		return
 	}
 
-	// Delete associated records in the person_course table
-	_, err = h.DB.Exec("DELETE FROM person_course WHERE person_id = $1", personID)
-	if err != nil {
-		http.Error(w, "Error deleting associated courses: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	// Return a success message
+	render(w, r, http.StatusOK, Message{Message: "Person deleted successfully"})
+}
 
-	// Delete the person
-	_, err = h.DB.Exec("DELETE FROM person WHERE id = $1", personID)
-	if err != nil {
-		http.Error(w, "Error deleting person: "+err.Error(), http.StatusInternalServerError)
-		return
+// Split where a person stands on their courses into the courses they're
+// enrolled in with their role in each, and those they're waitlisted for.
+func splitEnrollments(enrollments []store.Enrollment) (courses []uint, roles []string, waitlisted []uint) {
+	courses = []uint{}
+	for _, enrollment := range enrollments {
+		if enrollment.Status == store.StatusWaitlisted {
+			waitlisted = append(waitlisted, enrollment.CourseID)
+		} else {
+			courses = append(courses, enrollment.CourseID)
+			roles = append(roles, enrollment.Role)
+		}
 	}
+	return courses, roles, waitlisted
+}
 
-	// Return a success message as a JSON response
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]string{"message": "Person deleted successfully"}); err != nil {
-		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
-		return
+// Convert a request body to the store's input.
+func personInput(person CompletePerson) store.PersonInput {
+	return store.PersonInput{
+		FirstName: person.FirstName,
+		LastName:  person.LastName,
+		Type:      person.Type,
+		Age:       person.Age,
+		Courses:   person.Courses,
+		Roles:     person.Roles,
 	}
 }
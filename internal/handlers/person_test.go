@@ -10,6 +10,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,17 +28,15 @@ func TestGetAllPeople(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).
 		AddRow(1, "John", "Doe", "student", 25).
 		AddRow(2, "Jane", "Smith", "professor", 30)
+	storetest.ExpectTerm(mock, 0)
 	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person").WillReturnRows(rows)
 
-	// Mock the database response for courses for each person
-	courseRows1 := sqlmock.NewRows([]string{"course_id"}).
-		AddRow(1).
-		AddRow(2)
-	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1").WithArgs(1).WillReturnRows(courseRows1)
-
-	courseRows2 := sqlmock.NewRows([]string{"course_id"}).
-		AddRow(3)
-	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1").WithArgs(2).WillReturnRows(courseRows2)
+	// Mock the database response for everyone's courses in the current term
+	courseRows := sqlmock.NewRows([]string{"person_id", "course_id", "role"}).
+		AddRow(1, 1, "student").
+		AddRow(1, 2, "ta").
+		AddRow(2, 3, "instructor")
+	storetest.ExpectPersonCourses(mock, courseRows, 1, 2)
 
 	// Create a new HTTP request
 	req, err := http.NewRequest("GET", "/people", nil)
@@ -68,6 +67,7 @@ func TestGetAllPeople(t *testing.T) {
 
 	assert.Len(t, people[1].Courses, 1)
 	assert.Equal(t, uint(3), people[1].Courses[0])
+	assert.Equal(t, storetest.CurrentTerm.ID, people[1].TermID)
 }
 
 func TestGetPerson(t *testing.T) {
@@ -84,13 +84,15 @@ func TestGetPerson(t *testing.T) {
 	// Mock the database response
 	row := sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).
 		AddRow(1, "John", "Doe", "student", 25)
+	storetest.ExpectTerm(mock, 0)
 	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
 		WithArgs("John Doe").WillReturnRows(row)
 
 	// Mock the database response for courses
-	courseRows := sqlmock.NewRows([]string{"course_id"}).
-		AddRow(1)
-	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1").WithArgs(1).WillReturnRows(courseRows)
+	courseRows := sqlmock.NewRows([]string{"person_id", "course_id", "role"}).
+		AddRow(1, 1, "student")
+	storetest.ExpectPersonCourses(mock, courseRows, 1)
+	storetest.ExpectPersonWaitlist(mock, sqlmock.NewRows([]string{"person_id", "course_id", "position"}).AddRow(1, 3, 2), 1)
 
 	req, err := http.NewRequest("GET", "/person/John Doe", nil)
 	assert.NoError(t, err)
@@ -107,6 +109,22 @@ func TestGetPerson(t *testing.T) {
 	err = json.NewDecoder(rr.Body).Decode(&person)
 	assert.NoError(t, err)
 	assert.Equal(t, "John", person.FirstName)
+	assert.Equal(t, []uint{1}, person.Courses)
+	assert.Equal(t, []uint{3}, person.Waitlisted)
+
+	// nobody by that name
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
+		WithArgs("Jane Doe").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}))
+
+	rctx.URLParams = chi.RouteParams{}
+	rctx.URLParams.Add("name", "Jane Doe")
+	rr = httptest.NewRecorder()
+	handler.GetPerson(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Person not found\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 func TestUpdatePerson(t *testing.T) {
 	//mock database connection
@@ -119,24 +137,39 @@ func TestUpdatePerson(t *testing.T) {
 	// Mock the database response for finding the person ID
 	row := sqlmock.NewRows([]string{"id"}).
 		AddRow(1)
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT id FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
 		WithArgs("John Doe").WillReturnRows(row)
 
 	// Mock the database response for updating the person
 	mock.ExpectExec("UPDATE person SET first_name = \\$1, last_name = \\$2, type = \\$3, age = \\$4 WHERE id = \\$5").
 		WithArgs("John", "Doe", "student", 25, 1).WillReturnResult(sqlmock.NewResult(1, 1))
-
-	// Mock the database response for deleting old courses
-	mock.ExpectExec("DELETE FROM person_course WHERE person_id = \\$1").
-		WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
-
-	// Mock the database response for checking course existence
-	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").
-		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-
-	// Mock the database response for inserting new courses
-	mock.ExpectExec("INSERT INTO person_course \\(person_id, course_id\\) VALUES \\(\\$1, \\$2\\)").
-		WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	storetest.ExpectLockTerm(mock, int(storetest.CurrentTerm.ID))
+	storetest.ExpectEvent(mock, "person.updated")
+
+	// Mock the database response for finding their current courses in the
+	// term
+	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1 AND term_id = \\$2 UNION ALL SELECT course_id FROM waitlist WHERE person_id = \\$1 AND term_id = \\$2").
+		WithArgs(1, storetest.CurrentTerm.ID).WillReturnRows(sqlmock.NewRows([]string{"course_id"}).AddRow(2))
+
+	storetest.ExpectLockCourse(mock, 1, 1)
+	storetest.ExpectLockCourse(mock, 2, nil)
+
+	// dropping course 2, which has no capacity
+	mock.ExpectExec("DELETE FROM person_course WHERE person_id = \\$1 AND course_id = \\$2 AND term_id = \\$3").
+		WithArgs(1, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.removed")
+
+	// joining course 1, which is full, waitlists them
+	expectStanding(mock, 1, 1, "", 0)
+	expectMissingPrerequisites(mock, 1, 1)
+	storetest.ExpectNoClashes(mock, 1, 1)
+	expectSeats(mock, 1, 1, 0)
+	mock.ExpectExec("INSERT INTO waitlist \\(person_id, course_id, term_id\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs(1, 1, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	storetest.ExpectEvent(mock, "waitlist.added")
+	mock.ExpectCommit()
 
 	person := CompletePerson{
 		FirstName: "John",
@@ -164,6 +197,28 @@ func TestUpdatePerson(t *testing.T) {
 	err = json.NewDecoder(rr.Body).Decode(&updatedPerson)
 	assert.NoError(t, err)
 	assert.Equal(t, "John", updatedPerson.FirstName)
+	assert.Equal(t, []uint{}, updatedPerson.Courses)
+	assert.Equal(t, []uint{1}, updatedPerson.Waitlisted)
+	assert.Equal(t, storetest.CurrentTerm.ID, updatedPerson.TermID)
+
+	// a professor instructing a course can't become a student
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
+		WithArgs("John Doe").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("UPDATE person SET .* WHERE id = \\$5 AND \\(\\$3 = 'professor' OR NOT EXISTS\\(").
+		WithArgs("John", "Doe", "student", 25, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	body, err = json.Marshal(person)
+	assert.NoError(t, err)
+	req, err = http.NewRequest("PUT", "/person/John Doe", bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	rr = httptest.NewRecorder()
+	handler.UpdatePerson(rr, req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Only professors can be instructors, person 1 instructs a course\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestCreatePerson(t *testing.T) {
@@ -174,8 +229,12 @@ func TestCreatePerson(t *testing.T) {
 	handler := &RequestHandler{DB: db}
 
 	// Mock the database response
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO person \\(first_name, last_name, type, age\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id").
 		WithArgs("John", "Doe", "student", 25).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectEvent(mock, "person.created")
+	mock.ExpectCommit()
 
 	person := CompletePerson{
 		FirstName: "John",
@@ -207,12 +266,30 @@ func TestDeletePerson(t *testing.T) {
 	handler := &RequestHandler{DB: db}
 
 	// Mock the database response
-	mock.ExpectQuery("SELECT id FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").
-		WithArgs("John Doe").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-	mock.ExpectExec("DELETE FROM person_course WHERE person_id = \\$1").
-		WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1 FOR NO KEY UPDATE").
+		WithArgs("John Doe").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).
+		AddRow(1, "John", "Doe", "student", 25))
+
+	// the courses they're on or waiting for are locked first, in id order
+	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1 UNION SELECT course_id FROM waitlist").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"course_id"}).AddRow(5).AddRow(3))
+	storetest.ExpectLockCourse(mock, 3, 2)
+	storetest.ExpectLockCourse(mock, 5, nil)
+	mock.ExpectQuery("DELETE FROM person_course WHERE person_id = \\$1 RETURNING course_id, term_id").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"course_id", "term_id"}).AddRow(3, storetest.CurrentTerm.ID))
 	mock.ExpectExec("DELETE FROM person WHERE id = \\$1").
 		WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	storetest.ExpectEvent(mock, "enrollment.removed")
+	storetest.ExpectEvent(mock, "person.deleted")
+
+	// their seat goes to the first student waiting
+	expectSeats(mock, 3, 1, 1)
+	mock.ExpectQuery("WITH promoted AS").WithArgs(3, storetest.CurrentTerm.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id"}).AddRow(4))
+	storetest.ExpectEvent(mock, "waitlist.removed")
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
 
 	req, err := http.NewRequest("DELETE", "/person/John Doe", nil)
 	assert.NoError(t, err)
@@ -229,4 +306,5 @@ func TestDeletePerson(t *testing.T) {
 	err = json.NewDecoder(rr.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.Equal(t, "Person deleted successfully", response["message"])
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
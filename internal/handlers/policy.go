@@ -0,0 +1,71 @@
+// authorization rules that need the database, used with authz.Require
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+)
+
+// TeachesCourse allows professors to act on the course named by the id URL
+// param when they are assigned to it in person_course in the term named by
+// the term query param, or the current term.
+func (h *RequestHandler) TeachesCourse(r *http.Request, principal *auth.Principal) (bool, string, error) {
+	const reason = "professors may only manage courses they teach"
+	if !principal.HasRole(auth.RoleProfessor) || principal.PersonID == 0 {
+		return false, reason, nil
+	}
+
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return false, reason, nil
+	}
+
+	var termID uint
+	if value := r.URL.Query().Get("term"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return false, reason, nil
+		}
+		termID = uint(id)
+	}
+
+	teaches, err := h.store().Teaches(r.Context(), principal.PersonID, uint(courseID), termID)
+	if err != nil {
+		return false, "", err
+	}
+	if !teaches {
+		return false, reason, nil
+	}
+	return true, "", nil
+}
+
+// IsSelf allows students to act on the person named by the name URL param,
+// or with the id URL param, when that person is them.
+func (h *RequestHandler) IsSelf(r *http.Request, principal *auth.Principal) (bool, string, error) {
+	const reason = "students may only access their own record"
+	if !principal.HasRole(auth.RoleStudent) || principal.PersonID == 0 {
+		return false, reason, nil
+	}
+
+	if id := chi.URLParam(r, "id"); id != "" {
+		if id != strconv.FormatUint(uint64(principal.PersonID), 10) {
+			return false, reason, nil
+		}
+		return true, "", nil
+	}
+
+	var self bool
+	err := h.DB.QueryRowContext(r.Context(),
+		"SELECT EXISTS(SELECT 1 FROM person WHERE id = $1 AND first_name || ' ' || last_name = $2)",
+		principal.PersonID, chi.URLParam(r, "name")).Scan(&self)
+	if err != nil {
+		return false, "", err
+	}
+	if !self {
+		return false, reason, nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build a request carrying a single chi URL param.
+func requestWithParam(t *testing.T, key, value string) *http.Request {
+	req, err := http.NewRequest("GET", "/", nil)
+	assert.NoError(t, err)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// TestTeachesCourse tests the professor roster rule.
+func TestTeachesCourse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	professor := &auth.Principal{Subject: "sjobs", Roles: []string{auth.RoleProfessor}, PersonID: 1}
+	req := requestWithParam(t, "id", "2")
+
+	mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc WHERE pc.person_id = \\$1 AND pc.course_id = \\$2 AND pc.role = 'instructor'").
+		WithArgs(1, 2, 0).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	allowed, _, err := handler.TeachesCourse(req, professor)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc WHERE pc.person_id = \\$1 AND pc.course_id = \\$2 AND pc.role = 'instructor'").
+		WithArgs(1, 2, 0).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	allowed, reason, err := handler.TeachesCourse(req, professor)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "professors may only manage courses they teach", reason)
+
+	// students are denied without a lookup
+	student := &auth.Principal{Subject: "lpage", Roles: []string{auth.RoleStudent}, PersonID: 3}
+	allowed, _, err = handler.TeachesCourse(req, student)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestIsSelf tests the student self-access rule.
+func TestIsSelf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	student := &auth.Principal{Subject: "lpage", Roles: []string{auth.RoleStudent}, PersonID: 3}
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1 AND first_name \\|\\| ' ' \\|\\| last_name = \\$2\\)").
+		WithArgs(3, "Larry Page").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	allowed, _, err := handler.IsSelf(requestWithParam(t, "name", "Larry Page"), student)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1").
+		WithArgs(3, "Bill Gates").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	allowed, reason, err := handler.IsSelf(requestWithParam(t, "name", "Bill Gates"), student)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "students may only access their own record", reason)
+
+	// the id URL param is compared without a query
+	allowed, _, err = handler.IsSelf(requestWithParam(t, "id", "3"), student)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, _, err = handler.IsSelf(requestWithParam(t, "id", "4"), student)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	// a student token without a person link can't be matched
+	allowed, _, err = handler.IsSelf(requestWithParam(t, "name", "Larry Page"), &auth.Principal{Roles: []string{auth.RoleStudent}})
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,80 @@
+// all handlers for course prerequisites (course_prerequisite)
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Return the courses that must be completed before a course can be taken.
+func (h *RequestHandler) GetCoursePrerequisites(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	prerequisites, err := h.store().Prerequisites(r.Context(), uint(courseID))
+	if err != nil {
+		storeFailed(w, err, "querying prerequisites")
+		return
+	}
+
+	courses := make([]Course, len(prerequisites))
+	for i, course := range prerequisites {
+		courses[i] = Course{ID: course.ID, Name: course.Name, Capacity: course.Capacity, Credits: course.Credits}
+	}
+	render(w, r, http.StatusOK, courses)
+}
+
+// Require a course to be completed before another. Prerequisites that would
+// form a cycle are refused with a 409.
+func (h *RequestHandler) AddPrerequisite(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var prerequisite CoursePrerequisite
+	if err := decode(r, &prerequisite); err != nil {
+		decodeError(w, err)
+		return
+	}
+	if prerequisite.PrerequisiteID == 0 {
+		http.Error(w, "prerequisite_id is required", http.StatusBadRequest)
+		return
+	}
+	prerequisite.CourseID = uint(courseID)
+
+	if err := h.store().AddPrerequisite(r.Context(), prerequisite.CourseID, prerequisite.PrerequisiteID); err != nil {
+		storeFailed(w, err, "adding prerequisite")
+		return
+	}
+
+	render(w, r, http.StatusCreated, prerequisite)
+}
+
+// Stop requiring a course before another.
+func (h *RequestHandler) RemovePrerequisite(w http.ResponseWriter, r *http.Request) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	prerequisiteID, err := strconv.Atoi(chi.URLParam(r, "prerequisiteID"))
+	if err != nil {
+		http.Error(w, "Invalid prerequisite ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store().RemovePrerequisite(r.Context(), uint(courseID), uint(prerequisiteID)); err != nil {
+		storeFailed(w, err, "removing prerequisite")
+		return
+	}
+
+	render(w, r, http.StatusOK, Message{Message: "Prerequisite removed successfully"})
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+)
+
+// TestGetCoursePrerequisites tests listing a course's prerequisites.
+func TestGetCoursePrerequisites(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	expectCourseExists(mock, 2, true)
+	mock.ExpectQuery("SELECT cp.course_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor FROM course_prerequisite cp").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"course_id", "id", "name", "capacity", "credits", "requires_instructor"}).AddRow(2, 1, "Programming", nil, nil, false))
+
+	rr := httptest.NewRecorder()
+	handler.GetCoursePrerequisites(rr, routeRequest(t, "GET", "/api/course/2/prerequisites", nil, "id", "2"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"id":1,"name":"Programming"}]`, rr.Body.String())
+
+	expectCourseExists(mock, 9, false)
+	rr = httptest.NewRecorder()
+	handler.GetCoursePrerequisites(rr, routeRequest(t, "GET", "/api/course/9/prerequisites", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Expect the checks made before adding a prerequisite, reporting whether it
+// would close a cycle.
+func expectPrerequisiteChecks(mock sqlmock.Sqlmock, courseID, prerequisiteID int, cycle bool) {
+	mock.ExpectExec("LOCK TABLE course_prerequisite IN SHARE ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\), EXISTS\\(SELECT 1 FROM course WHERE id = \\$2\\)").
+		WithArgs(courseID, prerequisiteID).WillReturnRows(sqlmock.NewRows([]string{"course", "prerequisite"}).AddRow(true, true))
+	mock.ExpectQuery("WITH RECURSIVE required").WithArgs(prerequisiteID, courseID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(cycle))
+}
+
+// TestAddPrerequisite tests adding a prerequisite and refusing cycles.
+func TestAddPrerequisite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	expectPrerequisiteChecks(mock, 3, 2, false)
+	mock.ExpectExec("INSERT INTO course_prerequisite \\(course_id, prerequisite_id\\) VALUES \\(\\$1, \\$2\\) ON CONFLICT DO NOTHING").
+		WithArgs(3, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "prerequisite.added")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.AddPrerequisite(rr, routeRequest(t, "POST", "/api/course/3/prerequisites", CoursePrerequisite{PrerequisiteID: 2}, "id", "3"))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.JSONEq(t, `{"course_id":3,"prerequisite_id":2}`, rr.Body.String())
+
+	// 1 already requires 3 through 2
+	mock.ExpectBegin()
+	expectPrerequisiteChecks(mock, 3, 1, true)
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.AddPrerequisite(rr, routeRequest(t, "POST", "/api/course/3/prerequisites", CoursePrerequisite{PrerequisiteID: 1}, "id", "3"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Course 1 already requires course 3, so it can't be its prerequisite\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.AddPrerequisite(rr, routeRequest(t, "POST", "/api/course/3/prerequisites", CoursePrerequisite{PrerequisiteID: 3}, "id", "3"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "A course can't be its own prerequisite\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRemovePrerequisite tests removing a prerequisite.
+func TestRemovePrerequisite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM course_prerequisite WHERE course_id = \\$1 AND prerequisite_id = \\$2").
+		WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "prerequisite.removed")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.RemovePrerequisite(rr, routeRequest(t, "DELETE", "/api/course/2/prerequisites/1", nil, "id", "2", "prerequisiteID", "1"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM course_prerequisite").WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.RemovePrerequisite(rr, routeRequest(t, "DELETE", "/api/course/2/prerequisites/3", nil, "id", "2", "prerequisiteID", "3"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Course 3 is not a prerequisite of course 2\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAddToRosterPrerequisites tests that students missing prerequisites are
+// refused with the courses they're missing, unless an admin overrides them.
+func TestAddToRosterPrerequisites(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	expectStudent := func() {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+			WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+		storetest.ExpectLockTerm(mock, 0)
+		storetest.ExpectLockCourse(mock, 4, nil)
+		expectStanding(mock, 5, 4, "", 0)
+	}
+
+	expectStudent()
+	expectMissingPrerequisites(mock, 4, 5, Course{ID: 1, Name: "Programming"}, Course{ID: 2, Name: "Databases"})
+	mock.ExpectRollback()
+
+	rr := httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/4/roster", PersonCourse{PersonID: 5}, "id", "4"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Person 5 is missing prerequisites for course 4 in Spring 2026: Programming (1), Databases (2)\n", rr.Body.String())
+
+	// only admins may override
+	override := func(principal *auth.Principal) *http.Request {
+		req := routeRequest(t, "POST", "/api/course/4/roster", PersonCourse{PersonID: 5}, "id", "4")
+		req.URL.RawQuery = "override_prerequisites=true"
+		return req.WithContext(auth.WithPrincipal(req.Context(), principal))
+	}
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, override(registrar))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, "Forbidden: only admins may override prerequisites\n", rr.Body.String())
+
+	expectStudent()
+	storetest.ExpectNoClashes(mock, 4, 5)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(5, 4, storetest.CurrentTerm.ID, "student").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, override(admin))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAddToRosterFailedPrerequisite tests that a prerequisite the student
+// failed doesn't count as completed.
+func TestAddToRosterFailedPrerequisite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+		WithArgs(6).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, nil)
+	expectStanding(mock, 6, 2, "", 0)
+	// the student took Programming in Fall 2025 but was graded F
+	expectMissingPrerequisites(mock, 2, 6, Course{ID: 1, Name: "Programming"})
+	mock.ExpectRollback()
+
+	rr := httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 6}, "id", "2"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Person 6 is missing prerequisites for course 2 in Spring 2026: Programming (1)\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
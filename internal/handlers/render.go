@@ -0,0 +1,524 @@
+// response rendering and request decoding for every supported media type
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types the API can render and decode.
+const (
+	MediaJSON    = "application/json"
+	MediaXML     = "application/xml"
+	MediaCSV     = "text/csv"
+	MediaMsgPack = "application/msgpack"
+)
+
+// alternative spellings accepted from clients, mapped to the media type used
+var mediaAliases = map[string]string{
+	MediaJSON:                 MediaJSON,
+	MediaXML:                  MediaXML,
+	"text/xml":                MediaXML,
+	MediaCSV:                  MediaCSV,
+	MediaMsgPack:              MediaMsgPack,
+	"application/x-msgpack":   MediaMsgPack,
+	"application/vnd.msgpack": MediaMsgPack,
+}
+
+// ErrUnsupportedMediaType is returned by decode when the request body is in
+// a format the API does not understand.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+type mediaTypeKey struct{}
+
+// Pick the response media type from the Accept header, honoring q-values.
+// An empty header or a wildcard selects JSON. ok is false when the client
+// only accepts types the API cannot produce.
+func negotiate(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return MediaJSON, true
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+	var candidates []candidate
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		switch mediaType {
+		case "*/*", "application/*":
+			mediaType = MediaJSON
+		case "text/*":
+			mediaType = MediaCSV
+		default:
+			supported, ok := mediaAliases[mediaType]
+			if !ok {
+				continue
+			}
+			mediaType = supported
+		}
+		candidates = append(candidates, candidate{mediaType, q})
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].mediaType, true
+}
+
+// Negotiate rejects requests whose Accept header can't be satisfied with 406
+// and stores the chosen media type on the request context for render.
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, ok := negotiate(r.Header.Get("Accept"))
+		if !ok {
+			http.Error(w, "Not acceptable, supported types are "+strings.Join(SupportedMediaTypes(), ", "), http.StatusNotAcceptable)
+			return
+		}
+		ctx := context.WithValue(r.Context(), mediaTypeKey{}, mediaType)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// SupportedMediaTypes lists the media types accepted by render and decode.
+func SupportedMediaTypes() []string {
+	return []string{MediaJSON, MediaXML, MediaCSV, MediaMsgPack}
+}
+
+// Return the media type chosen by Negotiate, negotiating again if the
+// request did not pass through the middleware.
+func responseMediaType(r *http.Request) (string, bool) {
+	if mediaType, ok := r.Context().Value(mediaTypeKey{}).(string); ok {
+		return mediaType, true
+	}
+	return negotiate(r.Header.Get("Accept"))
+}
+
+// Write v to the response in the media type negotiated for the request.
+func render(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	mediaType, ok := responseMediaType(r)
+	if !ok {
+		http.Error(w, "Not acceptable, supported types are "+strings.Join(SupportedMediaTypes(), ", "), http.StatusNotAcceptable)
+		return
+	}
+
+	body, err := marshal(mediaType, v)
+	if err != nil {
+		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func marshal(mediaType string, v interface{}) ([]byte, error) {
+	switch mediaType {
+	case MediaXML:
+		return marshalXML(v)
+	case MediaCSV:
+		return marshalCSV(v)
+	case MediaMsgPack:
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		body, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return append(body, '\n'), nil
+	}
+}
+
+// Decode the request body into v according to its Content-Type. A missing
+// Content-Type is treated as JSON.
+func decode(r *http.Request, v interface{}) error {
+	mediaType := MediaJSON
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return ErrUnsupportedMediaType
+		}
+		supported, ok := mediaAliases[parsed]
+		if !ok {
+			return ErrUnsupportedMediaType
+		}
+		mediaType = supported
+	}
+
+	switch mediaType {
+	case MediaXML:
+		return unmarshalXML(r.Body, v)
+	case MediaCSV:
+		return unmarshalCSV(r.Body, v)
+	case MediaMsgPack:
+		dec := msgpack.NewDecoder(r.Body)
+		dec.SetCustomStructTag("json")
+		return dec.Decode(v)
+	default:
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+}
+
+// Report a decode failure, using 415 when the body format is unsupported.
+func decodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		http.Error(w, "Unsupported content type, supported types are "+strings.Join(SupportedMediaTypes(), ", "), http.StatusUnsupportedMediaType)
+		return
+	}
+	http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+}
+
+// Convert a Go type name such as CompletePerson to complete_person for use as
+// an XML element name.
+func elementName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var b strings.Builder
+	for i, c := range t.Name() {
+		if unicode.IsUpper(c) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	if b.Len() == 0 {
+		return "item"
+	}
+	return b.String()
+}
+
+// Marshal v as XML. Slices are wrapped in an <items> root element so the
+// document always has a single root.
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf strings.Builder
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		root := xml.StartElement{Name: xml.Name{Local: "items"}}
+		if err := enc.EncodeToken(root); err != nil {
+			return nil, err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			item := rv.Index(i)
+			start := xml.StartElement{Name: xml.Name{Local: elementName(item.Type())}}
+			if err := enc.EncodeElement(item.Interface(), start); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.EncodeToken(root.End()); err != nil {
+			return nil, err
+		}
+	} else {
+		start := xml.StartElement{Name: xml.Name{Local: elementName(rv.Type())}}
+		if err := enc.EncodeElement(v, start); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+// csvColumns returns the exported fields of a struct type keyed by the name
+// in their json tag, in declaration order.
+func csvColumns(t reflect.Type) ([]string, []int) {
+	var names []string
+	var indexes []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+		indexes = append(indexes, i)
+	}
+	return names, indexes
+}
+
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(text)
+	}
+	if v.Kind() == reflect.Slice {
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = csvValue(v.Index(i))
+		}
+		return strings.Join(parts, ";")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Marshal a struct or slice of structs as CSV with a header row taken from
+// the json tags. List fields are joined with semicolons.
+func marshalCSV(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	var items []reflect.Value
+	elemType := rv.Type()
+	if rv.Kind() == reflect.Slice {
+		elemType = elemType.Elem()
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, rv.Index(i))
+		}
+	} else {
+		items = append(items, rv)
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode %s as CSV", elemType)
+	}
+
+	var buf strings.Builder
+	cw := csv.NewWriter(&buf)
+	names, indexes := csvColumns(elemType)
+	if err := cw.Write(names); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		record := make([]string, len(indexes))
+		for i, index := range indexes {
+			record[i] = csvValue(item.Field(index))
+		}
+		if err := cw.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+func setCSVValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		if value == "" {
+			return nil
+		}
+		elem := reflect.New(field.Type().Elem())
+		if err := setCSVValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		if value == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), 0, 0)
+		if value != "" {
+			for _, part := range strings.Split(value, ";") {
+				elem := reflect.New(field.Type().Elem()).Elem()
+				if err := setCSVValue(elem, strings.TrimSpace(part)); err != nil {
+					return err
+				}
+				slice = reflect.Append(slice, elem)
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("cannot decode CSV into %s", field.Type())
+	}
+	return nil
+}
+
+// Unmarshal a CSV body with a header row and a single record into the struct
+// pointed to by v.
+func unmarshalCSV(body io.Reader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode CSV into %T", v)
+	}
+	rv = rv.Elem()
+
+	records, err := csv.NewReader(body).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) != 2 {
+		return errors.New("CSV body must contain a header row and exactly one record")
+	}
+
+	names, indexes := csvColumns(rv.Type())
+	columns := make(map[string]int, len(names))
+	for i, name := range names {
+		columns[name] = indexes[i]
+	}
+
+	header, record := records[0], records[1]
+	for i, name := range header {
+		index, ok := columns[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown CSV column %q", name)
+		}
+		if err := setCSVValue(rv.Field(index), record[i]); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Decode an XML body into v, refusing elements v has no field for so a
+// misspelled field isn't silently dropped the way it would be in JSON.
+func unmarshalXML(body io.Reader, v interface{}) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	fields := xmlFields(reflect.TypeOf(v))
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var path []string
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			path = append(path, token.Name.Local)
+			switch len(path) {
+			case 2:
+				if _, ok := fields[path[1]]; !ok {
+					return fmt.Errorf("unknown XML element %q", path[1])
+				}
+			case 3:
+				// wrapped lists such as <courses><course>
+				if inner := fields[path[1]]; inner != "" && inner != path[2] {
+					return fmt.Errorf("unknown XML element %q", path[1]+">"+path[2])
+				}
+			}
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			if len(path) == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// Return the child element names of the struct t decodes from, mapped to
+// the name of their items for wrapped lists and to "" otherwise.
+func xmlFields(t reflect.Type) map[string]string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	fields := map[string]string{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("xml"), ",")
+		if !field.IsExported() || field.Name == "XMLName" || tag[0] == "-" || len(tag) > 1 && tag[1] == "attr" {
+			continue
+		}
+		name := tag[0]
+		if name == "" {
+			name = field.Name
+		}
+		outer, inner, _ := strings.Cut(name, ">")
+		fields[outer] = inner
+	}
+	return fields
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// TestNegotiate tests Accept header parsing.
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept    string
+		mediaType string
+		ok        bool
+	}{
+		{"", MediaJSON, true},
+		{"*/*", MediaJSON, true},
+		{"application/xml", MediaXML, true},
+		{"text/xml", MediaXML, true},
+		{"text/csv", MediaCSV, true},
+		{"application/x-msgpack", MediaMsgPack, true},
+		{"application/json;q=0.5, application/xml", MediaXML, true},
+		{"text/html, application/msgpack;q=0.1", MediaMsgPack, true},
+		{"text/html", "", false},
+		{"application/json;q=0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			mediaType, ok := negotiate(tt.accept)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.mediaType, mediaType)
+		})
+	}
+}
+
+// TestRenderFormats tests that a list of courses renders in every format.
+func TestRenderFormats(t *testing.T) {
+	capacity := uint(30)
+	courses := []Course{{ID: 1, Name: "Programming"}, {ID: 2, Name: "Databases", Capacity: &capacity}}
+
+	tests := []struct {
+		accept string
+		check  func(t *testing.T, body []byte)
+	}{
+		{MediaJSON, func(t *testing.T, body []byte) {
+			assert.JSONEq(t, `[{"id":1,"name":"Programming"},{"id":2,"name":"Databases","capacity":30}]`, string(body))
+		}},
+		{MediaXML, func(t *testing.T, body []byte) {
+			var doc struct {
+				XMLName xml.Name `xml:"items"`
+				Courses []Course `xml:"course"`
+			}
+			assert.NoError(t, xml.Unmarshal(body, &doc))
+			assert.Equal(t, courses, doc.Courses)
+		}},
+		{MediaCSV, func(t *testing.T, body []byte) {
+			assert.Equal(t, "id,name,capacity,credits,requires_instructor\n1,Programming,,,false\n2,Databases,30,,false\n", string(body))
+		}},
+		{MediaMsgPack, func(t *testing.T, body []byte) {
+			var decoded []Course
+			dec := msgpack.NewDecoder(bytes.NewReader(body))
+			dec.SetCustomStructTag("json")
+			assert.NoError(t, dec.Decode(&decoded))
+			assert.Equal(t, courses, decoded)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/api/course", nil)
+			assert.NoError(t, err)
+			req.Header.Set("Accept", tt.accept)
+
+			rr := httptest.NewRecorder()
+			render(rr, req, http.StatusOK, courses)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.accept, rr.Header().Get("Content-Type"))
+			tt.check(t, rr.Body.Bytes())
+		})
+	}
+}
+
+// TestDecodeFormats tests that a person body is accepted in every format.
+func TestDecodeFormats(t *testing.T) {
+	expected := CompletePerson{FirstName: "John", LastName: "Doe", Type: "student", Age: 25, Courses: []uint{1, 2}}
+
+	packed, err := marshal(MediaMsgPack, expected)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{MediaJSON, []byte(`{"first_name":"John","last_name":"Doe","type":"student","age":25,"courses":[1,2]}`)},
+		{MediaXML, []byte(`<complete_person><first_name>John</first_name><last_name>Doe</last_name><type>student</type><age>25</age><courses><course>1</course><course>2</course></courses></complete_person>`)},
+		{MediaCSV, []byte("first_name,last_name,type,age,courses\nJohn,Doe,student,25,1;2\n")},
+		{MediaMsgPack, packed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/api/person", bytes.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			var person CompletePerson
+			assert.NoError(t, decode(req, &person))
+			assert.Equal(t, expected, person)
+		})
+	}
+}
+
+// TestDecodeUnknownFields tests that XML and CSV bodies with fields the
+// body type doesn't have are refused rather than partly decoded.
+func TestDecodeUnknownFields(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		expected    string
+	}{
+		{MediaXML, `<complete_person><first_name>John</first_name><frist_name>Jon</frist_name></complete_person>`, `unknown XML element "frist_name"`},
+		{MediaXML, `<complete_person><courses><course>1</course><coarse>2</coarse></courses></complete_person>`, `unknown XML element "courses>coarse"`},
+		{MediaXML, `<complete_person><first_name>John</first_name>`, "XML syntax error on line 1: unexpected EOF"},
+		{MediaCSV, "first_name,nickname\nJohn,Jack\n", `unknown CSV column "nickname"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/api/person", strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			var person CompletePerson
+			assert.EqualError(t, decode(req, &person), tt.expected)
+		})
+	}
+}
+
+// TestNegotiateMiddleware tests that unsupported Accept headers are rejected
+// before the handler runs.
+func TestNegotiateMiddleware(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	req, err := http.NewRequest("DELETE", "/api/course/1", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+
+	rr := httptest.NewRecorder()
+	Negotiate(http.HandlerFunc(handler.DeleteCourse)).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUnsupportedContentType tests that CreateCourse rejects unknown body
+// formats with 415.
+func TestUnsupportedContentType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	req, err := http.NewRequest("POST", "/api/course", strings.NewReader("name: yaml"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/yaml")
+
+	rr := httptest.NewRecorder()
+	handler.CreateCourse(rr, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
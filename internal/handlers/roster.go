@@ -0,0 +1,215 @@
+// all handlers for course rosters (person_course)
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// Parse the course id URL param and check the course exists, writing the
+// error response and returning false when it doesn't.
+func (h *RequestHandler) rosterCourseID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+
+	var exists bool
+	err = h.DB.QueryRowContext(r.Context(), "SELECT EXISTS(SELECT 1 FROM course WHERE id = $1)", courseID).Scan(&exists)
+	if err != nil {
+		http.Error(w, "Error checking course existence: "+err.Error(), http.StatusInternalServerError)
+		return 0, false
+	}
+	if !exists {
+		http.Error(w, "Course not found", http.StatusNotFound)
+		return 0, false
+	}
+	return courseID, true
+}
+
+// Read the override_prerequisites query, which enrolls students missing a
+// course's prerequisites. Only admins may set it; anyone else gets a 403 and
+// false is returned.
+func overridePrerequisites(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	value := r.URL.Query().Get("override_prerequisites")
+	if value == "" {
+		return false, true
+	}
+	override, err := strconv.ParseBool(value)
+	if err != nil {
+		http.Error(w, "Invalid override_prerequisites: "+value, http.StatusBadRequest)
+		return false, false
+	}
+	if override {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok || !principal.HasRole(auth.RoleAdmin) {
+			http.Error(w, "Forbidden: only admins may override prerequisites", http.StatusForbidden)
+			return false, false
+		}
+	}
+	return override, true
+}
+
+// Return everyone enrolled in or teaching a course in a term with their
+// role, the current term unless the term query param names another.
+func (h *RequestHandler) GetCourseRoster(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := h.rosterCourseID(w, r)
+	if !ok {
+		return
+	}
+	term, ok := h.queryTerm(w, r)
+	if !ok {
+		return
+	}
+
+	people := []RosterMember{}
+	rows, err := h.DB.QueryContext(r.Context(), `
+        SELECT p.id, p.first_name, p.last_name, p.type, p.age, pc.role
+        FROM person p
+        JOIN person_course pc ON pc.person_id = p.id
+        WHERE pc.course_id = $1 AND pc.term_id = $2
+        ORDER BY p.id`, courseID, term.ID)
+	if err != nil {
+		http.Error(w, "Error querying roster: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var person RosterMember
+		if err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age, &person.Role); err != nil {
+			http.Error(w, "Error scanning person data: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		people = append(people, person)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, "Error iterating over roster: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, people)
+}
+
+// Add a Person to a course's roster with a role, or its waitlist when it's
+// full, in the term named by the term query param or the current term.
+func (h *RequestHandler) AddToRoster(w http.ResponseWriter, r *http.Request) {
+	// the store checks the course exists
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var enrollment PersonCourse
+	if err := decode(r, &enrollment); err != nil {
+		decodeError(w, err)
+		return
+	}
+	if enrollment.PersonID == 0 {
+		http.Error(w, "person_id is required", http.StatusBadRequest)
+		return
+	}
+	enrollment.CourseID = uint(courseID)
+
+	override, ok := overridePrerequisites(w, r)
+	if !ok {
+		return
+	}
+	termID, ok := termParam(w, r)
+	if !ok {
+		return
+	}
+
+	opts := store.EnrollOptions{OverridePrerequisites: override, Term: termID, Role: enrollment.Role}
+	enrolled, err := h.store().Enroll(r.Context(), enrollment.CourseID, enrollment.PersonID, opts)
+	if err != nil {
+		storeFailed(w, err, "adding to roster")
+		return
+	}
+	enrollment.TermID, enrollment.Role = enrolled.TermID, enrolled.Role
+	enrollment.Status, enrollment.Position = enrolled.Status, enrolled.Position
+
+	// a student placed on a full course's waitlist isn't enrolled yet
+	status := http.StatusCreated
+	if enrolled.Status == store.StatusWaitlisted {
+		status = http.StatusAccepted
+	}
+	render(w, r, status, enrollment)
+}
+
+// Return the people waiting for a seat in a course in a term, first in line
+// first.
+func (h *RequestHandler) GetCourseWaitlist(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := h.rosterCourseID(w, r)
+	if !ok {
+		return
+	}
+	term, ok := h.queryTerm(w, r)
+	if !ok {
+		return
+	}
+
+	waitlist := []WaitlistEntry{}
+	rows, err := h.DB.QueryContext(r.Context(), `
+        SELECT p.id, p.first_name, p.last_name, p.type, p.age, wl.created_at
+        FROM waitlist wl
+        JOIN person p ON p.id = wl.person_id
+        WHERE wl.course_id = $1 AND wl.term_id = $2
+        ORDER BY wl.id`, courseID, term.ID)
+	if err != nil {
+		http.Error(w, "Error querying waitlist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := WaitlistEntry{Position: len(waitlist) + 1}
+		person := &entry.Person
+		if err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age, &entry.CreatedAt); err != nil {
+			http.Error(w, "Error scanning waitlist data: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		waitlist = append(waitlist, entry)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, "Error iterating over waitlist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, waitlist)
+}
+
+// Remove a Person from a course's roster or waitlist in a term. A seat they
+// free goes to the next student waiting in it.
+func (h *RequestHandler) RemoveFromRoster(w http.ResponseWriter, r *http.Request) {
+	// the store checks the course exists
+	courseID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid course ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	personID, err := strconv.Atoi(chi.URLParam(r, "personID"))
+	if err != nil {
+		http.Error(w, "Invalid person ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	termID, ok := termParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store().Unenroll(r.Context(), uint(courseID), uint(personID), termID); err != nil {
+		storeFailed(w, err, "removing from roster")
+		return
+	}
+
+	render(w, r, http.StatusOK, Message{Message: "Person removed from roster successfully"})
+}
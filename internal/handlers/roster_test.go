@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func expectCourseExists(mock sqlmock.Sqlmock, id int, exists bool) {
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM course WHERE id = \\$1\\)").
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+// Expect the students enrolled in and waiting for a course in the current
+// term to be counted.
+func expectSeats(mock sqlmock.Sqlmock, id, enrolled, waiting int) {
+	mock.ExpectQuery("SELECT \\(SELECT count\\(\\*\\) FROM person_course WHERE course_id = \\$1 AND term_id = \\$2 AND role = 'student'\\)").WithArgs(id, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"enrolled", "waiting"}).AddRow(enrolled, waiting))
+}
+
+// Expect where a person stands on a course in the current term to be looked
+// up: their role when enrolled, or their waitlist position, empty and 0 when
+// they're on neither. The course has the instructor it needs.
+func expectStanding(mock sqlmock.Sqlmock, personID, courseID int, role string, position int) {
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course WHERE person_id = \\$1 AND course_id = \\$2 AND term_id = \\$3\\), ''\\)").
+		WithArgs(personID, courseID, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow(role, position, false))
+}
+
+// Expect a student's missing prerequisites for a course in the current term
+// to be looked up.
+func expectMissingPrerequisites(mock sqlmock.Sqlmock, courseID, personID int, missing ...Course) {
+	rows := sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"})
+	for _, course := range missing {
+		rows.AddRow(course.ID, course.Name, nil, nil, false)
+	}
+	mock.ExpectQuery("SELECT c.id, c.name, c.capacity, c.credits, c.requires_instructor FROM course_prerequisite cp .* NOT EXISTS\\(.* AND pc.role = 'student' AND pc.grade_points > 0\\)").
+		WithArgs(courseID, personID, storetest.CurrentTerm.Start).WillReturnRows(rows)
+}
+
+// TestGetCourseRoster tests listing the people on a course.
+func TestGetCourseRoster(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	expectCourseExists(mock, 1, true)
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT p.id, p.first_name, p.last_name, p.type, p.age, pc.role FROM person p JOIN person_course pc").
+		WithArgs(1, storetest.CurrentTerm.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "role"}).
+		AddRow(1, "Steve", "Jobs", "professor", 56, "instructor").
+		AddRow(3, "Larry", "Page", "student", 51, "student"))
+
+	req, err := http.NewRequest("GET", "/api/course/1/roster", nil)
+	assert.NoError(t, err)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	handler.GetCourseRoster(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var people []RosterMember
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&people))
+	assert.Len(t, people, 2)
+	assert.Equal(t, "Larry", people[1].FirstName)
+	assert.Equal(t, "instructor", people[0].Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Build a request to target with body encoded as JSON and the chi URL
+// params in pairs of name and value.
+func routeRequest(t *testing.T, method, target string, body interface{}, params ...string) *http.Request {
+	var buf bytes.Buffer
+	if body != nil {
+		assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req, err := http.NewRequest(method, target, &buf)
+	assert.NoError(t, err)
+	rctx := chi.NewRouteContext()
+	for i := 0; i+1 < len(params); i += 2 {
+		rctx.URLParams.Add(params[i], params[i+1])
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// TestAddToRoster tests enrolling a person in a course.
+func TestAddToRoster(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+		WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 3)
+	expectStanding(mock, 4, 2, "", 0)
+	expectMissingPrerequisites(mock, 2, 4)
+	storetest.ExpectNoClashes(mock, 2, 4)
+	expectSeats(mock, 2, 2, 0)
+	mock.ExpectExec("INSERT INTO person_course \\(person_id, course_id, term_id, role\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\)").
+		WithArgs(4, 2, storetest.CurrentTerm.ID, "student").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 4}, "id", "2"))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var enrollment PersonCourse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&enrollment))
+	assert.Equal(t, PersonCourse{PersonID: 4, CourseID: 2, TermID: storetest.CurrentTerm.ID, Role: "student", Status: "enrolled"}, enrollment)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAddToRosterWaitlisted tests that students are queued behind anyone
+// already waiting for a full course, and that professors never are.
+func TestAddToRosterWaitlisted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+		WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 3)
+	expectStanding(mock, 5, 2, "", 0)
+	expectMissingPrerequisites(mock, 2, 5)
+	storetest.ExpectNoClashes(mock, 2, 5)
+	expectSeats(mock, 2, 3, 1)
+	mock.ExpectExec("INSERT INTO waitlist \\(person_id, course_id, term_id\\) VALUES \\(\\$1, \\$2, \\$3\\)").
+		WithArgs(5, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "waitlist.added")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 5}, "id", "2"))
+
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	var enrollment PersonCourse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&enrollment))
+	assert.Equal(t, PersonCourse{PersonID: 5, CourseID: 2, TermID: storetest.CurrentTerm.ID, Role: "student", Status: "waitlisted", Position: 2}, enrollment)
+
+	// asking again keeps their place
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+		WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("student"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 3)
+	expectStanding(mock, 5, 2, "", 2)
+	mock.ExpectCommit()
+
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 5}, "id", "2"))
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+
+	// professors teach however full the course is
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow("professor"))
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 3)
+	expectStanding(mock, 1, 2, "", 0)
+	storetest.ExpectNoClashes(mock, 2, 1)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(1, 2, storetest.CurrentTerm.ID, "instructor").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 1}, "id", "2"))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAddToRosterRoles tests enrolling with a role: TAs take no seat, only
+// professors instruct, a course requiring an instructor turns students away
+// until it has one, and a role only changes by removing them first.
+func TestAddToRosterRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	expectPerson := func(id int, personType string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT type FROM person WHERE id = \\$1 FOR NO KEY UPDATE").
+			WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"type"}).AddRow(personType))
+		storetest.ExpectLockTerm(mock, 0)
+		storetest.ExpectLockCourse(mock, 2, 3)
+	}
+
+	// the course is full, but a TA needs no seat or prerequisites
+	expectPerson(4, "student")
+	expectStanding(mock, 4, 2, "", 0)
+	storetest.ExpectNoClashes(mock, 2, 4)
+	mock.ExpectExec("INSERT INTO person_course").WithArgs(4, 2, storetest.CurrentTerm.ID, "ta").WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+	rr := httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 4, Role: "ta"}, "id", "2"))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var enrollment PersonCourse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&enrollment))
+	assert.Equal(t, PersonCourse{PersonID: 4, CourseID: 2, TermID: storetest.CurrentTerm.ID, Role: "ta", Status: "enrolled"}, enrollment)
+
+	expectPerson(4, "student")
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 4, Role: "instructor"}, "id", "2"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Only professors can be instructors, person 4 is a student\n", rr.Body.String())
+
+	expectPerson(4, "student")
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 4, Role: "dean"}, "id", "2"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "role must be one of instructor, student, ta\n", rr.Body.String())
+
+	expectPerson(5, "student")
+	mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT role FROM person_course").WithArgs(5, 2, storetest.CurrentTerm.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "position", "needs_instructor"}).AddRow("", 0, true))
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 5}, "id", "2"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Course 2 needs an instructor in Spring 2026 before students can enroll\n", rr.Body.String())
+
+	expectPerson(4, "student")
+	expectStanding(mock, 4, 2, "ta", 0)
+	mock.ExpectRollback()
+	rr = httptest.NewRecorder()
+	handler.AddToRoster(rr, routeRequest(t, "POST", "/api/course/2/roster", PersonCourse{PersonID: 4, Role: "student"}, "id", "2"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Person 4 is already the ta of course 2 in Spring 2026, remove them to change their role\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRemoveFromRoster tests dropping a person from a course, which promotes
+// the next student waiting, and from its waitlist.
+func TestRemoveFromRoster(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 3)
+	mock.ExpectExec("DELETE FROM person_course WHERE person_id = \\$1 AND course_id = \\$2 AND term_id = \\$3").
+		WithArgs(4, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "enrollment.removed")
+	expectSeats(mock, 2, 2, 2)
+	mock.ExpectQuery("WITH promoted AS \\( DELETE FROM waitlist").WithArgs(2, storetest.CurrentTerm.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id"}).AddRow(5))
+	storetest.ExpectEvent(mock, "waitlist.removed")
+	storetest.ExpectEvent(mock, "enrollment.added")
+	mock.ExpectCommit()
+
+	// off the waitlist
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, 3)
+	mock.ExpectExec("DELETE FROM person_course WHERE person_id = \\$1 AND course_id = \\$2 AND term_id = \\$3").
+		WithArgs(6, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM waitlist WHERE person_id = \\$1 AND course_id = \\$2 AND term_id = \\$3").
+		WithArgs(6, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "waitlist.removed")
+	mock.ExpectCommit()
+
+	// on neither
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 2, nil)
+	mock.ExpectExec("DELETE FROM person_course").WithArgs(7, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM waitlist").WithArgs(7, 2, storetest.CurrentTerm.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	storetest.ExpectLockTerm(mock, 0)
+	storetest.ExpectLockCourse(mock, 9, false)
+	mock.ExpectRollback()
+
+	for _, tt := range []struct {
+		courseID     string
+		personID     string
+		expectedCode int
+	}{
+		{"2", "4", http.StatusOK},
+		{"2", "6", http.StatusOK},
+		{"2", "7", http.StatusNotFound},
+		{"9", "4", http.StatusNotFound},
+	} {
+		rr := httptest.NewRecorder()
+		handler.RemoveFromRoster(rr, routeRequest(t, "DELETE", "/api/course/"+tt.courseID+"/roster/"+tt.personID, nil, "id", tt.courseID, "personID", tt.personID))
+		assert.Equal(t, tt.expectedCode, rr.Code)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetCourseWaitlist tests listing the people waiting for a course in
+// order.
+func TestGetCourseWaitlist(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	created := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	expectCourseExists(mock, 3, true)
+	storetest.ExpectTerm(mock, 0)
+	mock.ExpectQuery("SELECT p.id, p.first_name, p.last_name, p.type, p.age, wl.created_at FROM waitlist wl").
+		WithArgs(3, storetest.CurrentTerm.ID).WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "created_at"}).
+		AddRow(5, "Elon", "Musk", "student", 52, created).
+		AddRow(4, "Bill", "Gates", "student", 67, created.Add(time.Minute)))
+
+	rr := httptest.NewRecorder()
+	handler.GetCourseWaitlist(rr, routeRequest(t, "GET", "/api/course/3/waitlist", nil, "id", "3"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var waitlist []WaitlistEntry
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&waitlist))
+	assert.Len(t, waitlist, 2)
+	assert.Equal(t, WaitlistEntry{Position: 2, Person: Person{ID: 4, FirstName: "Bill", LastName: "Gates", Type: "student", Age: 67}, CreatedAt: created.Add(time.Minute)}, waitlist[1])
+
+	expectCourseExists(mock, 9, false)
+	rr = httptest.NewRecorder()
+	handler.GetCourseWaitlist(rr, routeRequest(t, "GET", "/api/course/9/waitlist", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
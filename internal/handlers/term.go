@@ -0,0 +1,142 @@
+// all handlers for academic terms (term) and the term query param
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// Read the term query param naming the term of the enrollments a route
+// reads or changes, 0 for the current term when it's missing. Anything but
+// an id gets a 400 and false is returned.
+func termParam(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	value := r.URL.Query().Get("term")
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil || id == 0 {
+		http.Error(w, "Invalid term: "+value, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// Resolve the term query param to a term, the current one when it's
+// missing, writing the error response and returning false when there's no
+// such term.
+func (h *RequestHandler) queryTerm(w http.ResponseWriter, r *http.Request) (store.Term, bool) {
+	id, ok := termParam(w, r)
+	if !ok {
+		return store.Term{}, false
+	}
+	term, err := h.store().Term(r.Context(), id)
+	if err != nil {
+		storeFailed(w, err, "querying term")
+		return term, false
+	}
+	return term, true
+}
+
+// Convert a store term to its response body.
+func termBody(term store.Term) Term {
+	return Term{ID: term.ID, Name: term.Name, Start: term.Start, End: term.End}
+}
+
+// Return every term in date order.
+func (h *RequestHandler) GetAllTerms(w http.ResponseWriter, r *http.Request) {
+	terms, err := h.store().ListTerms(r.Context())
+	if err != nil {
+		storeFailed(w, err, "querying terms")
+		return
+	}
+
+	bodies := make([]Term, len(terms))
+	for i, term := range terms {
+		bodies[i] = termBody(term)
+	}
+	render(w, r, http.StatusOK, bodies)
+}
+
+// Return the current term, the latest to have started.
+func (h *RequestHandler) GetCurrentTerm(w http.ResponseWriter, r *http.Request) {
+	term, err := h.store().Term(r.Context(), 0)
+	if err != nil {
+		storeFailed(w, err, "querying term")
+		return
+	}
+	render(w, r, http.StatusOK, termBody(term))
+}
+
+// Return a term by id.
+func (h *RequestHandler) GetTerm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		http.Error(w, "Invalid term ID: "+chi.URLParam(r, "id"), http.StatusBadRequest)
+		return
+	}
+
+	term, err := h.store().Term(r.Context(), uint(id))
+	if err != nil {
+		storeFailed(w, err, "querying term")
+		return
+	}
+	render(w, r, http.StatusOK, termBody(term))
+}
+
+// Create a term. Terms that share a name or overlap another are refused
+// with a 409.
+func (h *RequestHandler) CreateTerm(w http.ResponseWriter, r *http.Request) {
+	var body Term
+	if err := decode(r, &body); err != nil {
+		decodeError(w, err)
+		return
+	}
+
+	term, err := h.store().CreateTerm(r.Context(), store.Term{Name: body.Name, Start: body.Start, End: body.End})
+	if err != nil {
+		storeFailed(w, err, "creating term")
+		return
+	}
+	render(w, r, http.StatusCreated, termBody(term))
+}
+
+// Replace a term's name and dates.
+func (h *RequestHandler) UpdateTerm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		http.Error(w, "Invalid term ID: "+chi.URLParam(r, "id"), http.StatusBadRequest)
+		return
+	}
+
+	var body Term
+	if err := decode(r, &body); err != nil {
+		decodeError(w, err)
+		return
+	}
+
+	term, err := h.store().UpdateTerm(r.Context(), uint(id), store.Term{Name: body.Name, Start: body.Start, End: body.End})
+	if err != nil {
+		storeFailed(w, err, "updating term")
+		return
+	}
+	render(w, r, http.StatusOK, termBody(term))
+}
+
+// Delete a term nobody is enrolled in.
+func (h *RequestHandler) DeleteTerm(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id < 1 {
+		http.Error(w, "Invalid term ID: "+chi.URLParam(r, "id"), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store().DeleteTerm(r.Context(), uint(id)); err != nil {
+		storeFailed(w, err, "deleting term")
+		return
+	}
+	render(w, r, http.StatusOK, Message{Message: "Term deleted successfully"})
+}
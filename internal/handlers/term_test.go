@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
+)
+
+// TestGetAllTerms tests listing terms.
+func TestGetAllTerms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("SELECT id, name, .* FROM term ORDER BY start_date").
+		WillReturnRows(storetest.TermRows(store.Term{ID: 1, Name: "Fall 2025", Start: "2025-09-01", End: "2025-12-19"}, storetest.CurrentTerm))
+
+	rr := httptest.NewRecorder()
+	handler.GetAllTerms(rr, routeRequest(t, "GET", "/api/term", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[
+		{"id":1,"name":"Fall 2025","start":"2025-09-01","end":"2025-12-19"},
+		{"id":2,"name":"Spring 2026","start":"2026-01-12","end":"2026-05-15"}
+	]`, rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetTerm tests reading a term and the current term.
+func TestGetTerm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	storetest.ExpectTerm(mock, 2)
+	rr := httptest.NewRecorder()
+	handler.GetTerm(rr, routeRequest(t, "GET", "/api/term/2", nil, "id", "2"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":2,"name":"Spring 2026","start":"2026-01-12","end":"2026-05-15"}`, rr.Body.String())
+
+	storetest.ExpectTerm(mock, 0)
+	rr = httptest.NewRecorder()
+	handler.GetCurrentTerm(rr, routeRequest(t, "GET", "/api/term", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":2,"name":"Spring 2026","start":"2026-01-12","end":"2026-05-15"}`, rr.Body.String())
+
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(0).WillReturnRows(storetest.TermRows())
+	rr = httptest.NewRecorder()
+	handler.GetCurrentTerm(rr, routeRequest(t, "GET", "/api/term", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "No term has started yet\n", rr.Body.String())
+
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(9).WillReturnRows(storetest.TermRows())
+	rr = httptest.NewRecorder()
+	handler.GetTerm(rr, routeRequest(t, "GET", "/api/term/9", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Term not found\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.GetTerm(rr, routeRequest(t, "GET", "/api/term/fall", nil, "id", "fall"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Invalid term ID: fall\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Expect the terms to be locked and checked for one sharing a name or dates
+// with term, finding other when it isn't empty.
+func expectCheckTerm(mock sqlmock.Sqlmock, term, other store.Term) {
+	mock.ExpectExec("LOCK TABLE term IN SHARE ROW EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := storetest.TermRows()
+	if other.ID != 0 {
+		rows = storetest.TermRows(other)
+	}
+	mock.ExpectQuery("FROM term WHERE id <> \\$1 AND \\(name = \\$2 OR \\(start_date <= \\$4 AND \\$3 <= end_date\\)\\)").
+		WithArgs(term.ID, term.Name, term.Start, term.End).WillReturnRows(rows)
+}
+
+// TestCreateTerm tests creating a term and refusing overlapping ones.
+func TestCreateTerm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	fall := store.Term{Name: "Fall 2026", Start: "2026-09-01", End: "2026-12-18"}
+
+	mock.ExpectBegin()
+	expectCheckTerm(mock, fall, store.Term{})
+	mock.ExpectQuery("INSERT INTO term \\(name, start_date, end_date\\) VALUES \\(\\$1, \\$2, \\$3\\) RETURNING id").
+		WithArgs("Fall 2026", "2026-09-01", "2026-12-18").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	storetest.ExpectEvent(mock, "term.created")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.CreateTerm(rr, routeRequest(t, "POST", "/api/term", Term{Name: "Fall 2026", Start: "2026-09-01", End: "2026-12-18"}))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.JSONEq(t, `{"id":3,"name":"Fall 2026","start":"2026-09-01","end":"2026-12-18"}`, rr.Body.String())
+
+	overlapping := store.Term{Name: "Summer 2026", Start: "2026-05-01", End: "2026-08-14"}
+	mock.ExpectBegin()
+	expectCheckTerm(mock, overlapping, storetest.CurrentTerm)
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.CreateTerm(rr, routeRequest(t, "POST", "/api/term", Term{Name: "Summer 2026", Start: "2026-05-01", End: "2026-08-14"}))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Term Summer 2026 overlaps Spring 2026 (2), which runs from 2026-01-12 to 2026-05-15\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.CreateTerm(rr, routeRequest(t, "POST", "/api/term", Term{Name: "Fall 2026", Start: "2026-12-18", End: "2026-09-01"}))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "A term can't end before it starts\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.CreateTerm(rr, routeRequest(t, "POST", "/api/term", Term{Name: "Fall 2026", Start: "September", End: "2026-12-18"}))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "start must be a date like 2026-09-01\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateTerm tests renaming a term and refusing a name already taken.
+func TestUpdateTerm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	spring := store.Term{ID: 2, Name: "Spring Semester 2026", Start: "2026-01-12", End: "2026-05-15"}
+
+	mock.ExpectBegin()
+	expectCheckTerm(mock, spring, store.Term{})
+	mock.ExpectExec("UPDATE term SET name = \\$1, start_date = \\$2, end_date = \\$3 WHERE id = \\$4").
+		WithArgs(spring.Name, spring.Start, spring.End, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "term.updated")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.UpdateTerm(rr, routeRequest(t, "PUT", "/api/term/2", Term{Name: spring.Name, Start: spring.Start, End: spring.End}, "id", "2"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":2,"name":"Spring Semester 2026","start":"2026-01-12","end":"2026-05-15"}`, rr.Body.String())
+
+	fall := store.Term{ID: 1, Name: "Spring 2026", Start: "2025-09-01", End: "2025-12-19"}
+	mock.ExpectBegin()
+	expectCheckTerm(mock, fall, storetest.CurrentTerm)
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.UpdateTerm(rr, routeRequest(t, "PUT", "/api/term/1", Term{Name: fall.Name, Start: fall.Start, End: fall.End}, "id", "1"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "A term named Spring 2026 already exists\n", rr.Body.String())
+
+	missing := store.Term{ID: 9, Name: "Fall 2030", Start: "2030-09-01", End: "2030-12-18"}
+	mock.ExpectBegin()
+	expectCheckTerm(mock, missing, store.Term{})
+	mock.ExpectExec("UPDATE term").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.UpdateTerm(rr, routeRequest(t, "PUT", "/api/term/9", Term{Name: missing.Name, Start: missing.Start, End: missing.End}, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeleteTerm tests deleting a term and refusing ones with enrollments.
+func TestDeleteTerm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	expectUsed := func(id int, used bool) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT name, .* FROM term WHERE id = \\$1 FOR UPDATE").WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "start", "end"}).AddRow("Fall 2026", "2026-09-01", "2026-12-18"))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person_course WHERE term_id = \\$1\\) OR EXISTS\\(SELECT 1 FROM waitlist WHERE term_id = \\$1\\)").
+			WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"used"}).AddRow(used))
+	}
+
+	expectUsed(3, false)
+	mock.ExpectExec("DELETE FROM term WHERE id = \\$1").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	storetest.ExpectEvent(mock, "term.deleted")
+	mock.ExpectCommit()
+
+	rr := httptest.NewRecorder()
+	handler.DeleteTerm(rr, routeRequest(t, "DELETE", "/api/term/3", nil, "id", "3"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"message":"Term deleted successfully"}`, rr.Body.String())
+
+	expectUsed(3, true)
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.DeleteTerm(rr, routeRequest(t, "DELETE", "/api/term/3", nil, "id", "3"))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "Term Fall 2026 has enrollments, so it can't be deleted\n", rr.Body.String())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM term WHERE id = \\$1 FOR UPDATE").WithArgs(9).WillReturnRows(sqlmock.NewRows([]string{"name", "start", "end"}))
+	mock.ExpectRollback()
+
+	rr = httptest.NewRecorder()
+	handler.DeleteTerm(rr, routeRequest(t, "DELETE", "/api/term/9", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetCourseRosterTerm tests reading a past term's roster and refusing
+// bad terms.
+func TestGetCourseRosterTerm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	roster := func(term string) *http.Request {
+		req := routeRequest(t, "GET", "/api/course/1/roster", nil, "id", "1")
+		req.URL.RawQuery = "term=" + term
+		return req
+	}
+
+	expectCourseExists(mock, 1, true)
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(1).
+		WillReturnRows(storetest.TermRows(store.Term{ID: 1, Name: "Fall 2025", Start: "2025-09-01", End: "2025-12-19"}))
+	mock.ExpectQuery("SELECT p.id, p.first_name, p.last_name, p.type, p.age, pc.role FROM person p JOIN person_course pc .* AND pc.term_id = \\$2").
+		WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age", "role"}).AddRow(3, "Ada", "Lovelace", "student", 36, "student"))
+
+	rr := httptest.NewRecorder()
+	handler.GetCourseRoster(rr, roster("1"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"id":3,"first_name":"Ada","last_name":"Lovelace","type":"student","age":36,"role":"student"}]`, rr.Body.String())
+
+	expectCourseExists(mock, 1, true)
+	rr = httptest.NewRecorder()
+	handler.GetCourseRoster(rr, roster("fall"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Invalid term: fall\n", rr.Body.String())
+
+	expectCourseExists(mock, 1, true)
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(9).WillReturnRows(storetest.TermRows())
+	rr = httptest.NewRecorder()
+	handler.GetCourseRoster(rr, roster("9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Term not found\n", rr.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,250 @@
+// handlers for student transcripts rendered as JSON, printable HTML or PDF
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/pdf"
+)
+
+const (
+	transcriptJSON = "json"
+	transcriptHTML = "html"
+	transcriptPDF  = "pdf"
+
+	// longest course name that fits its column in the PDF
+	transcriptNameWidth = 60
+)
+
+// Pick the transcript format from the format query param, falling back to
+// the Accept header. JSON is used when the client expresses no preference.
+func transcriptFormat(r *http.Request) (string, bool) {
+	switch format := strings.ToLower(r.URL.Query().Get("format")); format {
+	case transcriptJSON, transcriptHTML, transcriptPDF:
+		return format, true
+	case "":
+	default:
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return transcriptJSON, true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case MediaJSON, "*/*", "application/*":
+			return transcriptJSON, true
+		case "text/html", "text/*":
+			return transcriptHTML, true
+		case "application/pdf":
+			return transcriptPDF, true
+		}
+	}
+	return "", false
+}
+
+// Get every course a student took by term with their grades, credits and
+// GPAs, plus totals. Professors have no transcript.
+func (h *RequestHandler) GetPersonTranscript(w http.ResponseWriter, r *http.Request) {
+	format, ok := transcriptFormat(r)
+	if !ok {
+		http.Error(w, "Unsupported transcript format, use json, html or pdf", http.StatusNotAcceptable)
+		return
+	}
+	personID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || personID < 1 {
+		http.Error(w, "Invalid person ID: "+chi.URLParam(r, "id"), http.StatusBadRequest)
+		return
+	}
+
+	transcript, err := h.store().Transcript(r.Context(), uint(personID))
+	if err != nil {
+		storeFailed(w, err, "querying transcript")
+		return
+	}
+
+	body := Transcript{
+		Person: Person(transcript.Person),
+		Terms:  make([]TranscriptTerm, len(transcript.Terms)),
+		Totals: TranscriptTotals(transcript.Totals),
+	}
+	for i, term := range transcript.Terms {
+		courses := make([]TranscriptCourse, len(term.Courses))
+		for j, course := range term.Courses {
+			courses[j] = TranscriptCourse(course)
+		}
+		body.Terms[i] = TranscriptTerm{
+			TermID:        term.TermID,
+			Term:          term.Term,
+			Start:         term.Start,
+			End:           term.End,
+			Courses:       courses,
+			Credits:       term.Credits,
+			GradedCredits: term.GradedCredits,
+			GPA:           term.GPA,
+		}
+	}
+
+	// html and pdf are built in memory so a failure can still be a 500
+	var buf bytes.Buffer
+	filename := fmt.Sprintf("transcript-%d", personID)
+	switch format {
+	case transcriptJSON:
+		w.Header().Set("Content-Type", MediaJSON)
+		err = json.NewEncoder(&buf).Encode(body)
+	case transcriptHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = transcriptPage.Execute(&buf, transcriptView{Transcript: body, Issued: time.Now().Format("2006-01-02")})
+	case transcriptPDF:
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `inline; filename="`+filename+`.pdf"`)
+		_, err = transcriptDocument(body, time.Now()).WriteTo(&buf)
+	}
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "Error rendering transcript: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// Format a GPA with two decimals, or a dash without graded courses.
+func gpaText(gpa *float64) string {
+	if gpa == nil {
+		return "—"
+	}
+	return strconv.FormatFloat(*gpa, 'f', 2, 64)
+}
+
+type transcriptView struct {
+	Transcript
+	Issued string // YYYY-MM-DD
+}
+
+// printable transcript page, styled inline so it needs nothing else
+var transcriptPage = template.Must(template.New("transcript").Funcs(template.FuncMap{"gpa": gpaText}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <title>Transcript - {{.Person.FirstName}} {{.Person.LastName}}</title>
+  <meta charset="utf-8">
+  <style>
+    body { font-family: Helvetica, Arial, sans-serif; max-width: 48em; margin: 2em auto; color: #111; }
+    table { width: 100%; border-collapse: collapse; margin-bottom: 1.5em; }
+    th, td { text-align: left; padding: 0.25em 0.5em; border-bottom: 1px solid #ccc; }
+    td.n, th.n { text-align: right; }
+    tfoot td { font-weight: bold; border-bottom: none; }
+    @media print { body { margin: 0; } h2 { break-after: avoid; } }
+  </style>
+</head>
+<body>
+  <h1>Official Transcript</h1>
+  <p>{{.Person.FirstName}} {{.Person.LastName}}, student {{.Person.ID}}<br>Issued {{.Issued}}</p>
+{{- range .Terms}}
+  <h2>{{.Term}} <small>{{.Start}} to {{.End}}</small></h2>
+  <table>
+    <thead><tr><th>Course</th><th class="n">Credits</th><th>Grade</th><th class="n">Points</th></tr></thead>
+    <tbody>
+    {{- range .Courses}}
+      <tr><td>{{.Name}}</td><td class="n">{{.Credits}}</td><td>{{with .Grade}}{{.}}{{else}}—{{end}}</td><td class="n">{{gpa .Points}}</td></tr>
+    {{- end}}
+    </tbody>
+    <tfoot><tr><td>Term GPA {{gpa .GPA}}</td><td class="n">{{.Credits}}</td><td colspan="2">{{.GradedCredits}} graded</td></tr></tfoot>
+  </table>
+{{- else}}
+  <p>No courses taken.</p>
+{{- end}}
+  <h2>Totals</h2>
+  <p>{{.Totals.Courses}} courses, {{.Totals.Credits}} credits, {{.Totals.GradedCredits}} graded, cumulative GPA {{gpa .Totals.GPA}}</p>
+</body>
+</html>
+`))
+
+// Lay out transcript as a PDF, continuing on a new page whenever a line
+// would fall into the bottom margin.
+func transcriptDocument(transcript Transcript, issued time.Time) *pdf.Document {
+	name := transcript.Person.FirstName + " " + transcript.Person.LastName
+	doc := pdf.New("Transcript - " + name)
+
+	// left edges of the columns and the right edge of the rules
+	const course, credits, grade, points, right = pdf.Margin, 380, 440, 500, pdf.PageWidth - pdf.Margin
+	const lineHeight = 14
+
+	page := doc.AddPage()
+	y := float64(pdf.PageHeight - pdf.Margin)
+	page.Text(course, y, pdf.HelveticaBold, 18, "Official Transcript")
+	y -= 22
+	page.Text(course, y, pdf.Helvetica, 10, fmt.Sprintf("%s, student %d", name, transcript.Person.ID))
+	page.Text(points, y, pdf.Helvetica, 10, "Issued "+issued.Format("2006-01-02"))
+	y -= 8
+	page.Line(course, y, right, y, 0.5)
+	y -= 24
+
+	// continue on a new page unless that many more lines fit on this one
+	need := func(lines int) {
+		if y-float64(lines*lineHeight) >= pdf.Margin {
+			return
+		}
+		page = doc.AddPage()
+		y = pdf.PageHeight - pdf.Margin
+		page.Text(course, y, pdf.Helvetica, 9, fmt.Sprintf("%s, student %d, page %d", name, transcript.Person.ID, doc.Pages()))
+		y -= 24
+	}
+
+	for _, term := range transcript.Terms {
+		need(4)
+		page.Text(course, y, pdf.HelveticaBold, 12, term.Term)
+		page.Text(points-60, y, pdf.Helvetica, 9, term.Start+" to "+term.End)
+		y -= lineHeight + 2
+		page.Text(course, y, pdf.HelveticaBold, 9, "Course")
+		page.Text(credits, y, pdf.HelveticaBold, 9, "Credits")
+		page.Text(grade, y, pdf.HelveticaBold, 9, "Grade")
+		page.Text(points, y, pdf.HelveticaBold, 9, "Points")
+		y -= 4
+		page.Line(course, y, right, y, 0.25)
+		y -= lineHeight - 2
+
+		for _, c := range term.Courses {
+			need(1)
+			letter := c.Grade
+			if letter == "" {
+				letter = "—"
+			}
+			page.Text(course, y, pdf.Helvetica, 10, truncate(c.Name, transcriptNameWidth))
+			page.Text(credits, y, pdf.Helvetica, 10, strconv.FormatUint(uint64(c.Credits), 10))
+			page.Text(grade, y, pdf.Helvetica, 10, letter)
+			page.Text(points, y, pdf.Helvetica, 10, gpaText(c.Points))
+			y -= lineHeight
+		}
+
+		need(1)
+		page.Text(course, y, pdf.HelveticaBold, 10, fmt.Sprintf("Term GPA %s, %d of %d credits graded", gpaText(term.GPA), term.GradedCredits, term.Credits))
+		y -= 2 * lineHeight
+	}
+
+	need(3)
+	page.Line(course, y+lineHeight-2, right, y+lineHeight-2, 0.5)
+	page.Text(course, y, pdf.HelveticaBold, 12, "Totals")
+	y -= lineHeight + 2
+	page.Text(course, y, pdf.Helvetica, 10, fmt.Sprintf("%d courses, %d credits, %d graded, cumulative GPA %s",
+		transcript.Totals.Courses, transcript.Totals.Credits, transcript.Totals.GradedCredits, gpaText(transcript.Totals.GPA)))
+	return doc
+}
+
+// Shorten s to at most n characters, ending in an ellipsis when cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
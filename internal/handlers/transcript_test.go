@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	transcriptPersonQuery = "SELECT id, first_name, last_name, type, age FROM person WHERE id = \\$1"
+	transcriptCourseQuery = "SELECT t.id, t.name, .* FROM person_course pc .* WHERE pc.person_id = \\$1 AND pc.role = 'student' ORDER BY t.start_date, c.name, c.id"
+	transcriptGPAQuery    = "SELECT t.id, t.name, COALESCE\\(SUM\\(COALESCE\\(c.credits, 1\\)\\), 0\\), .* GROUP BY ROLLUP"
+)
+
+// Expect the queries for the transcript of student 3, who was graded in
+// fall and is still taking a course in spring.
+func expectTranscript(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(transcriptPersonQuery).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).
+			AddRow(3, "José", "<Script>", "student", 20))
+	mock.ExpectQuery(transcriptCourseQuery).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end", "course_id", "course", "credits", "grade", "points"}).
+			AddRow(1, "Fall 2025", "2025-09-01", "2025-12-19", 4, "Algebra", 4, "A", 4.0).
+			AddRow(1, "Fall 2025", "2025-09-01", "2025-12-19", 2, "Biology", 3, "B+", 3.3).
+			AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15", 5, "Chemistry", 1, "", nil))
+	mock.ExpectQuery(transcriptGPAQuery).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "credits", "gpa"}).
+			AddRow(1, "Fall 2025", 7, 3.7).
+			AddRow(nil, nil, 7, 3.7))
+}
+
+// TestGetPersonTranscript tests the JSON transcript and the errors.
+func TestGetPersonTranscript(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	expectTranscript(mock)
+	rr := httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, routeRequest(t, "GET", "/api/person/3/transcript", nil, "id", "3"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"person":{"id":3,"first_name":"José","last_name":"<Script>","type":"student","age":20},"terms":[
+		{"term_id":1,"term":"Fall 2025","start":"2025-09-01","end":"2025-12-19","courses":[
+			{"course_id":4,"name":"Algebra","credits":4,"grade":"A","points":4},
+			{"course_id":2,"name":"Biology","credits":3,"grade":"B+","points":3.3}
+		],"credits":7,"graded_credits":7,"gpa":3.7},
+		{"term_id":2,"term":"Spring 2026","start":"2026-01-12","end":"2026-05-15","courses":[
+			{"course_id":5,"name":"Chemistry","credits":1,"points":null}
+		],"credits":1,"graded_credits":0,"gpa":null}
+	],"totals":{"courses":3,"credits":8,"graded_credits":7,"gpa":3.7}}`, rr.Body.String())
+
+	// professors have no transcript
+	mock.ExpectQuery(transcriptPersonQuery).WithArgs(6).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).
+			AddRow(6, "Ada", "Byron", "professor", 36))
+	rr = httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, routeRequest(t, "GET", "/api/person/6/transcript", nil, "id", "6"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Only students have transcripts, person 6 is a professor\n", rr.Body.String())
+
+	mock.ExpectQuery(transcriptPersonQuery).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}))
+	rr = httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, routeRequest(t, "GET", "/api/person/9/transcript", nil, "id", "9"))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "Person not found\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, routeRequest(t, "GET", "/api/person/john/transcript", nil, "id", "john"))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "Invalid person ID: john\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, routeRequest(t, "GET", "/api/person/3/transcript?format=xml", nil, "id", "3"))
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetPersonTranscriptHTML tests that the printable page lists every
+// course and escapes what it shows.
+func TestGetPersonTranscriptHTML(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	expectTranscript(mock)
+	req := routeRequest(t, "GET", "/api/person/3/transcript", nil, "id", "3")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	rr := httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	body := rr.Body.String()
+	assert.Contains(t, body, "José &lt;Script&gt;, student 3")
+	assert.NotContains(t, body, "<Script>")
+	assert.Contains(t, body, "<h2>Fall 2025 <small>2025-09-01 to 2025-12-19</small></h2>")
+	assert.Contains(t, body, `<tr><td>Biology</td><td class="n">3</td><td>B&#43;</td><td class="n">3.30</td></tr>`)
+	assert.Contains(t, body, `<tr><td>Chemistry</td><td class="n">1</td><td>—</td><td class="n">—</td></tr>`)
+	assert.Contains(t, body, "3 courses, 8 credits, 7 graded, cumulative GPA 3.70")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetPersonTranscriptPDF tests the PDF transcript.
+func TestGetPersonTranscriptPDF(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+	expectTranscript(mock)
+	rr := httptest.NewRecorder()
+	handler.GetPersonTranscript(rr, routeRequest(t, "GET", "/api/person/3/transcript?format=pdf", nil, "id", "3"))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `inline; filename="transcript-3.pdf"`, rr.Header().Get("Content-Disposition"))
+
+	body := rr.Body.String()
+	assert.True(t, strings.HasPrefix(body, "%PDF-1.4\n"))
+	assert.Contains(t, body, "/Title (Transcript - Jos\\351 <Script>)")
+	assert.Contains(t, body, "(B+) Tj")
+	assert.Contains(t, body, "(Term GPA 3.70, 7 of 7 credits graded) Tj")
+	assert.Contains(t, body, "(Term GPA \\227, 0 of 1 credits graded) Tj")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTranscriptDocumentPages tests that long transcripts continue on more
+// pages and long course names are cut to fit.
+func TestTranscriptDocumentPages(t *testing.T) {
+	transcript := Transcript{Person: Person{ID: 3, FirstName: "Ann", LastName: "Lee", Type: "student"}}
+	for i := 0; i < 12; i++ {
+		term := TranscriptTerm{TermID: uint(i + 1), Term: "Term", Start: "2025-01-01", End: "2025-05-01"}
+		for j := 0; j < 5; j++ {
+			term.Courses = append(term.Courses, TranscriptCourse{CourseID: uint(j), Name: strings.Repeat("x", 80), Credits: 1})
+		}
+		transcript.Terms = append(transcript.Terms, term)
+	}
+
+	doc := transcriptDocument(transcript, time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 3, doc.Pages())
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "(Issued 2026-05-20) Tj")
+	assert.Contains(t, buf.String(), "(Ann Lee, student 3, page 3) Tj")
+	assert.Contains(t, buf.String(), "("+strings.Repeat("x", 59)+"\\205) Tj")
+}
+
+// TestTranscriptFormat tests picking the format from the query and Accept
+// header.
+func TestTranscriptFormat(t *testing.T) {
+	tests := []struct {
+		query, accept, format string
+		ok                    bool
+	}{
+		{"", "", transcriptJSON, true},
+		{"", "application/pdf", transcriptPDF, true},
+		{"", "text/html;q=0.9", transcriptHTML, true},
+		{"", "*/*", transcriptJSON, true},
+		{"PDF", "text/html", transcriptPDF, true},
+		{"csv", "", "", false},
+		{"", "application/xml", "", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/person/3/transcript?format="+tt.query, nil)
+		req.Header.Set("Accept", tt.accept)
+		format, ok := transcriptFormat(req)
+		assert.Equal(t, tt.format, format, "format=%s Accept: %s", tt.query, tt.accept)
+		assert.Equal(t, tt.ok, ok, "format=%s Accept: %s", tt.query, tt.accept)
+	}
+}
@@ -0,0 +1,288 @@
+// admin handlers for webhook subscriptions and their delivery log
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/webhook"
+)
+
+// Check a subscription request, writing the error response and returning
+// false when it's invalid.
+func validWebhook(w http.ResponseWriter, newWebhook *NewWebhook) bool {
+	newWebhook.URL = strings.TrimSpace(newWebhook.URL)
+	u, err := url.Parse(newWebhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		http.Error(w, "Webhook url must be an absolute http or https URL", http.StatusBadRequest)
+		return false
+	}
+	for _, eventType := range newWebhook.Events {
+		if !store.IsEventType(eventType) {
+			http.Error(w, "Unknown event type: "+eventType, http.StatusBadRequest)
+			return false
+		}
+	}
+	if newWebhook.Events == nil {
+		newWebhook.Events = []string{}
+	}
+	return true
+}
+
+// Split the comma separated event types selected by the queries below.
+func splitEvents(events string) []string {
+	if events == "" {
+		return []string{}
+	}
+	return strings.Split(events, ",")
+}
+
+// Return all webhook subscriptions without their secrets.
+func (h *RequestHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks := []Webhook{}
+
+	rows, err := h.DB.QueryContext(r.Context(),
+		"SELECT id, url, array_to_string(event_types, ','), active, created_at FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		http.Error(w, "Error querying webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hook Webhook
+		var events string
+		if err := rows.Scan(&hook.ID, &hook.URL, &events, &hook.Active, &hook.CreatedAt); err != nil {
+			http.Error(w, "Error scanning webhook data: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		hook.Events = splitEvents(events)
+		webhooks = append(webhooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, "Error iterating over webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, webhooks)
+}
+
+// Subscribe a URL to events. The signing secret is only included in this
+// response.
+func (h *RequestHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var newWebhook NewWebhook
+	if err := decode(r, &newWebhook); err != nil {
+		decodeError(w, err)
+		return
+	}
+	if !validWebhook(w, &newWebhook) {
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		http.Error(w, "Error generating webhook secret: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hook := Webhook{URL: newWebhook.URL, Events: newWebhook.Events, Active: true, Secret: secret}
+	if newWebhook.Active != nil {
+		hook.Active = *newWebhook.Active
+	}
+	err = h.DB.QueryRowContext(r.Context(), `
+        INSERT INTO webhook_subscriptions (url, secret, event_types, active)
+        VALUES ($1, $2, string_to_array($3, ','), $4)
+        RETURNING id, created_at
+    `, hook.URL, secret, strings.Join(hook.Events, ","), hook.Active).Scan(&hook.ID, &hook.CreatedAt)
+	if err != nil {
+		http.Error(w, "Error creating webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusCreated, hook)
+}
+
+// Replace a subscription's url, event types and active flag. The secret is
+// kept.
+func (h *RequestHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	intID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid webhook ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var newWebhook NewWebhook
+	if err := decode(r, &newWebhook); err != nil {
+		decodeError(w, err)
+		return
+	}
+	if !validWebhook(w, &newWebhook) {
+		return
+	}
+
+	hook := Webhook{ID: uint(intID), URL: newWebhook.URL, Events: newWebhook.Events, Active: true}
+	if newWebhook.Active != nil {
+		hook.Active = *newWebhook.Active
+	}
+	err = h.DB.QueryRowContext(r.Context(), `
+        UPDATE webhook_subscriptions
+        SET url = $1, event_types = string_to_array($2, ','), active = $3
+        WHERE id = $4
+        RETURNING created_at
+    `, hook.URL, strings.Join(hook.Events, ","), hook.Active, intID).Scan(&hook.CreatedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, hook)
+}
+
+// Delete a subscription and its delivery log.
+func (h *RequestHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	intID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid webhook ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.DB.ExecContext(r.Context(), "DELETE FROM webhook_subscriptions WHERE id = $1", intID)
+	if err != nil {
+		http.Error(w, "Error deleting webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Return a subscription's deliveries, newest first. Takes status (pending,
+// delivered or dead), limit and offset querys.
+func (h *RequestHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	intID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid webhook ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var exists bool
+	err = h.DB.QueryRowContext(r.Context(), "SELECT EXISTS(SELECT 1 FROM webhook_subscriptions WHERE id = $1)", intID).Scan(&exists)
+	if err != nil {
+		http.Error(w, "Error checking webhook existence: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	query := `
+        SELECT d.id, d.event_id, e.type, d.status, d.attempts, d.next_attempt_at,
+               d.last_status_code, d.last_error, d.created_at, d.delivered_at
+        FROM webhook_deliveries d
+        JOIN events e ON e.id = d.event_id
+        WHERE d.subscription_id = $1`
+	args := []interface{}{intID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		args = append(args, status)
+		query += " AND d.status = $2"
+	}
+	query += " ORDER BY d.id DESC"
+
+	// page newest first
+	page, err := queryPage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, args := page.LimitClause(args)
+	query += limit
+
+	rows, err := h.DB.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		http.Error(w, "Error querying deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var delivery WebhookDelivery
+		var nextAttemptAt sql.NullTime
+		var lastStatusCode sql.NullInt64
+		err := rows.Scan(&delivery.ID, &delivery.EventID, &delivery.EventType, &delivery.Status, &delivery.Attempts,
+			&nextAttemptAt, &lastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt)
+		if err != nil {
+			http.Error(w, "Error scanning delivery data: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if delivery.Status == webhook.StatusPending && nextAttemptAt.Valid {
+			delivery.NextAttemptAt = &nextAttemptAt.Time
+		}
+		if lastStatusCode.Valid {
+			code := int(lastStatusCode.Int64)
+			delivery.LastStatusCode = &code
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, "Error iterating over deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, deliveries)
+}
+
+// Queue a dead delivery to be sent again with a fresh set of attempts.
+func (h *RequestHandler) RetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	intID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid webhook ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "deliveryID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid delivery ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var status string
+	err = h.DB.QueryRowContext(r.Context(),
+		"SELECT status FROM webhook_deliveries WHERE id = $1 AND subscription_id = $2", deliveryID, intID).Scan(&status)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Delivery not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error finding delivery: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if status != webhook.StatusDead {
+		http.Error(w, "Only dead deliveries can be retried, this one is "+status, http.StatusConflict)
+		return
+	}
+
+	_, err = h.DB.ExecContext(r.Context(), `
+        UPDATE webhook_deliveries
+        SET status = 'pending', attempts = 0, next_attempt_at = now()
+        WHERE id = $1 AND status = 'dead'
+    `, deliveryID)
+	if err != nil {
+		http.Error(w, "Error retrying delivery: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, Message{Message: "Delivery queued for retry"})
+}
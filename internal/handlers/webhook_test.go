@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateWebhook tests subscribing a url and validating the request.
+func TestCreateWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("INSERT INTO webhook_subscriptions \\(url, secret, event_types, active\\)").
+		WithArgs("https://lms.example.com/hooks", sqlmock.AnyArg(), "enrollment.added,enrollment.removed", true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	tests := []struct {
+		name         string
+		body         NewWebhook
+		expectedCode int
+		expectedBody string
+	}{
+		{"valid", NewWebhook{URL: "https://lms.example.com/hooks", Events: []string{"enrollment.added", "enrollment.removed"}}, http.StatusCreated, ""},
+		{"unknown event", NewWebhook{URL: "https://lms.example.com/hooks", Events: []string{"course.renamed"}}, http.StatusBadRequest, "Unknown event type: course.renamed\n"},
+		{"relative url", NewWebhook{URL: "/hooks"}, http.StatusBadRequest, "Webhook url must be an absolute http or https URL\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req, err := http.NewRequest("POST", "/api/admin/webhooks", bytes.NewBuffer(body))
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler.CreateWebhook(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rr.Body.String())
+				return
+			}
+			var hook Webhook
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&hook))
+			assert.Equal(t, uint(1), hook.ID)
+			assert.True(t, hook.Active)
+			assert.Regexp(t, "^whsec_[0-9a-f]{64}$", hook.Secret)
+		})
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetWebhookDeliveries tests reading the delivery log.
+func TestGetWebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM webhook_subscriptions WHERE id = \\$1\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("FROM webhook_deliveries d JOIN events e ON e.id = d.event_id WHERE d.subscription_id = \\$1 AND d.status = \\$2 ORDER BY d.id DESC LIMIT \\$3$").
+		WithArgs(1, "dead", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "event_id", "type", "status", "attempts", "next_attempt_at", "last_status_code", "last_error", "created_at", "delivered_at"}).
+			AddRow(7, 12, "enrollment.added", "dead", 10, now, 503, "unexpected response status 503", now, nil))
+
+	req, err := http.NewRequest("GET", "/api/admin/webhooks/1/deliveries?status=dead&limit=10", nil)
+	assert.NoError(t, err)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr := httptest.NewRecorder()
+	handler.GetWebhookDeliveries(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var deliveries []WebhookDelivery
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&deliveries))
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, "enrollment.added", deliveries[0].EventType)
+	assert.Equal(t, 503, *deliveries[0].LastStatusCode)
+	assert.Nil(t, deliveries[0].NextAttemptAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestRetryWebhookDelivery tests requeueing only dead deliveries.
+func TestRetryWebhookDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &RequestHandler{DB: db}
+
+	mock.ExpectQuery("SELECT status FROM webhook_deliveries WHERE id = \\$1 AND subscription_id = \\$2").WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("dead"))
+	mock.ExpectExec("UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = now\\(\\)").WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT status FROM webhook_deliveries").WithArgs(8, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("delivered"))
+	mock.ExpectQuery("SELECT status FROM webhook_deliveries").WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+
+	for _, tt := range []struct {
+		deliveryID   string
+		expectedCode int
+	}{{"7", http.StatusOK}, {"8", http.StatusConflict}, {"9", http.StatusNotFound}} {
+		req, err := http.NewRequest("POST", "/api/admin/webhooks/1/deliveries/"+tt.deliveryID+"/retry", nil)
+		assert.NoError(t, err)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "1")
+		rctx.URLParams.Add("deliveryID", tt.deliveryID)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		rr := httptest.NewRecorder()
+		handler.RetryWebhookDelivery(rr, req)
+		assert.Equal(t, tt.expectedCode, rr.Code, tt.deliveryID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
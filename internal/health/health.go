@@ -0,0 +1,128 @@
+// liveness and readiness endpoints for the orchestrator
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/database"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Component is the result of one check.
+type Component struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Version   int     `json:"version,omitempty"`
+	Expected  int     `json:"expected,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the body of both endpoints.
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components"`
+}
+
+// Checker answers /healthz and /readyz.
+type Checker struct {
+	DB            *sql.DB
+	SchemaVersion int           // version the server was built for
+	Timeout       time.Duration // per check, defaults to 2s
+
+	shuttingDown atomic.Bool
+}
+
+// NewChecker returns a checker for db expecting database.SchemaVersion.
+func NewChecker(db *sql.DB) *Checker {
+	return &Checker{DB: db, SchemaVersion: database.SchemaVersion, Timeout: 2 * time.Second}
+}
+
+// ShutDown marks the server as draining so /readyz fails and the
+// orchestrator stops routing new requests to it.
+func (c *Checker) ShutDown() {
+	c.shuttingDown.Store(true)
+}
+
+func write(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	for _, component := range report.Components {
+		if component.Status != StatusOK {
+			report.Status = StatusFail
+			status = http.StatusServiceUnavailable
+		}
+	}
+	if report.Status == "" {
+		report.Status = StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Liveness reports that the process is up and serving requests. It never
+// touches the database so a database outage doesn't restart the server.
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	write(w, Report{Components: map[string]Component{
+		"process": {Status: StatusOK},
+	}})
+}
+
+// Readiness pings the database, checks the seeded schema version and fails
+// while the server is shutting down.
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeout())
+	defer cancel()
+
+	report := Report{Components: map[string]Component{
+		"database":   c.checkDatabase(ctx),
+		"migrations": c.checkSchema(ctx),
+		"shutdown":   {Status: StatusOK},
+	}}
+	if c.shuttingDown.Load() {
+		report.Components["shutdown"] = Component{Status: StatusFail, Error: "server is shutting down"}
+	}
+	write(w, report)
+}
+
+func (c *Checker) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 2 * time.Second
+	}
+	return c.Timeout
+}
+
+func (c *Checker) checkDatabase(ctx context.Context) Component {
+	start := time.Now()
+	err := c.DB.PingContext(ctx)
+	component := Component{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		component.Status = StatusFail
+		component.Error = err.Error()
+	}
+	return component
+}
+
+func (c *Checker) checkSchema(ctx context.Context) Component {
+	version, err := database.AppliedSchemaVersion(ctx, c.DB)
+	component := Component{Status: StatusOK, Version: version, Expected: c.SchemaVersion}
+	if err != nil {
+		component.Status = StatusFail
+		component.Error = err.Error()
+	} else if version != c.SchemaVersion {
+		component.Status = StatusFail
+		component.Error = fmt.Sprintf("schema version %d does not match expected %d", version, c.SchemaVersion)
+	}
+	return component
+}
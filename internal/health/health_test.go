@@ -0,0 +1,81 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeReport(t *testing.T, rr *httptest.ResponseRecorder) Report {
+	var report Report
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&report))
+	return report
+}
+
+// TestLiveness tests that liveness never needs the database.
+func TestLiveness(t *testing.T) {
+	checker := &Checker{}
+	rr := httptest.NewRecorder()
+	checker.Liveness(rr, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	report := decodeReport(t, rr)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, StatusOK, report.Components["process"].Status)
+}
+
+// TestReadiness tests the database, schema version and shutdown checks.
+func TestReadiness(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	defer db.Close()
+	checker := NewChecker(db)
+	checker.SchemaVersion = 3
+
+	ready := func() (int, Report) {
+		rr := httptest.NewRecorder()
+		checker.Readiness(rr, httptest.NewRequest("GET", "/readyz", nil))
+		return rr.Code, decodeReport(t, rr)
+	}
+
+	// ready
+	mock.ExpectPing()
+	mock.ExpectQuery("SELECT version FROM schema_version").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	status, report := ready()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, 3, report.Components["migrations"].Version)
+
+	// database down
+	mock.ExpectPing().WillReturnError(assert.AnError)
+	mock.ExpectQuery("SELECT version FROM schema_version").WillReturnError(assert.AnError)
+	status, report = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusFail, report.Components["database"].Status)
+	assert.NotEmpty(t, report.Components["database"].Error)
+
+	// stale schema
+	mock.ExpectPing()
+	mock.ExpectQuery("SELECT version FROM schema_version").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	status, report = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, StatusOK, report.Components["database"].Status)
+	assert.Equal(t, StatusFail, report.Components["migrations"].Status)
+	assert.Equal(t, 3, report.Components["migrations"].Expected)
+
+	// shutting down
+	checker.ShutDown()
+	mock.ExpectPing()
+	mock.ExpectQuery("SELECT version FROM schema_version").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	status, report = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, StatusFail, report.Components["shutdown"].Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,128 @@
+// structured json logging and request-scoped loggers
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestIDHeader carries the request id on requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+// ParseLevel converts debug, info, warn or error to a slog level. An empty
+// string means info.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// New returns a logger writing json lines at or above level to w.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+type loggerKey struct{}
+
+type entryKey struct{}
+
+// entry collects attributes added while a request is handled so they can
+// be written with the access log line.
+type entry struct {
+	attrs []any
+}
+
+// FromContext returns the request-scoped logger, or the default logger when
+// ctx doesn't carry one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With adds attributes to the request-scoped logger and to the request's
+// access log line, returning the updated context.
+func With(ctx context.Context, args ...any) context.Context {
+	if e, ok := ctx.Value(entryKey{}).(*entry); ok {
+		e.attrs = append(e.attrs, args...)
+	}
+	return context.WithValue(ctx, loggerKey{}, FromContext(ctx).With(args...))
+}
+
+// Use an incoming request id when it is short and printable, otherwise
+// generate a new one.
+func requestID(r *http.Request) string {
+	id := r.Header.Get(RequestIDHeader)
+	if id != "" && len(id) <= 128 && !strings.ContainsFunc(id, func(c rune) bool { return c < '!' || c > '~' }) {
+		return id
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Middleware gives every request an id and a logger carrying it, and writes
+// one access log line per request with the route pattern, status, latency
+// and anything added with With. 5xx responses are logged as errors and 4xx
+// as warnings.
+func Middleware(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := requestID(r)
+			w.Header().Set(RequestIDHeader, id)
+
+			logger := base.With("request_id", id)
+			e := &entry{}
+			ctx := context.WithValue(r.Context(), loggerKey{}, logger)
+			ctx = context.WithValue(ctx, entryKey{}, e)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := ""
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			attrs := append([]any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", route,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+				"remote_addr", r.RemoteAddr,
+			}, e.attrs...)
+			logger.Log(r.Context(), level, "request", attrs...)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// Decode the json lines written to buf.
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// TestMiddleware tests request ids, the request-scoped logger and the
+// access log line.
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	r := chi.NewRouter()
+	r.Use(Middleware(New(&buf, slog.LevelInfo)))
+	r.Get("/api/course/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := With(r.Context(), "principal", "jdoe")
+		FromContext(ctx).Info("handling")
+		http.Error(w, "Course not found", http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest("GET", "/api/course/7", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, "abc-123", rr.Header().Get(RequestIDHeader))
+	lines := logLines(t, &buf)
+	assert.Len(t, lines, 2)
+
+	assert.Equal(t, "handling", lines[0]["msg"])
+	assert.Equal(t, "abc-123", lines[0]["request_id"])
+	assert.Equal(t, "jdoe", lines[0]["principal"])
+
+	access := lines[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "WARN", access["level"])
+	assert.Equal(t, "abc-123", access["request_id"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/api/course/{id}", access["route"])
+	assert.Equal(t, float64(http.StatusNotFound), access["status"])
+	assert.Equal(t, "jdoe", access["principal"])
+	assert.Contains(t, access, "latency_ms")
+
+	// unusable incoming ids are replaced
+	buf.Reset()
+	req = httptest.NewRequest("GET", "/api/course/7", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Len(t, rr.Header().Get(RequestIDHeader), 32)
+}
+
+// TestLevels tests level parsing and filtering.
+func TestLevels(t *testing.T) {
+	level, err := ParseLevel("")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+
+	level, err = ParseLevel("warn")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("loud")
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelWarn)
+	logger.Info("hidden")
+	logger.Warn("shown")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown")
+
+	// without the middleware the default logger is used
+	assert.Equal(t, slog.Default(), FromContext(httptest.NewRequest("GET", "/", nil).Context()))
+}
@@ -0,0 +1,91 @@
+// gauges computed from the college tables on every scrape
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	peopleDesc = prometheus.NewDesc("college_people",
+		"People in the person table, by type.", []string{"type"}, nil)
+	enrollmentsDesc = prometheus.NewDesc("college_course_enrollments",
+		"People on each course's roster in the current term.", []string{"course_id", "course"}, nil)
+	scrapeErrorDesc = prometheus.NewDesc("college_scrape_error",
+		"1 if the last query for the college gauges failed.", nil, nil)
+)
+
+// collegeCollector queries the current counts when prometheus scrapes, so
+// the gauges never drift from the tables.
+type collegeCollector struct {
+	db *sql.DB
+}
+
+func (c *collegeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- peopleDesc
+	ch <- enrollmentsDesc
+	ch <- scrapeErrorDesc
+}
+
+func (c *collegeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	failed := 0.0
+	if err := c.collectPeople(ctx, ch); err != nil {
+		slog.Error("Error collecting people metrics", "error", err)
+		failed = 1
+	}
+	if err := c.collectEnrollments(ctx, ch); err != nil {
+		slog.Error("Error collecting enrollment metrics", "error", err)
+		failed = 1
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, failed)
+}
+
+func (c *collegeCollector) collectPeople(ctx context.Context, ch chan<- prometheus.Metric) error {
+	rows, err := c.db.QueryContext(ctx, "SELECT type, count(*) FROM person GROUP BY type")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var personType string
+		var count float64
+		if err := rows.Scan(&personType, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(peopleDesc, prometheus.GaugeValue, count, personType)
+	}
+	return rows.Err()
+}
+
+func (c *collegeCollector) collectEnrollments(ctx context.Context, ch chan<- prometheus.Metric) error {
+	rows, err := c.db.QueryContext(ctx, `
+        SELECT c.id, c.name, count(pc.person_id)
+        FROM course c
+        LEFT JOIN person_course pc ON pc.course_id = c.id AND pc.term_id = (
+            SELECT id FROM term WHERE start_date <= current_date ORDER BY start_date DESC LIMIT 1)
+        GROUP BY c.id, c.name`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var name string
+		var count float64
+		if err := rows.Scan(&id, &name, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(enrollmentsDesc, prometheus.GaugeValue, count, strconv.Itoa(id), name)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/database"
+)
+
+// TestMiddleware tests that requests are counted by route pattern and status.
+func TestMiddleware(t *testing.T) {
+	m := New()
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/api/course/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "0" {
+			http.Error(w, "Course not found", http.StatusNotFound)
+		}
+	})
+
+	for _, path := range []string{"/api/course/1", "/api/course/2", "/api/course/0", "/nothing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("/api/course/{id}", "GET", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("/api/course/{id}", "GET", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("unmatched", "GET", "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.requestDuration))
+}
+
+// TestObserveQuery tests that statements run on an instrumented DB are timed
+// by statement name.
+func TestObserveQuery(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("metrics-observe-query")
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	m := New()
+	db, err := database.Open(mockDB.Driver(), "metrics-observe-query", m.ObserveQuery)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, name FROM course").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Math"))
+	mock.ExpectExec("DELETE FROM course").WillReturnError(assert.AnError)
+
+	rows, err := db.Query("SELECT id, name FROM course")
+	assert.NoError(t, err)
+	rows.Close()
+	_, err = db.Exec("DELETE FROM course WHERE id = $1", 1)
+	assert.Error(t, err)
+
+	counts := map[string]uint64{}
+	families, err := m.Registry.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "db_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := metric.GetLabel()
+			counts[labels[1].GetValue()+"/"+labels[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, map[string]uint64{"select course/success": 1, "delete course/error": 1}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCollegeCollector tests the gauges queried from the college tables.
+func TestCollegeCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT type, count\\(\\*\\) FROM person GROUP BY type").
+		WillReturnRows(sqlmock.NewRows([]string{"type", "count"}).AddRow("student", 8).AddRow("professor", 2))
+	mock.ExpectQuery("SELECT c.id, c.name, count\\(pc.person_id\\) FROM course c LEFT JOIN person_course pc").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count"}).AddRow(1, "Math", 3).AddRow(2, "Art", 0))
+
+	expected := `
+# HELP college_course_enrollments People on each course's roster in the current term.
+# TYPE college_course_enrollments gauge
+college_course_enrollments{course="Art",course_id="2"} 0
+college_course_enrollments{course="Math",course_id="1"} 3
+# HELP college_people People in the person table, by type.
+# TYPE college_people gauge
+college_people{type="professor"} 2
+college_people{type="student"} 8
+# HELP college_scrape_error 1 if the last query for the college gauges failed.
+# TYPE college_scrape_error gauge
+college_scrape_error 0
+`
+	err = testutil.CollectAndCompare(&collegeCollector{db: db}, strings.NewReader(expected))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
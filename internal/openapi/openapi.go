@@ -0,0 +1,40 @@
+// the openapi document describing every route in routes.GetRoutes
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// Spec is the OpenAPI 3.1 document for the API. Keep it in step with
+// routes.GetRoutes, TestGetRoutesMatchSpec fails when they disagree.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// ServeSpec serves the OpenAPI document.
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(Spec)
+}
+
+// docs page rendering /openapi.json with Redoc
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>College API</title>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="/openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// ServeDocs serves an html page rendering the OpenAPI document.
+func ServeDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSpec tests that the document is OpenAPI 3.1 and every $ref resolves.
+func TestSpec(t *testing.T) {
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(Spec, &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	refs := regexp.MustCompile(`"\$ref": "#/([^"]+)"`).FindAllStringSubmatch(string(Spec), -1)
+	assert.NotEmpty(t, refs)
+	for _, ref := range refs {
+		var node interface{} = doc
+		for _, key := range strings.Split(ref[1], "/") {
+			object, ok := node.(map[string]interface{})
+			if !assert.True(t, ok, ref[1]) {
+				break
+			}
+			node = object[key]
+		}
+		assert.NotNil(t, node, "unresolved $ref #/%s", ref[1])
+	}
+}
+
+// TestServe tests the spec and docs handlers.
+func TestServe(t *testing.T) {
+	rr := httptest.NewRecorder()
+	ServeSpec(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, Spec, rr.Body.Bytes())
+
+	rr = httptest.NewRecorder()
+	ServeDocs(rr, httptest.NewRequest("GET", "/docs", nil))
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rr.Body.String(), `spec-url="/openapi.json"`)
+}
@@ -0,0 +1,375 @@
+// request validation against the schemas in the openapi document
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"github.com/vmihailenco/msgpack/v5"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// largest request body the validator reads
+const maxBodyBytes = 1 << 20
+
+// resource url the document is compiled under
+const specURL = "openapi.json"
+
+var printer = message.NewPrinter(language.English)
+
+// FieldError describes one invalid part of a request. Pointer is a JSON
+// pointer into the body, or the parameter name for path and query params.
+type FieldError struct {
+	In      string `json:"in"` // body, path or query
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// Problem is the RFC 9457 style body of a 400 validation response.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Errors []FieldError `json:"errors"`
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	typ      string // schema type used to convert the raw string
+	schema   *jsonschema.Schema
+}
+
+type operation struct {
+	params []parameter
+	body   *jsonschema.Schema // json body schema, nil when there is no body
+}
+
+// Validator checks requests against the operation matching their chi route
+// pattern before they reach the handlers.
+type Validator struct {
+	operations map[string]*operation // by "METHOD /route/{pattern}"
+}
+
+// Escape a JSON pointer token.
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// NewValidator compiles the parameter and body schemas of every operation
+// in spec.
+func NewValidator(spec []byte) (*Validator, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(spec))
+	if err != nil {
+		return nil, fmt.Errorf("parsing openapi document: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err := compiler.AddResource(specURL, doc); err != nil {
+		return nil, err
+	}
+	compile := func(pointer string) (*jsonschema.Schema, error) {
+		return compiler.Compile(specURL + "#" + pointer)
+	}
+
+	var raw struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Parameters map[string]json.RawMessage `json:"parameters"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(spec, &raw); err != nil {
+		return nil, fmt.Errorf("parsing openapi document: %w", err)
+	}
+
+	type paramObject struct {
+		Ref      string `json:"$ref"`
+		Name     string `json:"name"`
+		In       string `json:"in"`
+		Required bool   `json:"required"`
+		Schema   struct {
+			Type string `json:"type"`
+		} `json:"schema"`
+	}
+
+	// compile the parameters listed at pointer, following $refs
+	params := func(data json.RawMessage, pointer string) ([]parameter, error) {
+		if len(data) == 0 {
+			return nil, nil
+		}
+		var objects []paramObject
+		if err := json.Unmarshal(data, &objects); err != nil {
+			return nil, err
+		}
+		var result []parameter
+		for i, object := range objects {
+			location := pointer + "/" + strconv.Itoa(i)
+			if object.Ref != "" {
+				name := strings.TrimPrefix(object.Ref, "#/components/parameters/")
+				location = "/components/parameters/" + escape(name)
+				object = paramObject{}
+				if err := json.Unmarshal(raw.Components.Parameters[name], &object); err != nil {
+					return nil, fmt.Errorf("parameter %s: %w", object.Ref, err)
+				}
+			}
+			schema, err := compile(location + "/schema")
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, parameter{
+				name:     object.Name,
+				in:       object.In,
+				required: object.Required,
+				typ:      object.Schema.Type,
+				schema:   schema,
+			})
+		}
+		return result, nil
+	}
+
+	v := &Validator{operations: map[string]*operation{}}
+	for path, item := range raw.Paths {
+		pathPointer := "/paths/" + escape(path)
+		shared, err := params(item["parameters"], pathPointer+"/parameters")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for method, data := range item {
+			if method == "parameters" {
+				continue
+			}
+			opPointer := pathPointer + "/" + method
+			var object struct {
+				Parameters  json.RawMessage `json:"parameters"`
+				RequestBody *struct {
+					Content map[string]json.RawMessage `json:"content"`
+				} `json:"requestBody"`
+			}
+			if err := json.Unmarshal(data, &object); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+
+			own, err := params(object.Parameters, opPointer+"/parameters")
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			op := &operation{params: append(append([]parameter{}, shared...), own...)}
+			if object.RequestBody != nil {
+				if _, ok := object.RequestBody.Content["application/json"]; ok {
+					op.body, err = compile(opPointer + "/requestBody/content/application~1json/schema")
+					if err != nil {
+						return nil, fmt.Errorf("%s %s: %w", method, path, err)
+					}
+				}
+			}
+			v.operations[strings.ToUpper(method)+" "+path] = op
+		}
+	}
+	return v, nil
+}
+
+// Convert a raw path or query value to the parameter's schema type.
+func (p parameter) value(raw string) (interface{}, error) {
+	switch p.typ {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("got %q, want integer", raw)
+		}
+		return json.Number(raw), nil
+	case "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, fmt.Errorf("got %q, want number", raw)
+		}
+		return json.Number(raw), nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("got %q, want boolean", raw)
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// Flatten a validation error into one FieldError per failed keyword.
+// Unknown properties are reported at the property's own pointer.
+func fieldErrors(in, prefix string, err error) []FieldError {
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []FieldError{{In: in, Pointer: prefix, Detail: err.Error()}}
+	}
+
+	var errs []FieldError
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		pointer := prefix
+		for _, token := range e.InstanceLocation {
+			pointer += "/" + escape(token)
+		}
+		if additional, ok := e.ErrorKind.(*kind.AdditionalProperties); ok {
+			for _, property := range additional.Properties {
+				errs = append(errs, FieldError{In: in, Pointer: pointer + "/" + escape(property), Detail: "unknown field"})
+			}
+			return
+		}
+		errs = append(errs, FieldError{In: in, Pointer: pointer, Detail: e.ErrorKind.LocalizedString(printer)})
+	}
+	walk(verr)
+	return errs
+}
+
+// Read a json or msgpack body into a value the schema can check. XML and
+// CSV bodies return nil and are left to the handlers' typed decoding, which
+// refuses elements and columns the body type has no field for.
+func bodyValue(r *http.Request, body []byte) (interface{}, bool, error) {
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, false, nil
+		}
+		mediaType = parsed
+	}
+
+	switch mediaType {
+	case "application/json":
+		value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+		return value, true, err
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		var decoded interface{}
+		if err := msgpack.Unmarshal(body, &decoded); err != nil {
+			return nil, true, err
+		}
+		asJSON, err := json.Marshal(decoded)
+		if err != nil {
+			return nil, true, err
+		}
+		value, err := jsonschema.UnmarshalJSON(bytes.NewReader(asJSON))
+		return value, true, err
+	}
+	return nil, false, nil
+}
+
+// Validate the request's path params, query params and body.
+func (v *Validator) validate(r *http.Request, op *operation) []FieldError {
+	var errs []FieldError
+	query := r.URL.Query()
+	declared := map[string]bool{}
+
+	for _, p := range op.params {
+		var raw string
+		var present bool
+		switch p.in {
+		case "path":
+			raw = chi.URLParam(r, p.name)
+			present = true
+		case "query":
+			declared[p.name] = true
+			present = query.Has(p.name)
+			raw = query.Get(p.name)
+		default:
+			continue
+		}
+
+		if !present {
+			if p.required {
+				errs = append(errs, FieldError{In: p.in, Pointer: p.name, Detail: "missing required parameter"})
+			}
+			continue
+		}
+		value, err := p.value(raw)
+		if err == nil {
+			err = p.schema.Validate(value)
+		}
+		if err != nil {
+			errs = append(errs, fieldErrors(p.in, p.name, err)...)
+		}
+	}
+
+	var unknown []string
+	for name := range query {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, FieldError{In: "query", Pointer: name, Detail: "unknown query parameter"})
+	}
+
+	if op.body == nil || r.Body == nil {
+		return errs
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return append(errs, FieldError{In: "body", Pointer: "", Detail: "error reading body: " + err.Error()})
+	}
+	if len(body) > maxBodyBytes {
+		return append(errs, FieldError{In: "body", Pointer: "", Detail: "body is larger than 1MB"})
+	}
+
+	value, checked, err := bodyValue(r, body)
+	if err != nil {
+		return append(errs, FieldError{In: "body", Pointer: "", Detail: "malformed body: " + err.Error()})
+	}
+	if checked {
+		if err := op.body.Validate(value); err != nil {
+			errs = append(errs, fieldErrors("body", "", err)...)
+		}
+	}
+	return errs
+}
+
+// Middleware rejects requests that don't match the operation for their
+// route with a 400 listing every invalid field. It must run after routing
+// so the chi route pattern is known; routes missing from the document pass
+// through unchecked.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		op, ok := v.operations[r.Method+" "+rctx.RoutePattern()]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if errs := v.validate(r, op); len(errs) > 0 {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Problem{
+				Type:   "about:blank",
+				Title:  "Request validation failed",
+				Status: http.StatusBadRequest,
+				Errors: errs,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// TestValidator tests path, query and body validation against the spec.
+func TestValidator(t *testing.T) {
+	validator, err := NewValidator(Spec)
+	assert.NoError(t, err)
+
+	var received string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		// group middleware runs once the route is matched
+		r.Use(validator.Middleware)
+		r.Get("/api/course/{id}", handler)
+		r.Post("/api/person", handler)
+		r.Get("/api/person", handler)
+		r.Get("/unlisted", handler)
+	})
+
+	msgpackBody, err := msgpack.Marshal(map[string]interface{}{"first_name": "Ada", "last_name": "Lovelace", "type": "student", "age": "old"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        []byte
+		errors      []FieldError
+	}{
+		{"valid body", "POST", "/api/person", "", []byte(`{"first_name":"Ada","last_name":"Lovelace","type":"student","age":36,"courses":[1]}`), nil},
+		{"unknown field", "POST", "/api/person", "application/json",
+			[]byte(`{"first_name":"Ada","last_name":"Lovelace","type":"student","age":36,"nickname":"A"}`),
+			[]FieldError{{In: "body", Pointer: "/nickname", Detail: "unknown field"}}},
+		{"wrong types", "POST", "/api/person", "application/json",
+			[]byte(`{"first_name":"Ada","last_name":"Lovelace","type":"teacher","age":"36","courses":["x"]}`),
+			[]FieldError{
+				{In: "body", Pointer: "/age", Detail: "got string, want integer"},
+				{In: "body", Pointer: "/courses/0", Detail: "got string, want integer"},
+				{In: "body", Pointer: "/type", Detail: "value must be one of 'professor', 'student'"},
+			}},
+		{"missing field", "POST", "/api/person", "", []byte(`{"first_name":"Ada","last_name":"Lovelace","type":"student"}`),
+			[]FieldError{{In: "body", Pointer: "", Detail: "missing property 'age'"}}},
+		{"malformed json", "POST", "/api/person", "", []byte(`{"first_name":`),
+			[]FieldError{{In: "body", Pointer: "", Detail: "malformed body: unexpected EOF"}}},
+		{"msgpack body", "POST", "/api/person", "application/msgpack", msgpackBody,
+			[]FieldError{{In: "body", Pointer: "/age", Detail: "got string, want integer"}}},
+		{"xml body is left to the handler", "POST", "/api/person", "application/xml", []byte(`<person><age>old</age></person>`), nil},
+		{"valid path", "GET", "/api/course/7", "", nil, nil},
+		{"path type", "GET", "/api/course/seven", "", nil,
+			[]FieldError{{In: "path", Pointer: "id", Detail: `got "seven", want integer`}}},
+		{"path minimum", "GET", "/api/course/0", "", nil,
+			[]FieldError{{In: "path", Pointer: "id", Detail: "minimum: got 0, want 1"}}},
+		{"valid query", "GET", "/api/person?name=Ada&age=36", "", nil, nil},
+		{"query type and unknown query", "GET", "/api/person?age=old&sort=name", "", nil,
+			[]FieldError{
+				{In: "query", Pointer: "age", Detail: `got "old", want integer`},
+				{In: "query", Pointer: "sort", Detail: "unknown query parameter"},
+			}},
+		{"route missing from spec", "GET", "/unlisted?anything=1", "", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if tt.errors == nil {
+				assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+				assert.Equal(t, string(tt.body), received, "body is passed on unchanged")
+				return
+			}
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+			var problem Problem
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+			assert.Equal(t, http.StatusBadRequest, problem.Status)
+			assert.ElementsMatch(t, tt.errors, problem.Errors)
+		})
+	}
+}
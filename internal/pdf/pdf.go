@@ -0,0 +1,159 @@
+// Package pdf writes simple text documents as PDF 1.4 in pure Go. Text is
+// set in the standard Helvetica and Courier fonts every PDF reader has, so
+// nothing is embedded and documents stay a few kilobytes.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// US Letter page size and the default margin, in points.
+const (
+	PageWidth  = 612
+	PageHeight = 792
+	Margin     = 54
+)
+
+// Font is one of the standard fonts a page can use.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	Courier
+)
+
+// base font names and the resource names pages refer to them by, by Font
+var fonts = []struct{ base, name string }{
+	{"Helvetica", "F1"},
+	{"Helvetica-Bold", "F2"},
+	{"Courier", "F3"},
+}
+
+// Document is a PDF being built page by page.
+type Document struct {
+	Title string
+	pages []*Page
+}
+
+// Page is a page of a Document. Coordinates are in points from the bottom
+// left corner.
+type Page struct {
+	content bytes.Buffer
+}
+
+// New returns an empty document with title in its metadata.
+func New(title string) *Document {
+	return &Document{Title: title}
+}
+
+// AddPage appends a blank page and returns it.
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages returns the number of pages added.
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Text draws text with its baseline starting at x, y.
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td %s Tj ET\n",
+		fonts[font].name, number(size), number(x), number(y), literal(text))
+}
+
+// Line draws a straight line width points wide.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", number(width), number(x1), number(y1), number(x2), number(y2))
+}
+
+// WriteTo writes the document to w. A document without pages gets one blank
+// page, since PDF requires at least one.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = []*Page{{}}
+	}
+
+	// objects are numbered from 1: the catalog, the page tree, the info
+	// dictionary, the fonts, then a page and its content stream per page
+	const catalog, tree, info, firstFont = 1, 2, 3, 4
+	firstPage := firstFont + len(fonts)
+	var objects []string
+	add := func(object string) { objects = append(objects, object) }
+
+	add(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", tree))
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	add(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	add(fmt.Sprintf("<< /Title %s /Producer (college api) >>", literal(d.Title)))
+	resources := make([]string, len(fonts))
+	for i, font := range fonts {
+		add(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.base))
+		resources[i] = fmt.Sprintf("/%s %d 0 R", font.name, firstFont+i)
+	}
+	for i, page := range pages {
+		add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			tree, PageWidth, PageHeight, strings.Join(resources, " "), firstPage+2*i+1))
+		add(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	// the cross-reference table holds the byte offset of every object
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, info, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// Format n with at most two decimals, the precision PDF readers need.
+func number(n float64) string {
+	return strconv.FormatFloat(math.Round(n*100)/100, 'f', -1, 64)
+}
+
+// Return text as a PDF string literal in WinAnsiEncoding. Characters the
+// encoding lacks become ?, and anything outside printable ASCII is written
+// as an octal escape.
+func literal(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		switch {
+		case !ok:
+			b.WriteByte('?')
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteTo tests the document structure and that the cross-reference
+// table points at every object.
+func TestWriteTo(t *testing.T) {
+	doc := New("Transcript")
+	page := doc.AddPage()
+	page.Text(54, 738, HelveticaBold, 16, "Official Transcript")
+	page.Line(54, 730, 558, 730, 0.5)
+	doc.AddPage().Text(54, 738, Courier, 10, "page 2")
+	assert.Equal(t, 2, doc.Pages())
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "/Type /Pages /Kids [7 0 R 9 0 R] /Count 2")
+	assert.Contains(t, out, "/Title (Transcript)")
+	assert.Contains(t, out, "BT /F2 16 Tf 54 738 Td (Official Transcript) Tj ET\n")
+	assert.Contains(t, out, "0.5 w 54 730 m 558 730 l S\n")
+
+	// startxref points at the table, and each entry at its object
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	assert.Len(t, start, 2)
+	xref, err := strconv.Atoi(start[1])
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out[xref:], "xref\n0 11\n"))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllStringSubmatch(out[xref:], -1)
+	assert.Len(t, entries, 10)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[1])
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out[offset:], strconv.Itoa(i+1)+" 0 obj\n"), "object %d", i+1)
+	}
+
+	// stream lengths match their content
+	for _, match := range regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)endstream`).FindAllStringSubmatch(out, -1) {
+		length, err := strconv.Atoi(match[1])
+		assert.NoError(t, err)
+		assert.Len(t, match[2], length)
+	}
+}
+
+// TestLiteral tests escaping text and encoding it in WinAnsi.
+func TestLiteral(t *testing.T) {
+	assert.Equal(t, `(Intro \(Part 1\) \\ Labs)`, literal(`Intro (Part 1) \ Labs`))
+	assert.Equal(t, `(Jos\351 \200 \227 ?)`, literal("José € — 漢"))
+}
+
+// TestEmpty tests that a document without pages still has one.
+func TestEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := New("").WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "/Count 1")
+}
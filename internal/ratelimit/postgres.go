@@ -0,0 +1,67 @@
+// postgres backed token bucket store shared by every replica
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+)
+
+// PostgresStore keeps buckets in the rate_limits table so all replicas
+// enforce one budget. Each Take is a single atomic upsert.
+type PostgresStore struct {
+	DB *sql.DB
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// takeQuery refills the bucket from the time elapsed since it was updated,
+// capped at the burst size, and takes a token when at least one is
+// available. $2 is the burst size, $3 the refill rate per second and $4 the
+// period in seconds, after which an idle bucket is full again and expires.
+const takeQuery = `
+    INSERT INTO rate_limits AS rl (key, tokens, allowed, updated_at, expires_at)
+    VALUES ($1, $2::float8 - 1, true, now(), now() + make_interval(secs => $4::float8))
+    ON CONFLICT (key) DO UPDATE SET
+        tokens = CASE
+            WHEN LEAST($2::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $3::float8) >= 1
+                THEN LEAST($2::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $3::float8) - 1
+            ELSE LEAST($2::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $3::float8)
+        END,
+        allowed = LEAST($2::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $3::float8) >= 1,
+        updated_at = now(),
+        expires_at = now() + make_interval(secs => $4::float8)
+    RETURNING tokens, allowed
+`
+
+// Take implements Store.
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.prune(ctx)
+
+	var result Result
+	err := s.DB.QueryRowContext(ctx, takeQuery, key, float64(limit.Requests), limit.rate(), limit.Period.Seconds()).
+		Scan(&result.Tokens, &result.Allowed)
+	return result, err
+}
+
+// prune deletes buckets that have refilled completely, at most once a
+// minute per replica, so idle clients don't accumulate rows. A failure only
+// delays pruning to the next minute.
+func (s *PostgresStore) prune(ctx context.Context) {
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(s.lastPrune) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.mu.Unlock()
+
+	if _, err := s.DB.ExecContext(ctx, "DELETE FROM rate_limits WHERE expires_at < now()"); err != nil {
+		logging.FromContext(ctx).Warn("Error pruning rate limits", "error", err)
+	}
+}
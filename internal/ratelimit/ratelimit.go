@@ -0,0 +1,180 @@
+// token bucket rate limiting per client and route group
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+)
+
+// Limit allows Requests per Period on average, with bursts of up to Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// rate returns how many tokens are added to the bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// ParseLimit parses a limit written as "<requests>/<period>", e.g. "60/1m".
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q must look like 60/1m", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: requests must be a positive integer", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: period must be a positive duration", s)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Result is the state of a bucket after taking a token from it.
+type Result struct {
+	Allowed bool
+	Tokens  float64 // tokens left in the bucket
+}
+
+// Store holds token buckets. Take refills the bucket for key according to
+// limit and the time since it was last used, then takes one token if
+// available.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limiter applies per route group limits using a shared store.
+type Limiter struct {
+	Store  Store
+	Limits map[string]Limit // by route group name
+}
+
+// ClientKey identifies the caller for rate limiting: the api key used, else
+// the authenticated subject, else the client IP.
+func ClientKey(r *http.Request) string {
+	if key, ok := PrincipalKey(r.Context()); ok {
+		return key
+	}
+	return IPKey(r)
+}
+
+// PrincipalKey identifies the authenticated caller on ctx like ClientKey,
+// reporting false when there is none to tell them by.
+func PrincipalKey(ctx context.Context) (string, bool) {
+	if principal, ok := auth.FromContext(ctx); ok {
+		if principal.APIKeyID != 0 {
+			return "key:" + strconv.FormatUint(uint64(principal.APIKeyID), 10), true
+		}
+		if principal.Subject != "" {
+			return principal.Method + ":" + principal.Subject, true
+		}
+	}
+	return "", false
+}
+
+// IPKey identifies the caller by client IP alone, for limits checked before
+// they authenticate.
+func IPKey(r *http.Request) string {
+	return AddrKey(r.RemoteAddr)
+}
+
+// AddrKey identifies the caller by the IP of their address, host:port.
+func AddrKey(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return "ip:" + host
+}
+
+// Allow takes a token from the bucket of key in group, reporting whether the
+// call may go ahead and, when it may not, how long until it could. Like the
+// middleware it allows every call when the limiter is nil, the group has no
+// limit or the store fails.
+func (l *Limiter) Allow(ctx context.Context, group, key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	limit, ok := l.Limits[group]
+	if !ok {
+		return true, 0
+	}
+	result, err := l.Store.Take(ctx, group+":"+key, limit)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error checking rate limit", "group", group, "error", err)
+		return true, 0
+	}
+	if !result.Allowed {
+		return false, retryAfter(limit, result)
+	}
+	return true, 0
+}
+
+// Time until the bucket of result holds a whole token again, at least a
+// second.
+func retryAfter(limit Limit, result Result) time.Duration {
+	return time.Duration(math.Max(1, math.Ceil((1-result.Tokens)/limit.rate()))) * time.Second
+}
+
+// Group returns middleware enforcing the limit configured for group per
+// ClientKey. It passes every request through when the limiter is nil or the
+// group has no limit.
+func (l *Limiter) Group(group string) func(http.Handler) http.Handler {
+	return l.middleware(group, ClientKey)
+}
+
+// IP returns middleware like Group, but keyed by IPKey so it can run before
+// authentication and limit callers with invalid credentials too.
+func (l *Limiter) IP(group string) func(http.Handler) http.Handler {
+	return l.middleware(group, IPKey)
+}
+
+func (l *Limiter) middleware(group string, key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l == nil {
+			return next
+		}
+		limit, ok := l.Limits[group]
+		if !ok {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := l.Store.Take(r.Context(), group+":"+key(r), limit)
+			if err != nil {
+				// fail open so a store outage doesn't take the API down
+				logging.FromContext(r.Context()).Error("Error checking rate limit", "group", group, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rate := limit.rate()
+			reset := math.Ceil((float64(limit.Requests) - result.Tokens) / rate)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(int(math.Max(0, math.Floor(result.Tokens)))))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Max(0, reset))))
+			w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Period.Seconds())))
+
+			if !result.Allowed {
+				seconds := strconv.Itoa(int(retryAfter(limit, result).Seconds()))
+				w.Header().Set("Retry-After", seconds)
+				http.Error(w, "Rate limit exceeded, retry in "+seconds+" seconds", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseLimit tests the <requests>/<period> format.
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("60/1m")
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 60, Period: time.Minute}, limit)
+	assert.Equal(t, 1.0, limit.rate())
+
+	for _, bad := range []string{"", "60", "0/1m", "x/1m", "60/x", "60/-1s"} {
+		_, err := ParseLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+// TestMemoryStore tests bucket depletion and refill with a fake clock.
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+
+	for _, expected := range []bool{true, true, false} {
+		result, err := store.Take(context.Background(), "a", limit)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result.Allowed)
+	}
+
+	// other keys have their own bucket
+	result, _ := store.Take(context.Background(), "b", limit)
+	assert.True(t, result.Allowed)
+
+	// one token is earned per second
+	now = now.Add(time.Second)
+	result, _ = store.Take(context.Background(), "a", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0.0, result.Tokens)
+
+	// full buckets are swept after a minute of inactivity
+	now = now.Add(2 * time.Minute)
+	store.Take(context.Background(), "c", limit)
+	assert.Len(t, store.buckets, 1)
+}
+
+// TestPostgresStore tests that Take is a single upsert with the limit's
+// burst size, refill rate and period, pruning expired buckets at most once a
+// minute.
+func TestPostgresStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	take := func() {
+		mock.ExpectQuery("INSERT INTO rate_limits AS rl \\(key, tokens, allowed, updated_at, expires_at\\)").
+			WithArgs("person:ip:10.0.0.1", 60.0, 1.0, 60.0).
+			WillReturnRows(sqlmock.NewRows([]string{"tokens", "allowed"}).AddRow(0.25, false))
+	}
+	mock.ExpectExec("DELETE FROM rate_limits WHERE expires_at < now\\(\\)").WillReturnResult(sqlmock.NewResult(0, 3))
+	take()
+	take()
+
+	store := &PostgresStore{DB: db}
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(context.Background(), "person:ip:10.0.0.1", Limit{Requests: 60, Period: time.Minute})
+		assert.NoError(t, err)
+		assert.Equal(t, Result{Allowed: false, Tokens: 0.25}, result)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestClientKey tests the preference for api key, then subject, then IP.
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	assert.Equal(t, "ip:10.0.0.1", ClientKey(req))
+
+	jwtReq := req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "jdoe", Method: "jwt"}))
+	assert.Equal(t, "jwt:jdoe", ClientKey(jwtReq))
+
+	keyReq := req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "lms", Method: "api_key", APIKeyID: 7}))
+	assert.Equal(t, "key:7", ClientKey(keyReq))
+	assert.Equal(t, "ip:10.0.0.1", IPKey(keyReq))
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("db down")
+}
+
+// TestLimiterGroup tests the headers and 429 response of the middleware.
+func TestLimiterGroup(t *testing.T) {
+	limiter := &Limiter{
+		Store:  NewMemoryStore(),
+		Limits: map[string]Limit{"person": {Requests: 2, Period: time.Minute}},
+	}
+	handler := limiter.Group("person")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/person", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rr.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=60", rr.Header().Get("RateLimit-Policy"))
+
+	serve()
+	rr = serve()
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+}
+
+// TestLimiterPassthrough tests groups without limits, a nil limiter and a
+// failing store.
+func TestLimiterPassthrough(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+
+	var nilLimiter *Limiter
+	failing := &Limiter{Store: failingStore{}, Limits: map[string]Limit{"person": {Requests: 1, Period: time.Second}}}
+
+	for _, handler := range []http.Handler{
+		nilLimiter.Group("person")(next),
+		failing.Group("course")(next),
+		failing.Group("person")(next),
+	} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	}
+}
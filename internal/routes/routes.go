@@ -2,23 +2,157 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/authz"
 	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/openapi"
 )
 
 func GetRoutes(r chi.Router, handler *handlers.RequestHandler) {
-	// course routes
-	r.Get("/api/course", handler.GetAllCourses)
-	r.Get("/api/course/{id}", handler.GetCourse)
-	r.Put("/api/course/{id}", handler.UpdateCourse)
-	r.Post("/api/course", handler.CreateCourse)
-	r.Delete("/api/course/{id}", handler.DeleteCourse)
-
-	// person routes
-	r.Get("/api/person", handler.GetAllPeople)     //takes querys of name (first or last) and age
-	r.Get("/api/person/{name}", handler.GetPerson) // name = first + ' ' + last
-	r.Put("/api/person/{name}", handler.UpdatePerson)
-	r.Post("/api/person", handler.CreatePerson)
-	r.Delete("/api/person/{name}", handler.DeletePerson)
+	// path params, query params and bodies are checked against openapi.json
+	// once a route's policy has allowed the request
+	validator, err := openapi.NewValidator(openapi.Spec)
+	if err != nil {
+		panic("invalid openapi document: " + err.Error())
+	}
+	policy := func(rule authz.Rule) func(http.Handler) http.Handler {
+		require := authz.Require(rule)
+		return func(next http.Handler) http.Handler {
+			return require(validator.Middleware(next))
+		}
+	}
+
+	// authorization policies, checked per route below
+	anyone := policy(authz.Authenticated())
+	admin := policy(authz.AnyRole(auth.RoleAdmin))
+	registrar := policy(authz.AnyRole(auth.RoleAdmin, auth.RoleRegistrar))
+	staff := policy(authz.AnyRole(auth.RoleAdmin, auth.RoleRegistrar, auth.RoleProfessor))
+	self := policy(authz.Any(
+		authz.AnyRole(auth.RoleAdmin, auth.RoleRegistrar, auth.RoleProfessor),
+		handler.IsSelf,
+	))
+	teacher := policy(authz.Any(
+		authz.AnyRole(auth.RoleAdmin, auth.RoleRegistrar),
+		handler.TeachesCourse,
+	))
+	grader := policy(handler.TeachesCourse) // not even admins grade courses
+
+	limit := handler.Limiter.Group
+
+	graphql, err := handler.GraphQL()
+	if err != nil {
+		panic("invalid graphql schema: " + err.Error())
+	}
+
+	// api documentation, readable without credentials
+	r.Get("/openapi.json", openapi.ServeSpec)
+	r.Get("/docs", openapi.ServeDocs)
+
+	r.Group(func(r chi.Router) {
+		// each client IP has a budget across every api route, checked before
+		// credentials so invalid ones can't be tried without limit
+		r.Use(handler.Limiter.IP("ip"))
+
+		// every api route requires an api key or bearer token
+		r.Use(handler.Authenticate)
+
+		// exports and transcripts pick their format themselves
+		r.Group(func(r chi.Router) {
+			r.Use(limit("export"))
+
+			r.With(anyone).Get("/api/course/export", handler.ExportCourses)              // ?format=csv|ndjson or Accept header
+			r.With(staff).Get("/api/person/export", handler.ExportPeople)                // same querys as GetAllPeople plus format
+			r.With(self).Get("/api/person/{id}/transcript", handler.GetPersonTranscript) // ?format=json|html|pdf or Accept header
+		})
+
+		// server-sent events of every change, open until the client leaves
+		r.Group(func(r chi.Router) {
+			r.Use(limit("events"))
+
+			r.With(staff).Get("/api/events", handler.StreamEvents) // takes types, course_id and person_id, resumes after Last-Event-ID
+		})
+
+		// graphql always answers in json; resolvers check the same roles as
+		// the REST routes
+		r.Group(func(r chi.Router) {
+			r.Use(limit("graphql"))
+
+			r.With(anyone).Get("/graphql", graphql) // queries only
+			r.With(anyone).Post("/graphql", graphql)
+		})
+
+		r.Group(func(r chi.Router) {
+			// render responses as json, xml, csv or msgpack based on Accept
+			r.Use(handlers.Negotiate)
+
+			// course, term and roster routes
+			r.Group(func(r chi.Router) {
+				r.Use(limit("course"))
+
+				r.With(anyone).Get("/api/term", handler.GetAllTerms)
+				r.With(anyone).Get("/api/term/current", handler.GetCurrentTerm) // latest to have started
+				r.With(anyone).Get("/api/term/{id}", handler.GetTerm)
+				r.With(registrar).Post("/api/term", handler.CreateTerm) // refuses overlapping terms
+				r.With(registrar).Put("/api/term/{id}", handler.UpdateTerm)
+				r.With(registrar).Delete("/api/term/{id}", handler.DeleteTerm) // refuses terms with enrollments
+
+				r.With(anyone).Get("/api/course", handler.GetAllCourses)
+				r.With(anyone).Get("/api/course/{id}", handler.GetCourse)
+				r.With(registrar).Put("/api/course/{id}", handler.UpdateCourse)
+				r.With(registrar).Post("/api/course", handler.CreateCourse)
+				r.With(registrar).Delete("/api/course/{id}", handler.DeleteCourse)
+
+				// roster and person routes take term, the current term when missing
+				r.With(staff).Get("/api/course/{id}/roster", handler.GetCourseRoster)
+				r.With(staff).Get("/api/course/{id}/waitlist", handler.GetCourseWaitlist)
+				r.With(teacher).Post("/api/course/{id}/roster", handler.AddToRoster) // override_prerequisites=true for admins
+				r.With(teacher).Delete("/api/course/{id}/roster/{personID}", handler.RemoveFromRoster)
+				r.With(grader).Put("/api/course/{id}/roster/{personID}/grade", handler.SetGrade)
+
+				r.With(anyone).Get("/api/grade-scale", handler.GetGradeScale)
+				r.With(registrar).Put("/api/grade-scale", handler.UpdateGradeScale) // recorded grades keep their points
+
+				r.With(anyone).Get("/api/course/{id}/prerequisites", handler.GetCoursePrerequisites)
+				r.With(registrar).Post("/api/course/{id}/prerequisites", handler.AddPrerequisite) // refuses cycles
+				r.With(registrar).Delete("/api/course/{id}/prerequisites/{prerequisiteID}", handler.RemovePrerequisite)
+
+				r.With(anyone).Get("/api/course/{id}/meetings", handler.GetCourseMeetings)
+				r.With(registrar).Post("/api/course/{id}/meetings", handler.AddMeeting) // refuses clashes with anyone's schedule
+				r.With(registrar).Delete("/api/course/{id}/meetings/{meetingID}", handler.RemoveMeeting)
+			})
+
+			// person routes
+			r.Group(func(r chi.Router) {
+				r.Use(limit("person"))
+
+				r.With(staff).Get("/api/person", handler.GetAllPeople)    //takes querys of name (first or last) and age
+				r.With(self).Get("/api/person/{name}", handler.GetPerson) // name = first + ' ' + last
+				r.With(self).Get("/api/person/{id}/gpa", handler.GetPersonGPA)
+				r.With(registrar).Put("/api/person/{name}", handler.UpdatePerson)
+				r.With(registrar).Post("/api/person", handler.CreatePerson)
+				r.With(registrar).Delete("/api/person/{name}", handler.DeletePerson)
+			})
+
+			// admin routes
+			r.Group(func(r chi.Router) {
+				r.Use(limit("admin"))
+
+				r.With(admin).Get("/api/admin/keys", handler.GetAllAPIKeys)
+				r.With(admin).Post("/api/admin/keys", handler.CreateAPIKey) // plaintext key only returned here
+				r.With(admin).Post("/api/admin/keys/{id}/rotate", handler.RotateAPIKey)
+				r.With(admin).Delete("/api/admin/keys/{id}", handler.RevokeAPIKey)
+
+				r.With(admin).Get("/api/admin/webhooks", handler.GetAllWebhooks)
+				r.With(admin).Post("/api/admin/webhooks", handler.CreateWebhook) // signing secret only returned here
+				r.With(admin).Put("/api/admin/webhooks/{id}", handler.UpdateWebhook)
+				r.With(admin).Delete("/api/admin/webhooks/{id}", handler.DeleteWebhook)
+				r.With(admin).Get("/api/admin/webhooks/{id}/deliveries", handler.GetWebhookDeliveries) // takes status, limit and offset
+				r.With(admin).Post("/api/admin/webhooks/{id}/deliveries/{deliveryID}/retry", handler.RetryWebhookDelivery)
+			})
+		})
+	})
 
 }
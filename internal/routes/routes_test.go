@@ -5,14 +5,25 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
 	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/openapi"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/ratelimit"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store/storetest"
 	"github.com/stretchr/testify/assert"
 )
 
+const testAdminKey = "test-admin-key"
+
 func TestGetRoutes(t *testing.T) {
 
 	// Create a new mock database connection
@@ -21,7 +32,7 @@ func TestGetRoutes(t *testing.T) {
 	defer db.Close()
 
 	// Create a new request handler
-	handler := &handlers.RequestHandler{DB: db}
+	handler := &handlers.RequestHandler{DB: db, AdminKey: testAdminKey}
 	r := chi.NewRouter()
 	GetRoutes(r, handler)
 
@@ -42,26 +53,50 @@ func TestGetRoutes(t *testing.T) {
 	}
 
 	// Mock the database responses
-	mock.ExpectQuery("SELECT \\* FROM course").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Course 1").AddRow(2, "Course 2"))
-	mock.ExpectQuery("SELECT \\* FROM course WHERE id = \\$1").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Course 1"))
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Course 1", nil, nil, false).AddRow(2, "Course 2", 30, nil, false))
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Course 1", nil, nil, false))
 
+	// people's courses are those of the current term
+	expectCurrentTerm := func() {
+		mock.ExpectQuery("SELECT id, name, .* FROM term WHERE CASE WHEN \\$1 = 0").WithArgs(0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end"}).AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15"))
+	}
+	expectCurrentTerm()
 	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(1, "John", "Doe", "student", 25).AddRow(2, "Jane", "Doe", "professor", 30))
-	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1").
-		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"course_id"}).AddRow(1).AddRow(2))
-	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1").
-		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"course_id"}).AddRow(1).AddRow(3))
+	storetest.ExpectPersonCourses(mock, sqlmock.NewRows([]string{"person_id", "course_id", "role"}).
+		AddRow(1, 1, "student").AddRow(2, 1, "instructor").AddRow(1, 2, "student").AddRow(2, 3, "instructor"), 1, 2)
 
+	expectCurrentTerm()
 	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person WHERE first_name \\|\\| ' ' \\|\\| last_name = \\$1").WithArgs("John Doe").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}).AddRow(1, "John", "Doe", "student", 25))
-	mock.ExpectQuery("SELECT course_id FROM person_course WHERE person_id = \\$1").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"course_id"}).AddRow(1))
+	storetest.ExpectPersonCourses(mock, sqlmock.NewRows([]string{"person_id", "course_id", "role"}).AddRow(1, 1, "student"), 1)
+	storetest.ExpectPersonWaitlist(mock, sqlmock.NewRows([]string{"person_id", "course_id", "position"}), 1)
 
-	mock.ExpectExec("UPDATE course SET name = \\$1 WHERE id = \\$2").WithArgs("Updated Course", 1).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery("SELECT \\* FROM course WHERE id = \\$1").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Updated Course"))
+	// changes publish an event in their transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(nil))
+	mock.ExpectExec("UPDATE course SET name = \\$1, capacity = \\$2, credits = \\$3, requires_instructor = \\$4 WHERE id = \\$5").WithArgs("Updated Course", nil, nil, false, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO events").WithArgs("course.updated", sqlmock.AnyArg(), "college_events").WillReturnResult(sqlmock.NewResult(0, 0))
+	// without a capacity everyone waiting is enrolled, in every term
+	mock.ExpectQuery("SELECT DISTINCT term_id FROM waitlist WHERE course_id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"term_id"}).AddRow(2))
+	mock.ExpectQuery("WITH promoted AS").WithArgs(1, 2, nil).WillReturnRows(sqlmock.NewRows([]string{"person_id"}))
+	mock.ExpectCommit()
 
-	mock.ExpectQuery("INSERT INTO course \\(name\\) VALUES \\(\\$1\\) RETURNING id").
-		WithArgs("New Course").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO course \\(name, capacity, credits, requires_instructor\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id").
+		WithArgs("New Course", nil, nil, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO events").WithArgs("course.created", sqlmock.AnyArg(), "college_events").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
 
-	mock.ExpectExec("DELETE FROM course WHERE id = \\$1").
-		WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"capacity"}).AddRow(nil))
+	mock.ExpectQuery("DELETE FROM person_course WHERE course_id = \\$1 RETURNING person_id, term_id").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"person_id", "term_id"}))
+	mock.ExpectQuery("DELETE FROM course WHERE id = \\$1 RETURNING name").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Updated Course"))
+	mock.ExpectExec("INSERT INTO events").WithArgs("course.deleted", sqlmock.AnyArg(), "college_events").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
 
 	// Run the test cases
 	for _, tt := range tests {
@@ -90,6 +125,7 @@ func TestGetRoutes(t *testing.T) {
 
 			// Assert there were no errors creating the request
 			assert.NoError(t, err)
+			req.Header.Set("X-API-Key", testAdminKey)
 
 			// Create a new recorder to capture response
 			rr := httptest.NewRecorder()
@@ -105,3 +141,304 @@ func TestGetRoutes(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestGetRoutesRequiresAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &handlers.RequestHandler{DB: db, AdminKey: testAdminKey}
+	r := chi.NewRouter()
+	GetRoutes(r, handler)
+
+	// no key is rejected before any handler runs
+	req, err := http.NewRequest("DELETE", "/api/course/1", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetRoutesAuthorization(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	secret := []byte("test-secret")
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: secret})
+	assert.NoError(t, err)
+
+	handler := &handlers.RequestHandler{DB: db, JWT: verifier}
+	r := chi.NewRouter()
+	GetRoutes(r, handler)
+
+	token := func(role string, personID uint) string {
+		claims := auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   role,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			Role:     role,
+			PersonID: personID,
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		assert.NoError(t, err)
+		return signed
+	}
+	student := token(auth.RoleStudent, 3)
+	professor := token(auth.RoleProfessor, 1)
+	registrar := token(auth.RoleRegistrar, 0)
+
+	tests := []struct {
+		name         string
+		token        string
+		method       string
+		url          string
+		body         string
+		expect       func()
+		expectedCode int
+		expectedBody string
+	}{
+		{"student creates person", student, "POST", "/api/person", `{}`, nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar\n"},
+		{"professor deletes person", professor, "DELETE", "/api/person/Larry Page", "", nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar\n"},
+		{"student lists people", student, "GET", "/api/person", "", nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar, professor\n"},
+		{"student reads another person", student, "GET", "/api/person/Bill Gates", "", func() {
+			mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM person WHERE id = \\$1").
+				WithArgs(3, "Bill Gates").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		}, http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar, professor; or students may only access their own record\n"},
+		{"professor edits roster of another course", professor, "POST", "/api/course/3/roster", `{"person_id":4}`, func() {
+			mock.ExpectQuery("SELECT EXISTS\\( SELECT 1 FROM person_course pc").
+				WithArgs(1, 3, 0).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		}, http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar; or professors may only manage courses they teach\n"},
+		{"registrar grades course", registrar, "PUT", "/api/course/1/roster/3/grade", `{"letter":"A"}`, nil,
+			http.StatusForbidden, "Forbidden: professors may only manage courses they teach\n"},
+		{"student reads another gpa", student, "GET", "/api/person/4/gpa", "", nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar, professor; or students may only access their own record\n"},
+		{"student reads another transcript", student, "GET", "/api/person/4/transcript?format=pdf", "", nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar, professor; or students may only access their own record\n"},
+		{"registrar manages api keys", registrar, "GET", "/api/admin/keys", "", nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin\n"},
+		{"professor deletes course", professor, "DELETE", "/api/course/1", "", nil,
+			http.StatusForbidden, "Forbidden: requires one of the roles: admin, registrar\n"},
+		{"student reads course", student, "GET", "/api/course/1", "", func() {
+			mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Programming", nil, nil, false))
+		}, http.StatusOK, ""},
+		{"registrar creates course", registrar, "POST", "/api/course", `{"name":"Networks"}`, func() {
+			mock.ExpectBegin()
+			mock.ExpectQuery("INSERT INTO course \\(name, capacity, credits, requires_instructor\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id").
+				WithArgs("Networks", nil, nil, false).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+			mock.ExpectExec("INSERT INTO events").WithArgs("course.created", sqlmock.AnyArg(), "college_events").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectCommit()
+		}, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expect != nil {
+				tt.expect()
+			}
+
+			req, err := http.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetRoutesRateLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &handlers.RequestHandler{
+		DB:       db,
+		AdminKey: testAdminKey,
+		Limiter: &ratelimit.Limiter{
+			Store:  ratelimit.NewMemoryStore(),
+			Limits: map[string]ratelimit.Limit{"course": {Requests: 1, Period: time.Minute}},
+		},
+	}
+	r := chi.NewRouter()
+	GetRoutes(r, handler)
+
+	// only the first request reaches the database
+	mock.ExpectQuery("SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "capacity", "credits", "requires_instructor"}).AddRow(1, "Programming", nil, nil, false))
+
+	for _, expectedCode := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req, err := http.NewRequest("GET", "/api/course/1", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-API-Key", testAdminKey)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, expectedCode, rr.Code)
+		assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+	}
+
+	// other route groups are not limited
+	req, err := http.NewRequest("GET", "/api/person", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-API-Key", testAdminKey)
+	mock.ExpectQuery("FROM term WHERE CASE").WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "start", "end"}).AddRow(2, "Spring 2026", "2026-01-12", "2026-05-15"))
+	mock.ExpectQuery("SELECT id, first_name, last_name, type, age FROM person").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "type", "age"}))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// TestGetRoutesRateLimitIP tests that the per IP limit counts requests
+// failing authentication, so a caller guessing keys is stopped too.
+func TestGetRoutesRateLimitIP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	handler := &handlers.RequestHandler{
+		DB:       db,
+		AdminKey: testAdminKey,
+		Limiter: &ratelimit.Limiter{
+			Store:  ratelimit.NewMemoryStore(),
+			Limits: map[string]ratelimit.Limit{"ip": {Requests: 2, Period: time.Minute}},
+		},
+	}
+	r := chi.NewRouter()
+	GetRoutes(r, handler)
+
+	for _, expectedCode := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req, err := http.NewRequest("GET", "/api/course/1", nil)
+		assert.NoError(t, err)
+		req.RemoteAddr = "10.0.0.1:5000"
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, expectedCode, rr.Code)
+		assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	}
+
+	// even valid credentials wait, while other IPs have their own budget
+	req, err := http.NewRequest("GET", "/api/course/1", nil)
+	assert.NoError(t, err)
+	req.RemoteAddr = "10.0.0.1:5001"
+	req.Header.Set("X-API-Key", testAdminKey)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	req.RemoteAddr = "10.0.0.2:5000"
+	req.Header.Del("X-API-Key")
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetRoutesMatchSpec tests that every registered route is described in
+// the OpenAPI document with the same path parameters, and that the document
+// describes no route that isn't registered.
+func TestGetRoutesMatchSpec(t *testing.T) {
+	type parameter struct {
+		Ref  string `json:"$ref"`
+		Name string `json:"name"`
+		In   string `json:"in"`
+	}
+	var spec struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Parameters map[string]parameter `json:"parameters"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(openapi.Spec, &spec))
+
+	// resolve the path parameters declared on a path and its operation
+	pathParams := func(raw ...json.RawMessage) []string {
+		var names []string
+		for _, r := range raw {
+			if len(r) == 0 {
+				continue
+			}
+			var params []parameter
+			var op struct {
+				Parameters []parameter `json:"parameters"`
+			}
+			if json.Unmarshal(r, &params) != nil {
+				assert.NoError(t, json.Unmarshal(r, &op))
+				params = op.Parameters
+			}
+			for _, p := range params {
+				if p.Ref != "" {
+					p = spec.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+				}
+				if p.In == "path" {
+					names = append(names, p.Name)
+				}
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	documented := map[string][]string{}
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			if method == "parameters" {
+				continue
+			}
+			documented[strings.ToUpper(method)+" "+path] = pathParams(item["parameters"], op)
+		}
+	}
+
+	r := chi.NewRouter()
+	GetRoutes(r, &handlers.RequestHandler{})
+	placeholder := regexp.MustCompile(`{([^}]+)}`)
+	registered := map[string][]string{}
+	err := chi.Walk(r, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		names := []string{}
+		for _, match := range placeholder.FindAllStringSubmatch(route, -1) {
+			names = append(names, match[1])
+		}
+		sort.Strings(names)
+		registered[method+" "+route] = names
+		return nil
+	})
+	assert.NoError(t, err)
+
+	for route, names := range registered {
+		params, ok := documented[route]
+		if assert.True(t, ok, "%s is registered but missing from openapi.json", route) && len(names) > 0 {
+			assert.Equal(t, names, params, "path parameters of %s", route)
+		}
+	}
+	for route := range documented {
+		_, ok := registered[route]
+		assert.True(t, ok, "%s is in openapi.json but not registered", route)
+	}
+}
@@ -0,0 +1,248 @@
+// batched lookups for loading nested objects with one query per level
+package store
+
+import (
+	"context"
+	"strconv"
+)
+
+// CoursesByPerson returns the courses of each person in personIDs in the
+// term with termID, ordered by id.
+func (s *Store) CoursesByPerson(ctx context.Context, termID uint, personIDs []uint) (map[uint][]Course, error) {
+	list, args := idList(personIDs)
+	args = append(args, termID)
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT pc.person_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor
+        FROM person_course pc
+        JOIN course c ON c.id = pc.course_id
+        WHERE pc.person_id IN (`+list+`) AND pc.term_id = $`+strconv.Itoa(len(args))+`
+        ORDER BY c.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := map[uint][]Course{}
+	for _, id := range personIDs {
+		courses[id] = []Course{}
+	}
+	for rows.Next() {
+		var personID uint
+		var course Course
+		if err := rows.Scan(&personID, &course.ID, &course.Name, &course.Capacity, &course.Credits, &course.RequiresInstructor); err != nil {
+			return nil, err
+		}
+		courses[personID] = append(courses[personID], course)
+	}
+	return courses, rows.Err()
+}
+
+// RosterByCourse returns the people on the roster of each course in
+// courseIDs in the term with termID, ordered by id.
+func (s *Store) RosterByCourse(ctx context.Context, termID uint, courseIDs []uint) (map[uint][]Person, error) {
+	list, args := idList(courseIDs)
+	args = append(args, termID)
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT pc.course_id, p.id, p.first_name, p.last_name, p.type, p.age
+        FROM person_course pc
+        JOIN person p ON p.id = pc.person_id
+        WHERE pc.course_id IN (`+list+`) AND pc.term_id = $`+strconv.Itoa(len(args))+`
+        ORDER BY p.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roster := map[uint][]Person{}
+	for _, id := range courseIDs {
+		roster[id] = []Person{}
+	}
+	for rows.Next() {
+		var courseID uint
+		var person Person
+		if err := rows.Scan(&courseID, &person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age); err != nil {
+			return nil, err
+		}
+		roster[courseID] = append(roster[courseID], person)
+	}
+	return roster, rows.Err()
+}
+
+// EnrollmentsByPerson returns the enrollments of each person in personIDs in
+// the term with termID with their roles, ordered by course id. Waitlist
+// places are left out.
+func (s *Store) EnrollmentsByPerson(ctx context.Context, termID uint, personIDs []uint) (map[uint][]Enrollment, error) {
+	return s.enrollmentsBy(ctx, "person_id", termID, personIDs)
+}
+
+// EnrollmentsByCourse returns the enrollments in each course in courseIDs in
+// the term with termID with their roles, ordered by person id. Waitlist
+// places are left out.
+func (s *Store) EnrollmentsByCourse(ctx context.Context, termID uint, courseIDs []uint) (map[uint][]Enrollment, error) {
+	return s.enrollmentsBy(ctx, "course_id", termID, courseIDs)
+}
+
+// Return the enrollments in the term by column, person_id or course_id,
+// for each of ids, ordered by the other column.
+func (s *Store) enrollmentsBy(ctx context.Context, column string, termID uint, ids []uint) (map[uint][]Enrollment, error) {
+	order := "course_id"
+	if column == "course_id" {
+		order = "person_id"
+	}
+	list, args := idList(ids)
+	args = append(args, termID)
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT person_id, course_id, role
+        FROM person_course
+        WHERE `+column+` IN (`+list+`) AND term_id = $`+strconv.Itoa(len(args))+`
+        ORDER BY `+order, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrollments := map[uint][]Enrollment{}
+	for _, id := range ids {
+		enrollments[id] = []Enrollment{}
+	}
+	for rows.Next() {
+		enrollment := Enrollment{TermID: termID, Status: StatusEnrolled}
+		if err := rows.Scan(&enrollment.PersonID, &enrollment.CourseID, &enrollment.Role); err != nil {
+			return nil, err
+		}
+		key := enrollment.PersonID
+		if column == "course_id" {
+			key = enrollment.CourseID
+		}
+		enrollments[key] = append(enrollments[key], enrollment)
+	}
+	return enrollments, rows.Err()
+}
+
+// WaitlistByPerson returns the waitlist places of each person in personIDs
+// in the term with termID with their positions, in the order they joined.
+func (s *Store) WaitlistByPerson(ctx context.Context, termID uint, personIDs []uint) (map[uint][]Enrollment, error) {
+	list, args := idList(personIDs)
+	args = append(args, termID)
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT w.person_id, w.course_id,
+               (SELECT count(*) FROM waitlist o WHERE o.course_id = w.course_id AND o.term_id = w.term_id AND o.id <= w.id)
+        FROM waitlist w
+        WHERE w.person_id IN (`+list+`) AND w.term_id = $`+strconv.Itoa(len(args))+`
+        ORDER BY w.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	waitlist := map[uint][]Enrollment{}
+	for _, id := range personIDs {
+		waitlist[id] = []Enrollment{}
+	}
+	for rows.Next() {
+		enrollment := Enrollment{TermID: termID, Role: RoleStudent, Status: StatusWaitlisted}
+		if err := rows.Scan(&enrollment.PersonID, &enrollment.CourseID, &enrollment.Position); err != nil {
+			return nil, err
+		}
+		waitlist[enrollment.PersonID] = append(waitlist[enrollment.PersonID], enrollment)
+	}
+	return waitlist, rows.Err()
+}
+
+// PrerequisitesByCourse returns the prerequisites of each course in
+// courseIDs, ordered by id.
+func (s *Store) PrerequisitesByCourse(ctx context.Context, courseIDs []uint) (map[uint][]Course, error) {
+	list, args := idList(courseIDs)
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT cp.course_id, c.id, c.name, c.capacity, c.credits, c.requires_instructor
+        FROM course_prerequisite cp
+        JOIN course c ON c.id = cp.prerequisite_id
+        WHERE cp.course_id IN (`+list+`)
+        ORDER BY c.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prerequisites := map[uint][]Course{}
+	for _, id := range courseIDs {
+		prerequisites[id] = []Course{}
+	}
+	for rows.Next() {
+		var courseID uint
+		var course Course
+		if err := rows.Scan(&courseID, &course.ID, &course.Name, &course.Capacity, &course.Credits, &course.RequiresInstructor); err != nil {
+			return nil, err
+		}
+		prerequisites[courseID] = append(prerequisites[courseID], course)
+	}
+	return prerequisites, rows.Err()
+}
+
+// MeetingsByCourse returns the meetings of each course in courseIDs, in
+// schedule order.
+func (s *Store) MeetingsByCourse(ctx context.Context, courseIDs []uint) (map[uint][]Meeting, error) {
+	list, args := idList(courseIDs)
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT id, course_id, day, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), location
+        FROM course_meeting
+        WHERE course_id IN (`+list+`)
+        ORDER BY day, start_time, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	meetings := map[uint][]Meeting{}
+	for _, id := range courseIDs {
+		meetings[id] = []Meeting{}
+	}
+	for rows.Next() {
+		var meeting Meeting
+		if err := rows.Scan(&meeting.ID, &meeting.CourseID, &meeting.Day, &meeting.Start, &meeting.End, &meeting.Location); err != nil {
+			return nil, err
+		}
+		meetings[meeting.CourseID] = append(meetings[meeting.CourseID], meeting)
+	}
+	return meetings, rows.Err()
+}
+
+// CoursesByID returns the courses with ids. Missing ids are left out.
+func (s *Store) CoursesByID(ctx context.Context, ids []uint) (map[uint]*Course, error) {
+	list, args := idList(ids)
+	rows, err := s.DB.QueryContext(ctx, "SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id IN ("+list+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := map[uint]*Course{}
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.ID, &course.Name, &course.Capacity, &course.Credits, &course.RequiresInstructor); err != nil {
+			return nil, err
+		}
+		courses[course.ID] = &course
+	}
+	return courses, rows.Err()
+}
+
+// PeopleByID returns the people with ids. Missing ids are left out.
+func (s *Store) PeopleByID(ctx context.Context, ids []uint) (map[uint]*Person, error) {
+	list, args := idList(ids)
+	rows, err := s.DB.QueryContext(ctx, "SELECT id, first_name, last_name, type, age FROM person WHERE id IN ("+list+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	people := map[uint]*Person{}
+	for rows.Next() {
+		var person Person
+		if err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age); err != nil {
+			return nil, err
+		}
+		people[person.ID] = &person
+	}
+	return people, rows.Err()
+}
@@ -0,0 +1,280 @@
+// courses and their rosters
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// ListCourses returns the courses in page.
+func (s *Store) ListCourses(ctx context.Context, page Page) ([]Course, error) {
+	clause, args, err := page.clause(nil)
+	if err != nil {
+		return nil, err
+	}
+	return listCourses(s.DB.QueryContext(ctx, "SELECT id, name, capacity, credits, requires_instructor FROM course"+clause, args...))
+}
+
+// Read rows of course id, name, capacity, credits and requires_instructor.
+func listCourses(rows *sql.Rows, err error) ([]Course, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []Course{}
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.ID, &course.Name, &course.Capacity, &course.Credits, &course.RequiresInstructor); err != nil {
+			return nil, err
+		}
+		courses = append(courses, course)
+	}
+	return courses, rows.Err()
+}
+
+// GetCourse returns the course with id.
+func (s *Store) GetCourse(ctx context.Context, id uint) (Course, error) {
+	var course Course
+	err := s.DB.QueryRowContext(ctx, "SELECT id, name, capacity, credits, requires_instructor FROM course WHERE id = $1", id).
+		Scan(&course.ID, &course.Name, &course.Capacity, &course.Credits, &course.RequiresInstructor)
+	if err == sql.ErrNoRows {
+		return course, notFound("Course not found")
+	}
+	return course, err
+}
+
+// Check a course's fields.
+func (course Course) validate() error {
+	if course.Name == "" {
+		return invalid("Course name is required")
+	}
+	if course.Capacity != nil && *course.Capacity == 0 {
+		return invalid("Course capacity must be at least 1")
+	}
+	if course.Credits != nil && *course.Credits == 0 {
+		return invalid("Course credits must be at least 1")
+	}
+	return nil
+}
+
+// CreateCourse adds a course named name with room for capacity students,
+// or any number when capacity is nil, worth credits towards a GPA. A course
+// that requiresInstructor only takes students in terms someone instructs it.
+func (s *Store) CreateCourse(ctx context.Context, name string, capacity, credits *uint, requiresInstructor bool) (Course, error) {
+	course := Course{Name: name, Capacity: capacity, Credits: credits, RequiresInstructor: requiresInstructor}
+	if err := course.validate(); err != nil {
+		return course, err
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, "INSERT INTO course (name, capacity, credits, requires_instructor) VALUES ($1, $2, $3, $4) RETURNING id",
+			name, capacity, credits, requiresInstructor).
+			Scan(&course.ID)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, tx, EventCourseCreated, course)
+	})
+	return course, err
+}
+
+// UpdateCourse replaces the name, capacity, credits and instructor
+// requirement of the course with id. Changing its credits reweighs the GPA of
+// everyone graded in it. Raising the capacity promotes students off its
+// waitlist in every term; lowering it below the students enrolled keeps them
+// all but takes nobody else until they drop. Requiring an instructor keeps
+// the students of terms without one too.
+func (s *Store) UpdateCourse(ctx context.Context, id uint, name string, capacity, credits *uint, requiresInstructor bool) (Course, error) {
+	course := Course{ID: id, Name: name, Capacity: capacity, Credits: credits, RequiresInstructor: requiresInstructor}
+	if err := course.validate(); err != nil {
+		return course, err
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := lockCourse(ctx, tx, id); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE course SET name = $1, capacity = $2, credits = $3, requires_instructor = $4 WHERE id = $5",
+			name, capacity, credits, requiresInstructor, id)
+		if err != nil {
+			return err
+		}
+		if err := publish(ctx, tx, EventCourseUpdated, course); err != nil {
+			return err
+		}
+		return promoteAll(ctx, tx, id, capacity)
+	})
+	return course, err
+}
+
+// DeleteCourse deletes the course with id, its roster in every term and its
+// waitlist.
+func (s *Store) DeleteCourse(ctx context.Context, id uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// lock the course so nobody joins the roster while it's cleared
+		if _, err := lockCourse(ctx, tx, id); err != nil {
+			return err
+		}
+		enrollments, err := clearRoster(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		// waitlist places, prerequisites and meetings are deleted with the course
+		course := Course{ID: id}
+		if err := tx.QueryRowContext(ctx, "DELETE FROM course WHERE id = $1 RETURNING name", id).Scan(&course.Name); err != nil {
+			return err
+		}
+		if err := publishEnrollments(ctx, tx, EventEnrollmentRemoved, enrollments); err != nil {
+			return err
+		}
+		return publish(ctx, tx, EventCourseDeleted, course)
+	})
+}
+
+// Delete the course's roster in every term, returning its enrollments in
+// person and term order.
+func clearRoster(ctx context.Context, tx *sql.Tx, courseID uint) ([]Enrollment, error) {
+	rows, err := tx.QueryContext(ctx, "DELETE FROM person_course WHERE course_id = $1 RETURNING person_id, term_id", courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrollments := []Enrollment{}
+	for rows.Next() {
+		enrollment := Enrollment{CourseID: courseID}
+		if err := rows.Scan(&enrollment.PersonID, &enrollment.TermID); err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	sort.Slice(enrollments, func(i, j int) bool {
+		a, b := enrollments[i], enrollments[j]
+		return a.PersonID < b.PersonID || a.PersonID == b.PersonID && a.TermID < b.TermID
+	})
+	return enrollments, rows.Err()
+}
+
+// queryer runs single row queries on a *sql.DB or *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Return a not found error unless the course with id exists.
+func checkCourse(ctx context.Context, q queryer, id uint) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM course WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound("Course not found")
+	}
+	return nil
+}
+
+// Roster returns everyone on the course's roster in the term with termID,
+// the current term when 0, with their role in it.
+func (s *Store) Roster(ctx context.Context, courseID, termID uint) ([]Member, error) {
+	if err := checkCourse(ctx, s.DB, courseID); err != nil {
+		return nil, err
+	}
+	term, err := s.Term(ctx, termID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT p.id, p.first_name, p.last_name, p.type, p.age, pc.role
+        FROM person_course pc
+        JOIN person p ON p.id = pc.person_id
+        WHERE pc.course_id = $1 AND pc.term_id = $2
+        ORDER BY p.id`, courseID, term.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		var member Member
+		if err := rows.Scan(&member.ID, &member.FirstName, &member.LastName, &member.Type, &member.Age, &member.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// Enroll adds the person to the course's roster in the term opts names with
+// the role opts names, or to the end of its waitlist for that term when
+// they're enrolling as a student and the course is full. Enrolling someone
+// already on either succeeds without change or event, unless asking for
+// another role. Only professors can be instructors. Students missing any of
+// the course's prerequisites are refused unless opts overrides them, as are
+// students of a course requiring an instructor in a term nobody instructs it,
+// and anyone whose schedule the course clashes with that term.
+func (s *Store) Enroll(ctx context.Context, courseID, personID uint, opts EnrollOptions) (Enrollment, error) {
+	var enrollment Enrollment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// the person is locked before the term and course, like when their
+		// courses are replaced, so their enrollments are checked one at a time.
+		// FOR NO KEY UPDATE leaves the key share a promotion into another
+		// course takes on them free, as that holds its course's lock first.
+		var personType string
+		err := tx.QueryRowContext(ctx, "SELECT type FROM person WHERE id = $1 FOR NO KEY UPDATE", personID).Scan(&personType)
+		if err == sql.ErrNoRows {
+			return invalid("Person ID does not exist: %d", personID)
+		}
+		if err != nil {
+			return err
+		}
+
+		term, err := lockTerm(ctx, tx, opts.Term)
+		if err != nil {
+			return err
+		}
+		capacity, err := lockCourse(ctx, tx, courseID)
+		if err != nil {
+			return err
+		}
+
+		enrollment, err = enroll(ctx, tx, courseID, capacity, term, personID, personType, opts)
+		return err
+	})
+	return enrollment, err
+}
+
+// Unenroll removes the person from the course's roster or waitlist in the
+// term with termID, the current term when 0. A seat freed on the roster goes
+// to the first student waiting that term.
+func (s *Store) Unenroll(ctx context.Context, courseID, personID, termID uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		term, err := lockTerm(ctx, tx, termID)
+		if err != nil {
+			return err
+		}
+		capacity, err := lockCourse(ctx, tx, courseID)
+		if err != nil {
+			return err
+		}
+		return unenroll(ctx, tx, courseID, capacity, term.ID, personID)
+	})
+}
+
+// Teaches reports whether the person instructs the course in the term with
+// termID, the current term when 0.
+func (s *Store) Teaches(ctx context.Context, personID, courseID, termID uint) (bool, error) {
+	var teaches bool
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT EXISTS(
+            SELECT 1 FROM person_course pc
+            WHERE pc.person_id = $1 AND pc.course_id = $2 AND pc.role = 'instructor'
+              AND pc.term_id = (
+                  SELECT id FROM term
+                  WHERE CASE WHEN $3 = 0 THEN start_date <= current_date ELSE id = $3 END
+                  ORDER BY start_date DESC
+                  LIMIT 1)
+        )`, personID, courseID, termID).Scan(&teaches)
+	return teaches, err
+}
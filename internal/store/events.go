@@ -0,0 +1,98 @@
+// change events written to the outbox in the same transaction as the change
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+// Event types published for every change to courses, people, rosters,
+// waitlists, prerequisites, meetings, terms and grades.
+const (
+	EventCourseCreated       = "course.created"
+	EventCourseUpdated       = "course.updated"
+	EventCourseDeleted       = "course.deleted"
+	EventPersonCreated       = "person.created"
+	EventPersonUpdated       = "person.updated"
+	EventPersonDeleted       = "person.deleted"
+	EventEnrollmentAdded     = "enrollment.added"
+	EventEnrollmentRemoved   = "enrollment.removed"
+	EventWaitlistAdded       = "waitlist.added"
+	EventWaitlistRemoved     = "waitlist.removed" // including when promoted to enrolled
+	EventPrerequisiteAdded   = "prerequisite.added"
+	EventPrerequisiteRemoved = "prerequisite.removed"
+	EventMeetingAdded        = "meeting.added"
+	EventMeetingRemoved      = "meeting.removed"
+	EventTermCreated         = "term.created"
+	EventTermUpdated         = "term.updated"
+	EventTermDeleted         = "term.deleted"
+	EventGradeSet            = "grade.set"
+	EventGradeScaleUpdated   = "grade_scale.updated"
+)
+
+// EventTypes lists every event type, in the order they're documented.
+var EventTypes = []string{
+	EventCourseCreated, EventCourseUpdated, EventCourseDeleted,
+	EventPersonCreated, EventPersonUpdated, EventPersonDeleted,
+	EventEnrollmentAdded, EventEnrollmentRemoved,
+	EventWaitlistAdded, EventWaitlistRemoved,
+	EventPrerequisiteAdded, EventPrerequisiteRemoved,
+	EventMeetingAdded, EventMeetingRemoved,
+	EventTermCreated, EventTermUpdated, EventTermDeleted,
+	EventGradeSet, EventGradeScaleUpdated,
+}
+
+// IsEventType reports whether t is one of EventTypes.
+func IsEventType(t string) bool {
+	for _, eventType := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// payload of the person events: the person and their course ids in a term,
+// or in every term they were in when deleted
+type personEvent struct {
+	Person
+	Courses []uint `json:"courses"`
+	TermID  uint   `json:"term_id,omitempty"` // unset when deleted
+}
+
+// EventsChannel is the postgres channel notified with the id of every event
+// once its transaction commits.
+const EventsChannel = "college_events"
+
+// Write an event to the events outbox, queue a webhook delivery for every
+// active subscription to its type and notify EventsChannel. All of it happens
+// in tx, so an event exists, and is announced, exactly when the change it
+// describes was committed.
+func publish(ctx context.Context, tx *sql.Tx, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+        WITH event AS (
+            INSERT INTO events (type, payload) VALUES ($1, $2) RETURNING id
+        ), deliveries AS (
+            INSERT INTO webhook_deliveries (subscription_id, event_id)
+            SELECT s.id, event.id FROM webhook_subscriptions s, event
+            WHERE s.active AND (cardinality(s.event_types) = 0 OR $1 = ANY(s.event_types))
+        )
+        SELECT pg_notify($3, event.id::text) FROM event`,
+		eventType, string(payload), EventsChannel)
+	return err
+}
+
+// Publish an enrollment event for each of enrollments.
+func publishEnrollments(ctx context.Context, tx *sql.Tx, eventType string, enrollments []Enrollment) error {
+	for _, enrollment := range enrollments {
+		if err := publish(ctx, tx, eventType, enrollment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
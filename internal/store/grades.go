@@ -0,0 +1,182 @@
+// grades on enrollments, the grade scale giving their points and GPAs
+package store
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"math"
+	"slices"
+	"strings"
+)
+
+// the data of a grade_scale.updated event
+type scaleEvent struct {
+	Grades []GradeStep `json:"grades"`
+}
+
+// GradeScale returns the letters grades can be given in, best first.
+func (s *Store) GradeScale(ctx context.Context) ([]GradeStep, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT letter, points::float8 FROM grade_scale ORDER BY points DESC, letter")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scale := []GradeStep{}
+	for rows.Next() {
+		var step GradeStep
+		if err := rows.Scan(&step.Letter, &step.Points); err != nil {
+			return nil, err
+		}
+		scale = append(scale, step)
+	}
+	return scale, rows.Err()
+}
+
+// Check the steps of a grade scale, normalizing letters to upper case and
+// points to hundredths, and sort them best first.
+func validateScale(scale []GradeStep) error {
+	if len(scale) == 0 {
+		return invalid("The grade scale needs at least one letter")
+	}
+	seen := make(map[string]bool, len(scale))
+	for i := range scale {
+		step := &scale[i]
+		step.Letter = strings.ToUpper(strings.TrimSpace(step.Letter))
+		if step.Letter == "" {
+			return invalid("Grade letters can't be empty")
+		}
+		if seen[step.Letter] {
+			return invalid("Grade %s is on the scale twice", step.Letter)
+		}
+		seen[step.Letter] = true
+		if step.Points < 0 || step.Points >= 100 {
+			return invalid("Grade %s must be worth between 0 and 99.99 points", step.Letter)
+		}
+		step.Points = math.Round(step.Points*100) / 100
+	}
+	slices.SortFunc(scale, func(a, b GradeStep) int {
+		return cmp.Or(cmp.Compare(b.Points, a.Points), strings.Compare(a.Letter, b.Letter))
+	})
+	return nil
+}
+
+// SetGradeScale replaces the grade scale. Grades already given keep the
+// points they were given with.
+func (s *Store) SetGradeScale(ctx context.Context, scale []GradeStep) ([]GradeStep, error) {
+	scale = append([]GradeStep(nil), scale...)
+	if err := validateScale(scale); err != nil {
+		return nil, err
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM grade_scale"); err != nil {
+			return err
+		}
+		for _, step := range scale {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO grade_scale (letter, points) VALUES ($1, $2)", step.Letter, step.Points); err != nil {
+				return err
+			}
+		}
+		return publish(ctx, tx, EventGradeScaleUpdated, scaleEvent{scale})
+	})
+	return scale, err
+}
+
+// SetGrade grades the student on the course's roster in the term with
+// termID, the current term when 0, with a letter from the grade scale,
+// replacing any grade they had.
+func (s *Store) SetGrade(ctx context.Context, courseID, personID, termID uint, letter string) (Grade, error) {
+	grade := Grade{PersonID: personID, CourseID: courseID, Letter: strings.ToUpper(strings.TrimSpace(letter))}
+	if grade.Letter == "" {
+		return grade, invalid("Grade letter is required")
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		term, err := lockTerm(ctx, tx, termID)
+		if err != nil {
+			return err
+		}
+		grade.TermID = term.ID
+
+		err = tx.QueryRowContext(ctx, "SELECT points::float8 FROM grade_scale WHERE letter = $1", grade.Letter).Scan(&grade.Points)
+		if err == sql.ErrNoRows {
+			return invalid("Grade %s is not on the grade scale", grade.Letter)
+		}
+		if err != nil {
+			return err
+		}
+
+		var role string
+		err = tx.QueryRowContext(ctx, `
+            UPDATE person_course SET grade = $1, grade_points = $2
+            WHERE person_id = $3 AND course_id = $4 AND term_id = $5
+            RETURNING role`, grade.Letter, grade.Points, personID, courseID, term.ID).Scan(&role)
+		if err == sql.ErrNoRows {
+			return notFound("Person %d is not on the roster of course %d in %s", personID, courseID, term.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if role != RoleStudent {
+			return invalid("Only students are graded, person %d is the %s of course %d", personID, role, courseID)
+		}
+		return publish(ctx, tx, EventGradeSet, grade)
+	})
+	return grade, err
+}
+
+// GPA returns the GPA of the person with personID in each term they were
+// graded in and across all of them. Each grade's points are weighted by its
+// course's credits, or 1 when the course has none.
+func (s *Store) GPA(ctx context.Context, personID uint) (GPA, error) {
+	gpa := GPA{PersonID: personID, Terms: []TermGPA{}}
+	var exists bool
+	err := s.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM person WHERE id = $1)", personID).Scan(&exists)
+	if err != nil {
+		return gpa, err
+	}
+	if !exists {
+		return gpa, notFound("Person not found")
+	}
+
+	gpa.Terms, gpa.Cumulative, err = s.termGPAs(ctx, personID)
+	return gpa, err
+}
+
+// Return the GPA of the person with personID in each term they were graded
+// in, in date order, and across all of them.
+func (s *Store) termGPAs(ctx context.Context, personID uint) ([]TermGPA, TermGPA, error) {
+	terms := []TermGPA{}
+	var cumulative TermGPA
+
+	// the rollup's grand total row, with a null term, is the cumulative GPA
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT t.id, t.name, COALESCE(SUM(COALESCE(c.credits, 1)), 0),
+               ROUND(SUM(pc.grade_points * COALESCE(c.credits, 1)) / SUM(COALESCE(c.credits, 1)), 2)::float8
+        FROM person_course pc
+        JOIN course c ON c.id = pc.course_id
+        JOIN term t ON t.id = pc.term_id
+        WHERE pc.person_id = $1 AND pc.grade IS NOT NULL
+        GROUP BY ROLLUP ((t.start_date, t.id, t.name))
+        ORDER BY t.start_date NULLS LAST`, personID)
+	if err != nil {
+		return nil, cumulative, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var termID sql.NullInt64
+		var name sql.NullString
+		var term TermGPA
+		if err := rows.Scan(&termID, &name, &term.Credits, &term.GPA); err != nil {
+			return nil, cumulative, err
+		}
+		if !termID.Valid {
+			cumulative = term
+			continue
+		}
+		term.TermID, term.Term = uint(termID.Int64), name.String
+		terms = append(terms, term)
+	}
+	return terms, cumulative, rows.Err()
+}
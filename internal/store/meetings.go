@@ -0,0 +1,186 @@
+// course meeting schedules and the clash check made before enrolling anyone
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays are the days a course can meet on, in schedule order.
+var Weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Check a meeting's fields, normalizing its times to HH:MM.
+func (m *Meeting) validate() error {
+	valid := false
+	for _, day := range Weekdays {
+		valid = valid || m.Day == day
+	}
+	if !valid {
+		return invalid("day must be one of %s", strings.Join(Weekdays, ", "))
+	}
+
+	start, err := time.Parse("15:04", m.Start)
+	if err != nil {
+		return invalid("start must be a time like 09:00")
+	}
+	end, err := time.Parse("15:04", m.End)
+	if err != nil {
+		return invalid("end must be a time like 10:30")
+	}
+	if !start.Before(end) {
+		return invalid("A meeting must start before it ends")
+	}
+	m.Start, m.End = start.Format("15:04"), end.Format("15:04")
+	return nil
+}
+
+// Meetings returns the weekly meetings of the course with id, in schedule
+// order.
+func (s *Store) Meetings(ctx context.Context, courseID uint) ([]Meeting, error) {
+	if err := checkCourse(ctx, s.DB, courseID); err != nil {
+		return nil, err
+	}
+	meetings, err := s.MeetingsByCourse(ctx, []uint{courseID})
+	return meetings[courseID], err
+}
+
+// AddMeeting adds a weekly meeting to the course with courseID, which it
+// keeps every term. A meeting that would clash with the schedule of anyone
+// enrolled in or waiting for the course in a term that hasn't ended is a
+// conflict.
+func (s *Store) AddMeeting(ctx context.Context, courseID uint, meeting Meeting) (Meeting, error) {
+	meeting.CourseID = courseID
+	if err := meeting.validate(); err != nil {
+		return meeting, err
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := lockCourse(ctx, tx, courseID); err != nil {
+			return err
+		}
+		// waits for enrollments checking their schedules, see checkSchedule
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE course_meeting IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+            WITH courses (person_id, course_id, term_id) AS (
+                SELECT person_id, course_id, term_id FROM person_course
+                UNION
+                SELECT person_id, course_id, term_id FROM waitlist
+            )
+            SELECT DISTINCT p.person_id, c.id, c.name, m.day, to_char(m.start_time, 'HH24:MI'), to_char(m.end_time, 'HH24:MI')
+            FROM courses p
+            JOIN term t ON t.id = p.term_id AND t.end_date >= current_date
+            JOIN courses o ON o.person_id = p.person_id AND o.term_id = p.term_id AND o.course_id <> p.course_id
+            JOIN course_meeting m ON m.course_id = o.course_id
+            JOIN course c ON c.id = m.course_id
+            WHERE p.course_id = $1 AND m.day = $2 AND m.start_time < $4 AND $3 < m.end_time
+            ORDER BY p.person_id, c.id, 5`,
+			courseID, meeting.Day, meeting.Start, meeting.End)
+		if err != nil {
+			return err
+		}
+		var clashes []string
+		for rows.Next() {
+			var personID uint
+			var course Course
+			var slot Meeting
+			if err := rows.Scan(&personID, &course.ID, &course.Name, &slot.Day, &slot.Start, &slot.End); err != nil {
+				rows.Close()
+				return err
+			}
+			clashes = append(clashes, fmt.Sprintf("person %d has %s (%d) %s", personID, course.Name, course.ID, slot.slot()))
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(clashes) > 0 {
+			return conflict("Course %d can't meet %s, it clashes with the schedules of its people: %s",
+				courseID, meeting.slot(), strings.Join(clashes, "; "))
+		}
+
+		err = tx.QueryRowContext(ctx, `
+            INSERT INTO course_meeting (course_id, day, start_time, end_time, location)
+            VALUES ($1, $2, $3, $4, $5)
+            RETURNING id`, courseID, meeting.Day, meeting.Start, meeting.End, meeting.Location).Scan(&meeting.ID)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, tx, EventMeetingAdded, meeting)
+	})
+	return meeting, err
+}
+
+// RemoveMeeting deletes the meeting with meetingID from the course with
+// courseID.
+func (s *Store) RemoveMeeting(ctx context.Context, courseID, meetingID uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		meeting := Meeting{ID: meetingID, CourseID: courseID}
+		err := tx.QueryRowContext(ctx, `
+            DELETE FROM course_meeting WHERE id = $1 AND course_id = $2
+            RETURNING day, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), location`,
+			meetingID, courseID).Scan(&meeting.Day, &meeting.Start, &meeting.End, &meeting.Location)
+		if err == sql.ErrNoRows {
+			return notFound("Meeting %d is not on the schedule of course %d", meetingID, courseID)
+		}
+		if err != nil {
+			return err
+		}
+		return publish(ctx, tx, EventMeetingRemoved, meeting)
+	})
+}
+
+// Describe when the meeting is, like "monday 09:00-10:30".
+func (m Meeting) slot() string {
+	return m.Day + " " + m.Start + "-" + m.End
+}
+
+// Return a conflict naming each of the course's meetings that clashes with
+// a meeting of another course the person is enrolled in or waiting for in
+// term, or nil when none do.
+func checkSchedule(ctx context.Context, tx *sql.Tx, courseID uint, term Term, personID uint) error {
+	// schedules are read under a lock shared by every enrollment, so no
+	// meeting can be added to the person's other courses before tx commits
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE course_meeting IN SHARE MODE"); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT n.day, to_char(n.start_time, 'HH24:MI'), to_char(n.end_time, 'HH24:MI'),
+               c.id, c.name, to_char(m.start_time, 'HH24:MI'), to_char(m.end_time, 'HH24:MI')
+        FROM course_meeting n
+        JOIN course_meeting m ON m.day = n.day AND m.start_time < n.end_time AND n.start_time < m.end_time
+        JOIN course c ON c.id = m.course_id
+        WHERE n.course_id = $1 AND m.course_id <> $1
+          AND m.course_id IN (
+              SELECT course_id FROM person_course WHERE person_id = $2 AND term_id = $3
+              UNION
+              SELECT course_id FROM waitlist WHERE person_id = $2 AND term_id = $3)
+        ORDER BY n.day, n.start_time, c.id, m.start_time`, courseID, personID, term.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var clashes []string
+	for rows.Next() {
+		var meeting, other Meeting
+		var course Course
+		if err := rows.Scan(&meeting.Day, &meeting.Start, &meeting.End, &course.ID, &course.Name, &other.Start, &other.End); err != nil {
+			return err
+		}
+		other.Day = meeting.Day
+		clashes = append(clashes, fmt.Sprintf("%s overlaps %s (%d) %s", meeting.slot(), course.Name, course.ID, other.slot()))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(clashes) == 0 {
+		return nil
+	}
+	return conflict("Course %d clashes with the schedule of person %d in %s: %s", courseID, personID, term.Name, strings.Join(clashes, "; "))
+}
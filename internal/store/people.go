@@ -0,0 +1,343 @@
+// people and their course enrollments
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ListPeople returns the people matching filter in page.
+func (s *Store) ListPeople(ctx context.Context, filter PersonFilter, page Page) ([]Person, error) {
+	conditions, args := filter.Conditions("", nil)
+	query := "SELECT id, first_name, last_name, type, age FROM person"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	clause, args, err := page.clause(args)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	people := []Person{}
+	for rows.Next() {
+		var person Person
+		if err := rows.Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age); err != nil {
+			return nil, err
+		}
+		people = append(people, person)
+	}
+	return people, rows.Err()
+}
+
+// GetPerson returns the person named fullName, first and last name
+// separated by a space.
+func (s *Store) GetPerson(ctx context.Context, fullName string) (Person, error) {
+	var person Person
+	err := s.DB.QueryRowContext(ctx,
+		"SELECT id, first_name, last_name, type, age FROM person WHERE first_name || ' ' || last_name = $1", fullName).
+		Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
+	if err == sql.ErrNoRows {
+		return person, notFound("Person not found")
+	}
+	return person, err
+}
+
+func (input PersonInput) validate() error {
+	if input.FirstName == "" || input.LastName == "" || input.Type == "" || input.Age == 0 {
+		return invalid("Missing required fields")
+	}
+	if input.Type != "student" && input.Type != "professor" {
+		return invalid("type must be student or professor")
+	}
+	if len(input.Roles) > 0 && len(input.Roles) != len(input.Courses) {
+		return invalid("roles must have a role for each of the %d courses", len(input.Courses))
+	}
+	return nil
+}
+
+// Return the role asked for in each of input.Courses, empty for the default.
+func (input PersonInput) roles() map[uint]string {
+	roles := map[uint]string{}
+	for i, role := range input.Roles {
+		roles[input.Courses[i]] = role
+	}
+	return roles
+}
+
+// Return input.Courses, never nil.
+func (input PersonInput) courses() []uint {
+	if input.Courses == nil {
+		return []uint{}
+	}
+	return input.Courses
+}
+
+func (input PersonInput) enrollOptions() EnrollOptions {
+	return EnrollOptions{Term: input.Term, OverridePrerequisites: input.OverridePrerequisites}
+}
+
+func (input PersonInput) person(id uint) Person {
+	return Person{ID: id, FirstName: input.FirstName, LastName: input.LastName, Type: input.Type, Age: input.Age}
+}
+
+// Delete the person's enrollments in every term, returning them in course
+// and term order.
+func clearEnrollments(ctx context.Context, tx *sql.Tx, personID uint) ([]Enrollment, error) {
+	rows, err := tx.QueryContext(ctx, "DELETE FROM person_course WHERE person_id = $1 RETURNING course_id, term_id", personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrollments := []Enrollment{}
+	for rows.Next() {
+		enrollment := Enrollment{PersonID: personID}
+		if err := rows.Scan(&enrollment.CourseID, &enrollment.TermID); err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	sort.Slice(enrollments, func(i, j int) bool {
+		a, b := enrollments[i], enrollments[j]
+		return a.CourseID < b.CourseID || a.CourseID == b.CourseID && a.TermID < b.TermID
+	})
+	return enrollments, rows.Err()
+}
+
+// Return the courses the person is enrolled in or waiting for in a term.
+func currentCourses(ctx context.Context, tx *sql.Tx, personID, termID uint) ([]uint, error) {
+	return readIDs(tx.QueryContext(ctx, `
+        SELECT course_id FROM person_course WHERE person_id = $1 AND term_id = $2
+        UNION ALL
+        SELECT course_id FROM waitlist WHERE person_id = $1 AND term_id = $2`, personID, termID))
+}
+
+// Read a single column of ids.
+func readIDs(rows *sql.Rows, err error) ([]uint, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uint{}
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Move the person from the current courses they're enrolled in or waiting
+// for in the locked term to courses, checking each exists. Courses kept keep
+// their place, dropped ones free their seat for the next student waiting,
+// and new ones enroll or waitlist them like Enroll with opts. New courses are
+// only checked against the schedule left after the drops, each with its role
+// in roles. Returns where they stand in each of courses.
+func setCourses(ctx context.Context, tx *sql.Tx, person Person, term Term, current, courses []uint, roles map[uint]string, opts EnrollOptions) ([]Enrollment, error) {
+	wanted := map[uint]bool{}
+	for _, id := range courses {
+		wanted[id] = true
+	}
+
+	ids := sortedIDs(append(append([]uint{}, current...), courses...))
+	capacities := map[uint]*uint{}
+	for _, courseID := range ids {
+		capacity, err := lockCourse(ctx, tx, courseID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, invalid("Course ID does not exist: %d", courseID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		capacities[courseID] = capacity
+	}
+
+	for _, courseID := range ids {
+		if !wanted[courseID] {
+			if err := unenroll(ctx, tx, courseID, capacities[courseID], term.ID, person.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	enrollments := []Enrollment{}
+	for _, courseID := range ids {
+		if !wanted[courseID] {
+			continue
+		}
+		opts.Role = roles[courseID]
+		enrollment, err := enroll(ctx, tx, courseID, capacities[courseID], term, person.ID, person.Type, opts)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	return enrollments, nil
+}
+
+// CreatePerson adds a person, enrolling or waitlisting them on
+// input.Courses in input.Term, all or nothing. It returns where they stand on
+// each course.
+func (s *Store) CreatePerson(ctx context.Context, input PersonInput) (Person, []Enrollment, error) {
+	if err := input.validate(); err != nil {
+		return Person{}, nil, err
+	}
+
+	var person Person
+	var enrollments []Enrollment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var id uint
+		err := tx.QueryRowContext(ctx, `
+            INSERT INTO person (first_name, last_name, type, age)
+            VALUES ($1, $2, $3, $4)
+            RETURNING id
+        `, input.FirstName, input.LastName, input.Type, input.Age).Scan(&id)
+		if err != nil {
+			return err
+		}
+		person = input.person(id)
+		term, err := lockTerm(ctx, tx, input.Term)
+		if err != nil {
+			return err
+		}
+		if err := publish(ctx, tx, EventPersonCreated, personEvent{person, input.courses(), term.ID}); err != nil {
+			return err
+		}
+		enrollments, err = setCourses(ctx, tx, person, term, nil, input.Courses, input.roles(), input.enrollOptions())
+		return err
+	})
+	if err != nil {
+		return Person{}, nil, err
+	}
+	return person, enrollments, nil
+}
+
+// UpdatePerson replaces the fields of the person named fullName and their
+// courses in input.Term, and returns where they stand on each course.
+func (s *Store) UpdatePerson(ctx context.Context, fullName string, input PersonInput) (Person, []Enrollment, error) {
+	if err := input.validate(); err != nil {
+		return Person{}, nil, err
+	}
+
+	var person Person
+	var enrollments []Enrollment
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var id uint
+		err := tx.QueryRowContext(ctx, "SELECT id FROM person WHERE first_name || ' ' || last_name = $1", fullName).Scan(&id)
+		if err == sql.ErrNoRows {
+			return notFound("Person not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		// a professor instructing a course can't become a student
+		result, err := tx.ExecContext(ctx, `
+            UPDATE person
+            SET first_name = $1, last_name = $2, type = $3, age = $4
+            WHERE id = $5 AND ($3 = 'professor' OR NOT EXISTS(
+                SELECT 1 FROM person_course WHERE person_id = $5 AND role = 'instructor'))
+        `, input.FirstName, input.LastName, input.Type, input.Age, id)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return conflict("Only professors can be instructors, person %d instructs a course", id)
+		}
+		person = input.person(id)
+		term, err := lockTerm(ctx, tx, input.Term)
+		if err != nil {
+			return err
+		}
+		if err := publish(ctx, tx, EventPersonUpdated, personEvent{person, input.courses(), term.ID}); err != nil {
+			return err
+		}
+
+		current, err := currentCourses(ctx, tx, id, term.ID)
+		if err != nil {
+			return err
+		}
+		enrollments, err = setCourses(ctx, tx, person, term, current, input.Courses, input.roles(), input.enrollOptions())
+		return err
+	})
+	if err != nil {
+		return Person{}, nil, err
+	}
+	return person, enrollments, nil
+}
+
+// DeletePerson deletes the person named fullName, their enrollments and
+// their waitlist places in every term.
+func (s *Store) DeletePerson(ctx context.Context, fullName string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// the person then every course they're on is locked, in the order
+		// Enroll and setCourses take them, before anything is deleted
+		var person Person
+		err := tx.QueryRowContext(ctx,
+			"SELECT id, first_name, last_name, type, age FROM person WHERE first_name || ' ' || last_name = $1 FOR NO KEY UPDATE", fullName).
+			Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
+		if err == sql.ErrNoRows {
+			return notFound("Person not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		current, err := readIDs(tx.QueryContext(ctx, `
+            SELECT course_id FROM person_course WHERE person_id = $1
+            UNION
+            SELECT course_id FROM waitlist WHERE person_id = $1`, person.ID))
+		if err != nil {
+			return err
+		}
+		capacities := map[uint]*uint{}
+		for _, courseID := range sortedIDs(current) {
+			if capacities[courseID], err = lockCourse(ctx, tx, courseID); err != nil {
+				return err
+			}
+		}
+
+		enrollments, err := clearEnrollments(ctx, tx, person.ID)
+		if err != nil {
+			return err
+		}
+		// waitlist places are deleted with the person
+		if _, err := tx.ExecContext(ctx, "DELETE FROM person WHERE id = $1", person.ID); err != nil {
+			return err
+		}
+		if err := publishEnrollments(ctx, tx, EventEnrollmentRemoved, enrollments); err != nil {
+			return err
+		}
+		courses := make([]uint, len(enrollments))
+		for i, enrollment := range enrollments {
+			courses[i] = enrollment.CourseID
+		}
+		if err := publish(ctx, tx, EventPersonDeleted, personEvent{Person: person, Courses: sortedIDs(courses)}); err != nil {
+			return err
+		}
+
+		// hand the seats they freed to the students waiting
+		for _, enrollment := range enrollments {
+			capacity := capacities[enrollment.CourseID]
+			if capacity == nil {
+				continue
+			}
+			if err := promote(ctx, tx, enrollment.CourseID, capacity, enrollment.TermID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
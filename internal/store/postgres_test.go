@@ -0,0 +1,258 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+// the seeded terms, Spring 2026 ended before Fall 2026 starts
+const (
+	springTerm = 2
+	fallTerm   = 3
+)
+
+// Return a store on the database STORE_TEST_DATABASE_URL names, freshly
+// seeded with db_seed.sql, or skip the test when it isn't set. Seeding drops
+// the tables, so only point it at a database you can throw away.
+func seededStore(t *testing.T) *Store {
+	dsn := os.Getenv("STORE_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("STORE_TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	seed, err := os.ReadFile("../../db_seed.sql")
+	if err == nil {
+		_, err = db.Exec(string(seed))
+	}
+	if err != nil {
+		t.Fatal("seeding: ", err)
+	}
+	return New(db)
+}
+
+// Create a student named after n.
+func createStudent(t *testing.T, s *Store, n int) Person {
+	person, _, err := s.CreatePerson(context.Background(), PersonInput{
+		FirstName: "Student", LastName: fmt.Sprint(n), Type: "student", Age: 20,
+	})
+	assert.NoError(t, err)
+	return person
+}
+
+// TestEnrollConcurrently tests that students enrolling in a course with one
+// seat at the same time never overfill it: one gets the seat and the rest
+// join its waitlist in distinct places.
+func TestEnrollConcurrently(t *testing.T) {
+	s := seededStore(t)
+	ctx := context.Background()
+
+	capacity := uint(1)
+	course, err := s.CreateCourse(ctx, "Seminar", &capacity, nil, false)
+	assert.NoError(t, err)
+
+	const students = 8
+	people := make([]Person, students)
+	for i := range people {
+		people[i] = createStudent(t, s, i)
+	}
+
+	enrollments := make([]Enrollment, students)
+	errs := make([]error, students)
+	var wg sync.WaitGroup
+	for i, person := range people {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			enrollments[i], errs[i] = s.Enroll(ctx, course.ID, person.ID, EnrollOptions{Term: fallTerm})
+		}()
+	}
+	wg.Wait()
+
+	var enrolled []uint
+	var positions []int
+	for i, enrollment := range enrollments {
+		assert.NoError(t, errs[i])
+		switch enrollment.Status {
+		case StatusEnrolled:
+			enrolled = append(enrolled, enrollment.PersonID)
+		case StatusWaitlisted:
+			positions = append(positions, enrollment.Position)
+		default:
+			t.Errorf("unexpected status %q", enrollment.Status)
+		}
+	}
+	sort.Ints(positions)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, positions)
+
+	roster, err := s.Roster(ctx, course.ID, fallTerm)
+	assert.NoError(t, err)
+	if assert.Len(t, enrolled, 1) && assert.Len(t, roster, 1) {
+		assert.Equal(t, enrolled[0], roster[0].ID)
+	}
+}
+
+// TestEnrollPrerequisites tests that a prerequisite only counts as completed
+// once it was passed as a student in an earlier term.
+func TestEnrollPrerequisites(t *testing.T) {
+	s := seededStore(t)
+	ctx := context.Background()
+
+	// Databases (2) requires Programming (1)
+	tests := []struct {
+		name      string
+		role      string
+		letter    string // empty to leave ungraded
+		completed bool
+	}{
+		{"passed", RoleStudent, "D", true},
+		{"failed", RoleStudent, "F", false},
+		{"ungraded", RoleStudent, "", false},
+		{"assisted", RoleTA, "", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			person := createStudent(t, s, i)
+			_, err := s.Enroll(ctx, 1, person.ID, EnrollOptions{Term: springTerm, Role: tt.role})
+			assert.NoError(t, err)
+			if tt.letter != "" {
+				_, err = s.SetGrade(ctx, 1, person.ID, springTerm, tt.letter)
+				assert.NoError(t, err)
+			}
+
+			_, err = s.Enroll(ctx, 2, person.ID, EnrollOptions{Term: fallTerm})
+			if tt.completed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrConflict))
+			assert.EqualError(t, err, fmt.Sprintf("Person %d is missing prerequisites for course 2 in Fall 2026: Programming (1)", person.ID))
+		})
+	}
+}
+
+// TestDeleteCourseWithRoster tests that a course is deleted along with its
+// roster in every term.
+func TestDeleteCourseWithRoster(t *testing.T) {
+	s := seededStore(t)
+	ctx := context.Background()
+
+	// Programming has people on its roster in Spring and Fall 2026
+	var enrolled, lastEvent int
+	assert.NoError(t, s.DB.QueryRow("SELECT count(*) FROM person_course WHERE course_id = 1").Scan(&enrolled))
+	assert.NoError(t, s.DB.QueryRow("SELECT COALESCE(max(id), 0) FROM events").Scan(&lastEvent))
+	assert.NotZero(t, enrolled)
+
+	assert.NoError(t, s.DeleteCourse(ctx, 1))
+	_, err := s.GetCourse(ctx, 1)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	var left, removed int
+	assert.NoError(t, s.DB.QueryRow("SELECT count(*) FROM person_course WHERE course_id = 1").Scan(&left))
+	assert.Zero(t, left)
+	assert.NoError(t, s.DB.QueryRow("SELECT count(*) FROM events WHERE id > $1 AND type = 'enrollment.removed'", lastEvent).Scan(&removed))
+	assert.Equal(t, enrolled, removed)
+}
+
+// TestEnrollWhilePromoted tests that a waitlisted student enrolling in a
+// course while someone else's change of courses promotes them off the
+// waitlist of another doesn't deadlock: the promotion holds both courses
+// before it needs the student, and the enrollment holds the student before
+// it needs the course.
+func TestEnrollWhilePromoted(t *testing.T) {
+	s := seededStore(t)
+	ctx := context.Background()
+
+	capacity := uint(1)
+	for i := 0; i < 20; i++ {
+		full, err := s.CreateCourse(ctx, fmt.Sprint("Full ", i), &capacity, nil, false)
+		assert.NoError(t, err)
+		open, err := s.CreateCourse(ctx, fmt.Sprint("Open ", i), nil, nil, false)
+		assert.NoError(t, err)
+
+		holder, waiting := createStudent(t, s, 2*i), createStudent(t, s, 2*i+1)
+		_, err = s.Enroll(ctx, full.ID, holder.ID, EnrollOptions{Term: fallTerm})
+		assert.NoError(t, err)
+		enrollment, err := s.Enroll(ctx, full.ID, waiting.ID, EnrollOptions{Term: fallTerm})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusWaitlisted, enrollment.Status)
+
+		// the holder swaps the full course for the open one as the waiting
+		// student enrolls in the open one too
+		var wg sync.WaitGroup
+		var updateErr, enrollErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, updateErr = s.UpdatePerson(ctx, "Student "+fmt.Sprint(2*i), PersonInput{
+				FirstName: holder.FirstName, LastName: holder.LastName, Type: holder.Type, Age: holder.Age,
+				Courses: []uint{open.ID}, Term: fallTerm,
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			_, enrollErr = s.Enroll(ctx, open.ID, waiting.ID, EnrollOptions{Term: fallTerm})
+		}()
+		wg.Wait()
+		assert.NoError(t, updateErr)
+		assert.NoError(t, enrollErr)
+
+		enrollments, err := s.EnrollmentsByPerson(ctx, fallTerm, []uint{waiting.ID})
+		assert.NoError(t, err)
+		assert.Len(t, enrollments[waiting.ID], 2)
+	}
+}
+
+// TestDeletePersonWhilePromoted tests that deleting a student enrolled in a
+// course in one term and waiting for it in another doesn't deadlock with
+// someone dropping it and promoting them.
+func TestDeletePersonWhilePromoted(t *testing.T) {
+	s := seededStore(t)
+	ctx := context.Background()
+
+	capacity := uint(1)
+	for i := 0; i < 20; i++ {
+		course, err := s.CreateCourse(ctx, fmt.Sprint("Seminar ", i), &capacity, nil, false)
+		assert.NoError(t, err)
+
+		holder, deleted := createStudent(t, s, 2*i), createStudent(t, s, 2*i+1)
+		_, err = s.Enroll(ctx, course.ID, deleted.ID, EnrollOptions{Term: springTerm})
+		assert.NoError(t, err)
+		_, err = s.Enroll(ctx, course.ID, holder.ID, EnrollOptions{Term: fallTerm})
+		assert.NoError(t, err)
+		_, err = s.Enroll(ctx, course.ID, deleted.ID, EnrollOptions{Term: fallTerm})
+		assert.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var deleteErr, dropErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			deleteErr = s.DeletePerson(ctx, "Student "+fmt.Sprint(2*i+1))
+		}()
+		go func() {
+			defer wg.Done()
+			dropErr = s.Unenroll(ctx, course.ID, holder.ID, fallTerm)
+		}()
+		wg.Wait()
+		assert.NoError(t, deleteErr)
+		assert.NoError(t, dropErr)
+
+		_, err = s.GetPerson(ctx, "Student "+fmt.Sprint(2*i+1))
+		assert.True(t, errors.Is(err, ErrNotFound))
+	}
+}
@@ -0,0 +1,124 @@
+// course prerequisites and the check made before enrolling students
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Prerequisites returns the courses that must be completed before the
+// course with id can be taken.
+func (s *Store) Prerequisites(ctx context.Context, courseID uint) ([]Course, error) {
+	if err := checkCourse(ctx, s.DB, courseID); err != nil {
+		return nil, err
+	}
+	prerequisites, err := s.PrerequisitesByCourse(ctx, []uint{courseID})
+	return prerequisites[courseID], err
+}
+
+// AddPrerequisite requires the course with prerequisiteID to be completed
+// before the course with courseID. Adding one that would make a course,
+// however indirectly, its own prerequisite is a conflict. Adding one
+// already there succeeds without change or event.
+func (s *Store) AddPrerequisite(ctx context.Context, courseID, prerequisiteID uint) error {
+	if courseID == prerequisiteID {
+		return invalid("A course can't be its own prerequisite")
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// two edges added at once could close a cycle neither sees, so
+		// additions are checked one at a time. Removals and reads don't wait.
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE course_prerequisite IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		var courseExists, prerequisiteExists bool
+		err := tx.QueryRowContext(ctx, `
+            SELECT
+                EXISTS(SELECT 1 FROM course WHERE id = $1),
+                EXISTS(SELECT 1 FROM course WHERE id = $2)`,
+			courseID, prerequisiteID).Scan(&courseExists, &prerequisiteExists)
+		if err != nil {
+			return err
+		}
+		if !courseExists {
+			return notFound("Course not found")
+		}
+		if !prerequisiteExists {
+			return invalid("Prerequisite course ID does not exist: %d", prerequisiteID)
+		}
+
+		// the new edge closes a cycle when the prerequisite already
+		// requires the course
+		var cycle bool
+		err = tx.QueryRowContext(ctx, `
+            WITH RECURSIVE required (id) AS (
+                SELECT prerequisite_id FROM course_prerequisite WHERE course_id = $1
+                UNION
+                SELECT cp.prerequisite_id FROM course_prerequisite cp JOIN required r ON cp.course_id = r.id
+            )
+            SELECT EXISTS(SELECT 1 FROM required WHERE id = $2)`,
+			prerequisiteID, courseID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return conflict("Course %d already requires course %d, so it can't be its prerequisite", prerequisiteID, courseID)
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"INSERT INTO course_prerequisite (course_id, prerequisite_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			courseID, prerequisiteID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return nil
+		}
+		return publish(ctx, tx, EventPrerequisiteAdded, Prerequisite{CourseID: courseID, PrerequisiteID: prerequisiteID})
+	})
+}
+
+// RemovePrerequisite stops requiring the course with prerequisiteID before
+// the course with courseID.
+func (s *Store) RemovePrerequisite(ctx context.Context, courseID, prerequisiteID uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"DELETE FROM course_prerequisite WHERE course_id = $1 AND prerequisite_id = $2", courseID, prerequisiteID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return notFound("Course %d is not a prerequisite of course %d", prerequisiteID, courseID)
+		}
+		return publish(ctx, tx, EventPrerequisiteRemoved, Prerequisite{CourseID: courseID, PrerequisiteID: prerequisiteID})
+	})
+}
+
+// Return a conflict listing the course's prerequisites the person hasn't
+// completed before term, or nil when there are none. A prerequisite counts as
+// completed once the person passed it as a student, with a grade worth more
+// than 0 points, in a term that ended before term starts.
+func checkPrerequisites(ctx context.Context, tx *sql.Tx, courseID uint, term Term, personID uint) error {
+	missing, err := listCourses(tx.QueryContext(ctx, `
+        SELECT c.id, c.name, c.capacity, c.credits, c.requires_instructor
+        FROM course_prerequisite cp
+        JOIN course c ON c.id = cp.prerequisite_id
+        WHERE cp.course_id = $1
+          AND NOT EXISTS(
+              SELECT 1 FROM person_course pc
+              JOIN term t ON t.id = pc.term_id
+              WHERE pc.person_id = $2 AND pc.course_id = cp.prerequisite_id AND t.end_date < $3
+                AND pc.role = 'student' AND pc.grade_points > 0)
+        ORDER BY c.id`, courseID, personID, term.Start))
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	names := make([]string, len(missing))
+	for i, course := range missing {
+		names[i] = fmt.Sprintf("%s (%d)", course.Name, course.ID)
+	}
+	return conflict("Person %d is missing prerequisites for course %d in %s: %s", personID, courseID, term.Name, strings.Join(names, ", "))
+}
@@ -0,0 +1,340 @@
+// data access for courses, people and enrollments shared by the graphql and
+// grpc apis
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotFound matches errors for a course, person, term or enrollment
+	// that doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalid matches errors caused by invalid input, such as a missing
+	// name or an unknown course id.
+	ErrInvalid = errors.New("invalid input")
+	// ErrConflict matches errors for a change the data already stored
+	// rules out, such as a prerequisite cycle, enrolling a student who
+	// hasn't completed a course's prerequisites, a schedule clash or
+	// overlapping terms.
+	ErrConflict = errors.New("conflict")
+)
+
+// storeError carries a message for the caller and the sentinel it matches.
+type storeError struct {
+	kind    error
+	message string
+}
+
+func (e *storeError) Error() string {
+	return e.message
+}
+
+func (e *storeError) Unwrap() error {
+	return e.kind
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &storeError{kind: ErrNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+func invalid(format string, args ...interface{}) error {
+	return &storeError{kind: ErrInvalid, message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) error {
+	return &storeError{kind: ErrConflict, message: fmt.Sprintf(format, args...)}
+}
+
+// json names match the REST api and are used for event payloads
+
+type Course struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Capacity *uint  `json:"capacity,omitempty"` // most students enrolled at once, unlimited when nil
+	Credits  *uint  `json:"credits,omitempty"`  // weight of its grades in a GPA, 1 when nil
+
+	// only take students in terms someone instructs it
+	RequiresInstructor bool `json:"requires_instructor,omitempty"`
+}
+
+type Person struct {
+	ID        uint   `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Type      string `json:"type"` // student or professor
+	Age       uint   `json:"age"`
+}
+
+// FullName is the name people are looked up by.
+func (p Person) FullName() string {
+	return p.FirstName + " " + p.LastName
+}
+
+// Term is an academic term. Every enrollment belongs to one.
+type Term struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`   // YYYY-MM-DD, on or after Start
+}
+
+// PersonInput holds the fields for creating or updating a person. Courses
+// replaces the person's enrollments in Term, the current term when 0.
+type PersonInput struct {
+	FirstName string
+	LastName  string
+	Type      string
+	Age       uint
+	Courses   []uint
+	Term      uint
+
+	// the role in each of Courses by position, or empty for the default
+	// role of the person's type in all of them
+	Roles []string
+
+	// enroll a student in Courses without the prerequisites they're missing
+	OverridePrerequisites bool
+}
+
+// Enrollment statuses. Students asking for a place on a full course are
+// waitlisted instead.
+const (
+	StatusEnrolled   = "enrolled"
+	StatusWaitlisted = "waitlisted"
+)
+
+// Enrollment roles. Only professors can be instructors, and only people
+// enrolled as students take a seat, need the prerequisites and are graded.
+const (
+	RoleInstructor = "instructor"
+	RoleStudent    = "student"
+	RoleTA         = "ta"
+)
+
+// Roles are the roles a person can have in a course.
+var Roles = []string{RoleInstructor, RoleStudent, RoleTA}
+
+// Return the role a person of personType gets when none is asked for.
+func defaultRole(personType string) string {
+	if personType == "professor" {
+		return RoleInstructor
+	}
+	return RoleStudent
+}
+
+type Enrollment struct {
+	PersonID uint   `json:"person_id"`
+	CourseID uint   `json:"course_id"`
+	TermID   uint   `json:"term_id"`
+	Role     string `json:"role,omitempty"`     // unset in removal events
+	Status   string `json:"status,omitempty"`   // enrolled or waitlisted, unset in events
+	Position int    `json:"position,omitempty"` // place on the waitlist from 1, while waitlisted
+}
+
+// Member is a person on a course's roster and their role in it.
+type Member struct {
+	Person
+	Role string `json:"role"`
+}
+
+// EnrollOptions changes how Enroll places a person on a course.
+type EnrollOptions struct {
+	// the term to enroll in, the current term when 0
+	Term uint
+
+	// the role to enroll with, the default for the person's type when
+	// empty: professors instruct and students study
+	Role string
+
+	// enroll a student without the prerequisites they're missing, which
+	// only admins may do
+	OverridePrerequisites bool
+}
+
+// Prerequisite is a course that must be completed before another can be
+// taken.
+type Prerequisite struct {
+	CourseID       uint `json:"course_id"`
+	PrerequisiteID uint `json:"prerequisite_id"`
+}
+
+// Meeting is a weekly meeting of a course.
+type Meeting struct {
+	ID       uint   `json:"id"`
+	CourseID uint   `json:"course_id"`
+	Day      string `json:"day"`   // one of Weekdays
+	Start    string `json:"start"` // HH:MM
+	End      string `json:"end"`   // HH:MM, after Start
+	Location string `json:"location,omitempty"`
+}
+
+// GradeStep is a letter on the grade scale and the points it's worth.
+type GradeStep struct {
+	Letter string  `json:"letter"`
+	Points float64 `json:"points"`
+}
+
+// Grade is the letter a student got for a course in a term, with the points
+// the grade scale gave it when it was set.
+type Grade struct {
+	PersonID uint    `json:"person_id"`
+	CourseID uint    `json:"course_id"`
+	TermID   uint    `json:"term_id"`
+	Letter   string  `json:"letter"`
+	Points   float64 `json:"points"`
+}
+
+// TermGPA is the credit weighted GPA of a person's graded courses in a
+// term, or in every term for the cumulative GPA.
+type TermGPA struct {
+	TermID  uint     `json:"term_id,omitempty"` // unset when cumulative
+	Term    string   `json:"term,omitempty"`
+	Credits uint     `json:"credits"` // of the graded courses, courses without credits count 1
+	GPA     *float64 `json:"gpa"`     // nil without graded courses
+}
+
+// GPA is a person's GPA in each term they were graded in, in date order,
+// and across all of them.
+type GPA struct {
+	PersonID   uint      `json:"person_id"`
+	Terms      []TermGPA `json:"terms"`
+	Cumulative TermGPA   `json:"cumulative"`
+}
+
+// TranscriptCourse is a course on a transcript, with its grade once graded.
+type TranscriptCourse struct {
+	CourseID uint     `json:"course_id"`
+	Name     string   `json:"name"`
+	Credits  uint     `json:"credits"`         // 1 for courses without credits
+	Grade    string   `json:"grade,omitempty"` // empty until graded
+	Points   *float64 `json:"points"`
+}
+
+// TranscriptTerm is a term on a transcript with the courses taken in it.
+type TranscriptTerm struct {
+	TermID        uint               `json:"term_id"`
+	Term          string             `json:"term"`
+	Start         string             `json:"start"`
+	End           string             `json:"end"`
+	Courses       []TranscriptCourse `json:"courses"`
+	Credits       uint               `json:"credits"`        // of every course taken
+	GradedCredits uint               `json:"graded_credits"` // of the courses in GPA
+	GPA           *float64           `json:"gpa"`
+}
+
+// TranscriptTotals sums a transcript over every term.
+type TranscriptTotals struct {
+	Courses       int      `json:"courses"`
+	Credits       uint     `json:"credits"`
+	GradedCredits uint     `json:"graded_credits"`
+	GPA           *float64 `json:"gpa"`
+}
+
+// Transcript is every course a student took, by term in date order, with
+// their grades and totals.
+type Transcript struct {
+	Person Person           `json:"person"`
+	Terms  []TranscriptTerm `json:"terms"`
+	Totals TranscriptTotals `json:"totals"`
+}
+
+// Page selects a slice of a list ordered by id. The zero Page selects
+// everything in table order, like the REST routes without limit and offset.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// PersonFilter narrows ListPeople like the REST name and age query params.
+type PersonFilter struct {
+	Name string // first or last name
+	Age  *int
+}
+
+// Conditions builds the WHERE conditions of f on the person table joined as
+// prefix, appending their values to args.
+func (f PersonFilter) Conditions(prefix string, args []interface{}) ([]string, []interface{}) {
+	var conditions []string
+	if f.Name != "" {
+		args = append(args, f.Name)
+		placeholder := "$" + strconv.Itoa(len(args))
+		conditions = append(conditions, "("+prefix+"first_name = "+placeholder+" OR "+prefix+"last_name = "+placeholder+")")
+	}
+	if f.Age != nil {
+		args = append(args, *f.Age)
+		conditions = append(conditions, prefix+"age = $"+strconv.Itoa(len(args)))
+	}
+	return conditions, args
+}
+
+// Store runs the queries against DB.
+type Store struct {
+	DB *sql.DB
+}
+
+// New returns a store for db.
+func New(db *sql.DB) *Store {
+	return &Store{DB: db}
+}
+
+// Build the ORDER BY, LIMIT and OFFSET clause for page, appending the values
+// to args.
+func (p Page) clause(args []interface{}) (string, []interface{}, error) {
+	if p.Limit < 0 {
+		return "", args, invalid("limit must be a positive integer")
+	}
+	if p.Offset < 0 {
+		return "", args, invalid("offset must be a non-negative integer")
+	}
+	if p.Limit == 0 && p.Offset == 0 {
+		return "", args, nil
+	}
+	clause, args := p.LimitClause(args)
+	return " ORDER BY id" + clause, args, nil
+}
+
+// LimitClause builds the LIMIT and OFFSET clause for page, appending the
+// values to args, for lists outside the store that order themselves.
+func (p Page) LimitClause(args []interface{}) (string, []interface{}) {
+	clause := ""
+	if p.Limit > 0 {
+		args = append(args, p.Limit)
+		clause += " LIMIT $" + strconv.Itoa(len(args))
+	}
+	if p.Offset > 0 {
+		args = append(args, p.Offset)
+		clause += " OFFSET $" + strconv.Itoa(len(args))
+	}
+	return clause, args
+}
+
+// Return "$n, $n+1, ..." for ids and their values as query args.
+func idList(ids []uint) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// Run fn in a transaction, committing when it returns nil.
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSortedIDs tests that ids are sorted without duplicates.
+func TestSortedIDs(t *testing.T) {
+	assert.Equal(t, []uint{}, sortedIDs(nil))
+	assert.Equal(t, []uint{1, 2, 3}, sortedIDs([]uint{3, 1, 2}))
+	assert.Equal(t, []uint{1, 4}, sortedIDs([]uint{4, 1, 4, 1, 1}))
+}
+
+// TestValidate tests the checks made on people, courses, terms and meetings
+// before they're stored.
+func TestValidate(t *testing.T) {
+	zero := uint(0)
+	student := PersonInput{FirstName: "Ada", LastName: "Lovelace", Type: "student", Age: 20}
+	withRoles := student
+	withRoles.Courses, withRoles.Roles = []uint{1, 2}, []string{RoleStudent}
+
+	tests := []struct {
+		name     string
+		validate func() error
+		expected string // empty when valid
+	}{
+		{"person", student.validate, ""},
+		{"person without name", PersonInput{LastName: "Lovelace", Type: "student", Age: 20}.validate, "Missing required fields"},
+		{"person without age", PersonInput{FirstName: "Ada", LastName: "Lovelace", Type: "student"}.validate, "Missing required fields"},
+		{"person of unknown type", PersonInput{FirstName: "Ada", LastName: "Lovelace", Type: "dean", Age: 20}.validate, "type must be student or professor"},
+		{"person missing roles", withRoles.validate, "roles must have a role for each of the 2 courses"},
+		{"course", Course{Name: "Programming"}.validate, ""},
+		{"course without name", Course{}.validate, "Course name is required"},
+		{"course without capacity", Course{Name: "Programming", Capacity: &zero}.validate, "Course capacity must be at least 1"},
+		{"course without credits", Course{Name: "Programming", Credits: &zero}.validate, "Course credits must be at least 1"},
+		{"term", Term{Name: "Fall 2026", Start: "2026-08-31", End: "2026-12-18"}.validate, ""},
+		{"one day term", Term{Name: "Exams", Start: "2026-12-18", End: "2026-12-18"}.validate, ""},
+		{"term without name", Term{Start: "2026-08-31", End: "2026-12-18"}.validate, "Term name is required"},
+		{"term with bad start", Term{Name: "Fall 2026", Start: "31/08/2026", End: "2026-12-18"}.validate, "start must be a date like 2026-09-01"},
+		{"term with bad end", Term{Name: "Fall 2026", Start: "2026-08-31"}.validate, "end must be a date like 2026-12-18"},
+		{"term ending before it starts", Term{Name: "Fall 2026", Start: "2026-12-18", End: "2026-08-31"}.validate, "A term can't end before it starts"},
+		{"meeting", (&Meeting{Day: "monday", Start: "9:00", End: "10:30"}).validate, ""},
+		{"meeting on unknown day", (&Meeting{Day: "Monday", Start: "09:00", End: "10:30"}).validate, "day must be one of monday, tuesday, wednesday, thursday, friday, saturday, sunday"},
+		{"meeting with bad start", (&Meeting{Day: "monday", Start: "9am", End: "10:30"}).validate, "start must be a time like 09:00"},
+		{"meeting with bad end", (&Meeting{Day: "monday", Start: "09:00", End: "25:00"}).validate, "end must be a time like 10:30"},
+		{"empty meeting", (&Meeting{Day: "monday", Start: "09:00", End: "09:00"}).validate, "A meeting must start before it ends"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate()
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expected)
+			assert.True(t, errors.Is(err, ErrInvalid))
+		})
+	}
+
+	// meeting times are normalized
+	meeting := Meeting{Day: "friday", Start: "9:05", End: "12:00"}
+	assert.NoError(t, meeting.validate())
+	assert.Equal(t, "09:05", meeting.Start)
+}
+
+// TestValidateScale tests that grade scales are normalized and sorted best
+// first, and that invalid ones are refused.
+func TestValidateScale(t *testing.T) {
+	scale := []GradeStep{{" c ", 2}, {"b+", 3.333}, {"F", 0}, {"A", 4}, {"P", 2}}
+	assert.NoError(t, validateScale(scale))
+	assert.Equal(t, []GradeStep{{"A", 4}, {"B+", 3.33}, {"C", 2}, {"P", 2}, {"F", 0}}, scale)
+
+	tests := []struct {
+		name     string
+		scale    []GradeStep
+		expected string
+	}{
+		{"empty", nil, "The grade scale needs at least one letter"},
+		{"blank letter", []GradeStep{{"A", 4}, {" ", 1}}, "Grade letters can't be empty"},
+		{"duplicate letter", []GradeStep{{"A", 4}, {"a", 3}}, "Grade A is on the scale twice"},
+		{"negative points", []GradeStep{{"F", -1}}, "Grade F must be worth between 0 and 99.99 points"},
+		{"too many points", []GradeStep{{"A+", 100}}, "Grade A+ must be worth between 0 and 99.99 points"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScale(tt.scale)
+			assert.EqualError(t, err, tt.expected)
+			assert.True(t, errors.Is(err, ErrInvalid))
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// sqlmock expectations for the queries the store makes, shared by the tests
+// of every api built on it
+package storetest
+
+import (
+	"database/sql/driver"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// CurrentTerm is the term the expectations find when no term is given.
+var CurrentTerm = store.Term{ID: 2, Name: "Spring 2026", Start: "2026-01-12", End: "2026-05-15"}
+
+// TermRows returns rows holding terms.
+func TermRows(terms ...store.Term) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "name", "start", "end"})
+	for _, term := range terms {
+		rows.AddRow(term.ID, term.Name, term.Start, term.End)
+	}
+	return rows
+}
+
+// ExpectEvent expects the outbox insert of an event of eventType.
+func ExpectEvent(mock sqlmock.Sqlmock, eventType string) {
+	mock.ExpectExec("WITH event AS \\( INSERT INTO events \\(type, payload\\)").
+		WithArgs(eventType, sqlmock.AnyArg(), store.EventsChannel).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// ExpectLockCourse expects a course's row to be locked, returning its
+// capacity, or no row when capacity is false.
+func ExpectLockCourse(mock sqlmock.Sqlmock, id int, capacity interface{}) {
+	rows := sqlmock.NewRows([]string{"capacity"})
+	if capacity != false {
+		rows.AddRow(capacity)
+	}
+	mock.ExpectQuery("SELECT capacity FROM course WHERE id = \\$1 FOR UPDATE").WithArgs(id).WillReturnRows(rows)
+}
+
+// ExpectTerm expects the term with id, the current term when 0, to be
+// looked up, finding CurrentTerm.
+func ExpectTerm(mock sqlmock.Sqlmock, id int) {
+	mock.ExpectQuery("SELECT id, name, .* FROM term WHERE CASE WHEN \\$1 = 0").WithArgs(id).WillReturnRows(TermRows(CurrentTerm))
+}
+
+// ExpectLockTerm expects the term with id, the current term when 0, to be
+// locked, finding CurrentTerm.
+func ExpectLockTerm(mock sqlmock.Sqlmock, id int) {
+	mock.ExpectQuery("SELECT id, name, .* FROM term WHERE CASE WHEN \\$1 = 0 .* FOR KEY SHARE").WithArgs(id).
+		WillReturnRows(TermRows(CurrentTerm))
+}
+
+// ExpectClashes expects the person's schedule in CurrentTerm to be checked
+// for clashes with a course, finding rows of day, start and end of the
+// course's meeting then id, name, start and end of the other course's.
+func ExpectClashes(mock sqlmock.Sqlmock, courseID, personID int, clashes *sqlmock.Rows) {
+	mock.ExpectExec("LOCK TABLE course_meeting IN SHARE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT n.day, .* FROM course_meeting n").WithArgs(courseID, personID, CurrentTerm.ID).WillReturnRows(clashes)
+}
+
+// ExpectNoClashes expects the person's schedule in CurrentTerm to be
+// checked for clashes with a course, finding none.
+func ExpectNoClashes(mock sqlmock.Sqlmock, courseID, personID int) {
+	ExpectClashes(mock, courseID, personID, sqlmock.NewRows([]string{"day", "start", "end", "id", "name", "other_start", "other_end"}))
+}
+
+// ExpectPersonCourses expects the courses of the people with personIDs in
+// CurrentTerm to be looked up, finding rows of person_id, course_id and role.
+func ExpectPersonCourses(mock sqlmock.Sqlmock, rows *sqlmock.Rows, personIDs ...int) {
+	mock.ExpectQuery("SELECT person_id, course_id, role FROM person_course WHERE person_id IN").
+		WithArgs(idArgs(personIDs)...).WillReturnRows(rows)
+}
+
+// ExpectPersonWaitlist expects the waitlist places of the people with
+// personIDs in CurrentTerm to be looked up, finding rows of person_id,
+// course_id and position.
+func ExpectPersonWaitlist(mock sqlmock.Sqlmock, rows *sqlmock.Rows, personIDs ...int) {
+	mock.ExpectQuery("SELECT w.person_id, w.course_id, .* FROM waitlist w WHERE w.person_id IN").
+		WithArgs(idArgs(personIDs)...).WillReturnRows(rows)
+}
+
+// Return ids followed by CurrentTerm's id as query args.
+func idArgs(ids []int) []driver.Value {
+	args := make([]driver.Value, 0, len(ids)+1)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return append(args, CurrentTerm.ID)
+}
@@ -0,0 +1,189 @@
+// academic terms and the current term enrollments default to
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Select the term with id $1, or the current term, the latest to have
+// started, when $1 is 0.
+const termQuery = `
+        SELECT id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD')
+        FROM term
+        WHERE CASE WHEN $1 = 0 THEN start_date <= current_date ELSE id = $1 END
+        ORDER BY start_date DESC
+        LIMIT 1`
+
+// Read the term selected by termQuery, or a not found error.
+func scanTerm(row *sql.Row, id uint) (Term, error) {
+	var term Term
+	err := row.Scan(&term.ID, &term.Name, &term.Start, &term.End)
+	if err == sql.ErrNoRows && id == 0 {
+		return term, notFound("No term has started yet")
+	}
+	if err == sql.ErrNoRows {
+		return term, notFound("Term not found")
+	}
+	return term, err
+}
+
+// ListTerms returns every term in date order.
+func (s *Store) ListTerms(ctx context.Context) ([]Term, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD')
+        FROM term
+        ORDER BY start_date`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := []Term{}
+	for rows.Next() {
+		var term Term
+		if err := rows.Scan(&term.ID, &term.Name, &term.Start, &term.End); err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, rows.Err()
+}
+
+// Term returns the term with id, or the current term when id is 0. The
+// current term is the latest to have started, so it stays current until the
+// next one starts.
+func (s *Store) Term(ctx context.Context, id uint) (Term, error) {
+	return scanTerm(s.DB.QueryRowContext(ctx, termQuery, id), id)
+}
+
+// Lock the term with id, or the current term when id is 0, until tx ends so
+// it can't be deleted under the enrollments made in it, and return it.
+func lockTerm(ctx context.Context, tx *sql.Tx, id uint) (Term, error) {
+	return scanTerm(tx.QueryRowContext(ctx, termQuery+" FOR KEY SHARE", id), id)
+}
+
+// Check a term's fields.
+func (term Term) validate() error {
+	if term.Name == "" {
+		return invalid("Term name is required")
+	}
+	start, err := time.Parse(time.DateOnly, term.Start)
+	if err != nil {
+		return invalid("start must be a date like 2026-09-01")
+	}
+	end, err := time.Parse(time.DateOnly, term.End)
+	if err != nil {
+		return invalid("end must be a date like 2026-12-18")
+	}
+	if end.Before(start) {
+		return invalid("A term can't end before it starts")
+	}
+	return nil
+}
+
+// Return a conflict when another term has the term's name or overlaps its
+// dates. Terms are locked against other changes until tx ends, so two
+// overlapping terms can't both pass.
+func checkTerm(ctx context.Context, tx *sql.Tx, term Term) error {
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE term IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+
+	var other Term
+	err := tx.QueryRowContext(ctx, `
+        SELECT id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD')
+        FROM term
+        WHERE id <> $1 AND (name = $2 OR (start_date <= $4 AND $3 <= end_date))
+        ORDER BY start_date
+        LIMIT 1`, term.ID, term.Name, term.Start, term.End).Scan(&other.ID, &other.Name, &other.Start, &other.End)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.Name == term.Name {
+		return conflict("A term named %s already exists", term.Name)
+	}
+	return conflict("Term %s overlaps %s (%d), which runs from %s to %s", term.Name, other.Name, other.ID, other.Start, other.End)
+}
+
+// CreateTerm adds a term. Terms can't share a name or overlap.
+func (s *Store) CreateTerm(ctx context.Context, term Term) (Term, error) {
+	term.ID = 0
+	if err := term.validate(); err != nil {
+		return term, err
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkTerm(ctx, tx, term); err != nil {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, "INSERT INTO term (name, start_date, end_date) VALUES ($1, $2, $3) RETURNING id",
+			term.Name, term.Start, term.End).Scan(&term.ID)
+		if err != nil {
+			return err
+		}
+		return publish(ctx, tx, EventTermCreated, term)
+	})
+	return term, err
+}
+
+// UpdateTerm replaces the name and dates of the term with id. Moving its
+// dates leaves its enrollments in it.
+func (s *Store) UpdateTerm(ctx context.Context, id uint, term Term) (Term, error) {
+	term.ID = id
+	if err := term.validate(); err != nil {
+		return term, err
+	}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkTerm(ctx, tx, term); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, "UPDATE term SET name = $1, start_date = $2, end_date = $3 WHERE id = $4",
+			term.Name, term.Start, term.End, id)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return notFound("Term not found")
+		}
+		return publish(ctx, tx, EventTermUpdated, term)
+	})
+	return term, err
+}
+
+// DeleteTerm deletes the term with id. Terms anyone is enrolled in or
+// waiting for a course in are a conflict, so no history is lost.
+func (s *Store) DeleteTerm(ctx context.Context, id uint) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		// waits for enrollments holding the term, see lockTerm
+		term := Term{ID: id}
+		err := tx.QueryRowContext(ctx, `
+            SELECT name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD')
+            FROM term WHERE id = $1 FOR UPDATE`, id).Scan(&term.Name, &term.Start, &term.End)
+		if err == sql.ErrNoRows {
+			return notFound("Term not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		var used bool
+		err = tx.QueryRowContext(ctx, `
+            SELECT EXISTS(SELECT 1 FROM person_course WHERE term_id = $1)
+                OR EXISTS(SELECT 1 FROM waitlist WHERE term_id = $1)`, id).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used {
+			return conflict("Term %s has enrollments, so it can't be deleted", term.Name)
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM term WHERE id = $1", id); err != nil {
+			return err
+		}
+		return publish(ctx, tx, EventTermDeleted, term)
+	})
+}
@@ -0,0 +1,76 @@
+// transcripts of every course a student took with their grades and GPAs
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Transcript returns every course the student with personID took, by term
+// in date order, with their grades, credits and GPAs. Courses not graded
+// yet are listed without a grade. Professors have no transcript.
+func (s *Store) Transcript(ctx context.Context, personID uint) (Transcript, error) {
+	transcript := Transcript{Terms: []TranscriptTerm{}}
+	person := &transcript.Person
+	err := s.DB.QueryRowContext(ctx, "SELECT id, first_name, last_name, type, age FROM person WHERE id = $1", personID).
+		Scan(&person.ID, &person.FirstName, &person.LastName, &person.Type, &person.Age)
+	if err == sql.ErrNoRows {
+		return transcript, notFound("Person not found")
+	}
+	if err != nil {
+		return transcript, err
+	}
+	if person.Type != "student" {
+		return transcript, invalid("Only students have transcripts, person %d is a %s", personID, person.Type)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT t.id, t.name, to_char(t.start_date, 'YYYY-MM-DD'), to_char(t.end_date, 'YYYY-MM-DD'),
+               c.id, c.name, COALESCE(c.credits, 1), COALESCE(pc.grade, ''), pc.grade_points::float8
+        FROM person_course pc
+        JOIN course c ON c.id = pc.course_id
+        JOIN term t ON t.id = pc.term_id
+        WHERE pc.person_id = $1 AND pc.role = 'student'
+        ORDER BY t.start_date, c.name, c.id`, personID)
+	if err != nil {
+		return transcript, err
+	}
+	defer rows.Close()
+
+	index := make(map[uint]int) // position of each term in transcript.Terms
+	for rows.Next() {
+		var term TranscriptTerm
+		var course TranscriptCourse
+		err := rows.Scan(&term.TermID, &term.Term, &term.Start, &term.End,
+			&course.CourseID, &course.Name, &course.Credits, &course.Grade, &course.Points)
+		if err != nil {
+			return transcript, err
+		}
+		i, ok := index[term.TermID]
+		if !ok {
+			i = len(transcript.Terms)
+			index[term.TermID] = i
+			term.Courses = []TranscriptCourse{}
+			transcript.Terms = append(transcript.Terms, term)
+		}
+		transcript.Terms[i].Courses = append(transcript.Terms[i].Courses, course)
+		transcript.Terms[i].Credits += course.Credits
+		transcript.Totals.Courses++
+		transcript.Totals.Credits += course.Credits
+	}
+	if err := rows.Err(); err != nil {
+		return transcript, err
+	}
+
+	terms, cumulative, err := s.termGPAs(ctx, personID)
+	if err != nil {
+		return transcript, err
+	}
+	for _, gpa := range terms {
+		if i, ok := index[gpa.TermID]; ok {
+			transcript.Terms[i].GradedCredits, transcript.Terms[i].GPA = gpa.Credits, gpa.GPA
+		}
+	}
+	transcript.Totals.GradedCredits, transcript.Totals.GPA = cumulative.Credits, cumulative.GPA
+	return transcript, nil
+}
@@ -0,0 +1,243 @@
+// course capacity, waitlists and promotion off them
+package store
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Lock the course's row until tx ends, so changes to its roster are made
+// one at a time, and return its capacity.
+func lockCourse(ctx context.Context, tx *sql.Tx, courseID uint) (*uint, error) {
+	var capacity *uint
+	err := tx.QueryRowContext(ctx, "SELECT capacity FROM course WHERE id = $1 FOR UPDATE", courseID).Scan(&capacity)
+	if err == sql.ErrNoRows {
+		return nil, notFound("Course not found")
+	}
+	return capacity, err
+}
+
+// Count the students enrolled in and waiting for a course in a term. Each
+// term has the course's capacity, which instructors and TAs don't take from.
+func seats(ctx context.Context, tx *sql.Tx, courseID, termID uint) (enrolled, waiting int, err error) {
+	err = tx.QueryRowContext(ctx, `
+        SELECT
+            (SELECT count(*) FROM person_course
+             WHERE course_id = $1 AND term_id = $2 AND role = 'student'),
+            (SELECT count(*) FROM waitlist WHERE course_id = $1 AND term_id = $2)`,
+		courseID, termID).Scan(&enrolled, &waiting)
+	return enrolled, waiting, err
+}
+
+// Check role is one of Roles and that only professors instruct.
+func checkRole(role string, personID uint, personType string) error {
+	if !slices.Contains(Roles, role) {
+		return invalid("role must be one of %s", strings.Join(Roles, ", "))
+	}
+	if role == RoleInstructor && personType != "professor" {
+		return invalid("Only professors can be instructors, person %d is a %s", personID, personType)
+	}
+	return nil
+}
+
+// Enroll the locked person in the locked course in the locked term with the
+// role opts names, or the default for their type, or waitlist them when
+// they're enrolling as a student and the course is full or has others
+// waiting that term. Someone already enrolled or waitlisted keeps their place
+// without an event, unless opts asks for another role. Anyone else mustn't
+// have a schedule clash and, when enrolling as a student, needs an
+// instructor when the course requires one and its prerequisites unless opts
+// overrides them.
+func enroll(ctx context.Context, tx *sql.Tx, courseID uint, capacity *uint, term Term, personID uint, personType string, opts EnrollOptions) (Enrollment, error) {
+	role := opts.Role
+	if role == "" {
+		role = defaultRole(personType)
+	}
+	if err := checkRole(role, personID, personType); err != nil {
+		return Enrollment{}, err
+	}
+	enrollment := Enrollment{PersonID: personID, CourseID: courseID, TermID: term.ID, Role: role, Status: StatusEnrolled}
+
+	// waitlisted people are always waiting to be students
+	var current string
+	var needsInstructor bool
+	err := tx.QueryRowContext(ctx, `
+        SELECT
+            COALESCE((SELECT role FROM person_course WHERE person_id = $1 AND course_id = $2 AND term_id = $3), ''),
+            (SELECT count(*) FROM waitlist
+             WHERE course_id = $2 AND term_id = $3
+               AND id <= (SELECT id FROM waitlist WHERE person_id = $1 AND course_id = $2 AND term_id = $3)),
+            (SELECT requires_instructor AND NOT EXISTS(
+                 SELECT 1 FROM person_course WHERE course_id = $2 AND term_id = $3 AND role = 'instructor')
+             FROM course WHERE id = $2)`,
+		personID, courseID, term.ID).Scan(&current, &enrollment.Position, &needsInstructor)
+	if err != nil {
+		return enrollment, err
+	}
+	if enrollment.Position > 0 {
+		current = RoleStudent
+		enrollment.Status = StatusWaitlisted
+	}
+	if current != "" {
+		if opts.Role != "" && opts.Role != current {
+			return enrollment, conflict("Person %d is already the %s of course %d in %s, remove them to change their role",
+				personID, current, courseID, term.Name)
+		}
+		enrollment.Role = current
+		return enrollment, nil
+	}
+
+	if role == RoleStudent && needsInstructor {
+		return enrollment, conflict("Course %d needs an instructor in %s before students can enroll", courseID, term.Name)
+	}
+	if role == RoleStudent && !opts.OverridePrerequisites {
+		if err := checkPrerequisites(ctx, tx, courseID, term, personID); err != nil {
+			return enrollment, err
+		}
+	}
+	if err := checkSchedule(ctx, tx, courseID, term, personID); err != nil {
+		return enrollment, err
+	}
+
+	if capacity != nil && role == RoleStudent {
+		taken, waiting, err := seats(ctx, tx, courseID, term.ID)
+		if err != nil {
+			return enrollment, err
+		}
+		if waiting > 0 || taken >= int(*capacity) {
+			_, err := tx.ExecContext(ctx, "INSERT INTO waitlist (person_id, course_id, term_id) VALUES ($1, $2, $3)",
+				personID, courseID, term.ID)
+			if err != nil {
+				return enrollment, err
+			}
+			enrollment.Status, enrollment.Position = StatusWaitlisted, waiting+1
+			return enrollment, publish(ctx, tx, EventWaitlistAdded, enrollment)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO person_course (person_id, course_id, term_id, role) VALUES ($1, $2, $3, $4)",
+		personID, courseID, term.ID, role)
+	if err != nil {
+		return enrollment, err
+	}
+	return enrollment, publish(ctx, tx, EventEnrollmentAdded, Enrollment{PersonID: personID, CourseID: courseID, TermID: term.ID, Role: role})
+}
+
+// Remove the person from the locked course's roster in a term, or failing
+// that its waitlist, promoting the next students into a freed seat.
+func unenroll(ctx context.Context, tx *sql.Tx, courseID uint, capacity *uint, termID, personID uint) error {
+	enrollment := Enrollment{PersonID: personID, CourseID: courseID, TermID: termID}
+	result, err := tx.ExecContext(ctx, "DELETE FROM person_course WHERE person_id = $1 AND course_id = $2 AND term_id = $3",
+		personID, courseID, termID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		result, err := tx.ExecContext(ctx, "DELETE FROM waitlist WHERE person_id = $1 AND course_id = $2 AND term_id = $3",
+			personID, courseID, termID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return notFound("Person is not on the course roster or waitlist")
+		}
+		return publish(ctx, tx, EventWaitlistRemoved, enrollment)
+	}
+
+	if err := publish(ctx, tx, EventEnrollmentRemoved, enrollment); err != nil {
+		return err
+	}
+	if capacity == nil {
+		return nil
+	}
+	return promote(ctx, tx, courseID, capacity, termID)
+}
+
+// Enroll students from the front of the locked course's waitlist in a term
+// while it has free seats, or all of them when it has no capacity.
+func promote(ctx context.Context, tx *sql.Tx, courseID uint, capacity *uint, termID uint) error {
+	var limit interface{} // NULL, no limit
+	if capacity != nil {
+		enrolled, waiting, err := seats(ctx, tx, courseID, termID)
+		if err != nil {
+			return err
+		}
+		if waiting == 0 || enrolled >= int(*capacity) {
+			return nil
+		}
+		limit = int(*capacity) - enrolled
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        WITH promoted AS (
+            DELETE FROM waitlist WHERE id IN (
+                SELECT id FROM waitlist WHERE course_id = $1 AND term_id = $2 ORDER BY id LIMIT $3
+            )
+            RETURNING id, person_id
+        )
+        INSERT INTO person_course (person_id, course_id, term_id)
+        SELECT person_id, $1, $2 FROM promoted ORDER BY id
+        RETURNING person_id`, courseID, termID, limit)
+	if err != nil {
+		return err
+	}
+	var promoted []uint
+	for rows.Next() {
+		var personID uint
+		if err := rows.Scan(&personID); err != nil {
+			rows.Close()
+			return err
+		}
+		promoted = append(promoted, personID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, personID := range promoted {
+		enrollment := Enrollment{PersonID: personID, CourseID: courseID, TermID: termID}
+		if err := publish(ctx, tx, EventWaitlistRemoved, enrollment); err != nil {
+			return err
+		}
+		enrollment.Role = RoleStudent
+		if err := publish(ctx, tx, EventEnrollmentAdded, enrollment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Promote students off every waitlist the locked course has, a term at a
+// time.
+func promoteAll(ctx context.Context, tx *sql.Tx, courseID uint, capacity *uint) error {
+	terms, err := readIDs(tx.QueryContext(ctx,
+		"SELECT DISTINCT term_id FROM waitlist WHERE course_id = $1 ORDER BY term_id", courseID))
+	if err != nil {
+		return err
+	}
+	for _, termID := range terms {
+		if err := promote(ctx, tx, courseID, capacity, termID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return ids sorted without duplicates. Course rows are always locked in id
+// order so two transactions can't deadlock waiting on each other's courses.
+func sortedIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	sorted := []uint{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
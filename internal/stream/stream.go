@@ -0,0 +1,282 @@
+// live change events fanned out to server-sent event streams
+package stream
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/database"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/store"
+)
+
+// Event is a committed change read from the events outbox. It marshals to
+// the same envelope webhooks are delivered in.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+
+	// position in commit order, which streams resume after. ids are handed
+	// out before commit, so a lower id can commit later.
+	Seq int64 `json:"-"`
+}
+
+// Resource returns the kind of record the event is about: course, person or
+// enrollment.
+func (e Event) Resource() string {
+	resource, _, _ := strings.Cut(e.Type, ".")
+	return resource
+}
+
+// Filter selects the events a stream receives. The zero Filter matches
+// everything.
+type Filter struct {
+	Types    map[string]bool // event types, any when empty
+	CourseID uint            // only events about this course and its enrollments, any when 0
+	PersonID uint            // only events about this person and their enrollments, any when 0
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Event) bool {
+	if len(f.Types) > 0 && !f.Types[e.Type] {
+		return false
+	}
+	if f.CourseID == 0 && f.PersonID == 0 {
+		return true
+	}
+
+	var data struct {
+		ID       uint `json:"id"`
+		PersonID uint `json:"person_id"`
+		CourseID uint `json:"course_id"`
+	}
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return false
+	}
+	switch e.Resource() {
+	case "course":
+		data.CourseID = data.ID
+	case "person":
+		data.PersonID = data.ID
+	}
+	return (f.CourseID == 0 || f.CourseID == data.CourseID) &&
+		(f.PersonID == 0 || f.PersonID == data.PersonID)
+}
+
+// how many events a subscriber may fall behind before it's dropped
+const subscriberBuffer = 64
+
+// Broker listens for events committed by any replica and fans them out to
+// the streams subscribed on this one. Subscribers that fall behind, or are
+// subscribed while the listener reconnects, are dropped and expected to
+// reconnect and resume from history.
+type Broker struct {
+	DB           *sql.DB
+	History      time.Duration // how far back streams can resume
+	HistoryLimit int           // most events replayed on resume
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+// NewBroker returns a broker for db with the default history of a day and
+// 1000 events.
+func NewBroker(db *sql.DB) *Broker {
+	return &Broker{DB: db, History: 24 * time.Hour, HistoryLimit: 1000}
+}
+
+// how often events older than History are pruned
+const pruneInterval = time.Hour
+
+// Run listens for events until ctx is done, reconnecting when the
+// connection is lost, then closes every subscription. Meanwhile it prunes
+// events older than History every hour.
+func (b *Broker) Run(ctx context.Context) {
+	defer b.close()
+	go b.pruneEvery(ctx, pruneInterval)
+
+	delay := time.Second
+	for {
+		started := time.Now()
+		err := database.Listen(ctx, b.DB, store.EventsChannel, func(payload string) {
+			b.notified(ctx, payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Error("Error listening for events", "error", err)
+
+		// anything sent while reconnecting is missed, so subscribers resume
+		// from history instead
+		b.dropAll()
+		if time.Since(started) > time.Minute {
+			delay = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, 30*time.Second)
+	}
+}
+
+// Load and broadcast the event whose id was notified.
+func (b *Broker) notified(ctx context.Context, payload string) {
+	id, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		slog.Warn("Ignoring malformed event notification", "payload", payload)
+		return
+	}
+	var event Event
+	var data []byte
+	err = b.DB.QueryRowContext(ctx, "SELECT id, type, payload, created_at, commit_seq FROM events WHERE id = $1", id).
+		Scan(&event.ID, &event.Type, &data, &event.CreatedAt, &event.Seq)
+	if err != nil {
+		slog.Error("Error loading event", "event", id, "error", err)
+		return
+	}
+	event.Data = data
+	b.Broadcast(event)
+}
+
+// Subscribe returns a channel of every event broadcast from now on and a
+// function to unsubscribe. The channel is closed when the subscriber is
+// dropped or the broker stops.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subscribers == nil {
+		b.subscribers = map[chan Event]struct{}{}
+	}
+	b.subscribers[ch] = struct{}{}
+	return ch, func() { b.drop(ch) }
+}
+
+// Broadcast sends event to every subscriber, dropping those whose buffer
+// is full.
+func (b *Broker) Broadcast(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *Broker) drop(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (b *Broker) dropAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		close(ch)
+	}
+	b.subscribers = nil
+}
+
+func (b *Broker) close() {
+	b.dropAll()
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+}
+
+// Replay returns the events committed after the event with Seq after, in
+// commit order. ok is false when they can't all be replayed: after is older
+// than History, or more than HistoryLimit events have happened since.
+func (b *Broker) Replay(ctx context.Context, after int64) (events []Event, ok bool, err error) {
+	var inHistory bool
+	err = b.DB.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM events WHERE commit_seq = $1 AND created_at > now() - make_interval(secs => $2))",
+		after, b.History.Seconds()).Scan(&inHistory)
+	if err != nil || !inHistory {
+		return nil, false, err
+	}
+
+	rows, err := b.DB.QueryContext(ctx,
+		"SELECT id, type, payload, created_at, commit_seq FROM events WHERE commit_seq > $1 ORDER BY commit_seq LIMIT $2",
+		after, b.HistoryLimit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	events = []Event{}
+	for rows.Next() {
+		var event Event
+		var data []byte
+		if err := rows.Scan(&event.ID, &event.Type, &data, &event.CreatedAt, &event.Seq); err != nil {
+			return nil, false, err
+		}
+		event.Data = data
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if len(events) > b.HistoryLimit {
+		return nil, false, nil
+	}
+	return events, true, nil
+}
+
+// Prune every interval until ctx is done.
+func (b *Broker) pruneEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := b.Prune(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Error pruning events", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune deletes the events older than History, which streams can no longer
+// resume from, and their finished webhook deliveries. Events with deliveries
+// still pending are kept until they're delivered or dead.
+func (b *Broker) Prune(ctx context.Context) error {
+	_, err := b.DB.ExecContext(ctx, `
+        DELETE FROM webhook_deliveries d USING events e
+        WHERE e.id = d.event_id AND e.created_at < now() - make_interval(secs => $1) AND d.status <> 'pending'`,
+		b.History.Seconds())
+	if err != nil {
+		return err
+	}
+	_, err = b.DB.ExecContext(ctx, `
+        DELETE FROM events e
+        WHERE e.created_at < now() - make_interval(secs => $1)
+          AND NOT EXISTS(SELECT 1 FROM webhook_deliveries d WHERE d.event_id = e.id)`,
+		b.History.Seconds())
+	return err
+}
@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFilter tests matching events by type, course and person.
+func TestFilter(t *testing.T) {
+	course := Event{Type: "course.updated", Data: []byte(`{"id":2,"name":"Art"}`)}
+	person := Event{Type: "person.created", Data: []byte(`{"id":3,"first_name":"Ada","courses":[2]}`)}
+	enrollment := Event{Type: "enrollment.added", Data: []byte(`{"person_id":3,"course_id":2}`)}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []bool // course, person, enrollment
+	}{
+		{"everything", Filter{}, []bool{true, true, true}},
+		{"types", Filter{Types: map[string]bool{"course.updated": true, "enrollment.added": true}}, []bool{true, false, true}},
+		{"course", Filter{CourseID: 2}, []bool{true, false, true}},
+		{"other course", Filter{CourseID: 5}, []bool{false, false, false}},
+		{"person", Filter{PersonID: 3}, []bool{false, true, true}},
+		{"course and person", Filter{CourseID: 2, PersonID: 3}, []bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, []bool{tt.filter.Match(course), tt.filter.Match(person), tt.filter.Match(enrollment)})
+		})
+	}
+}
+
+// TestBroadcast tests that slow subscribers are dropped and that nothing
+// can subscribe once the broker has stopped.
+func TestBroadcast(t *testing.T) {
+	b := &Broker{}
+	slow, _ := b.Subscribe()
+	fast, unsubscribe := b.Subscribe()
+
+	for i := int64(1); i <= subscriberBuffer+1; i++ {
+		b.Broadcast(Event{ID: i})
+		if i <= subscriberBuffer {
+			assert.Equal(t, i, (<-fast).ID)
+		}
+	}
+	assert.Equal(t, Event{ID: subscriberBuffer + 1}, <-fast)
+	unsubscribe()
+	_, ok := <-fast
+	assert.False(t, ok)
+
+	// slow received the buffered events, then was closed
+	for i := int64(1); i <= subscriberBuffer; i++ {
+		assert.Equal(t, i, (<-slow).ID)
+	}
+	_, ok = <-slow
+	assert.False(t, ok)
+
+	b.close()
+	closed, _ := b.Subscribe()
+	_, ok = <-closed
+	assert.False(t, ok)
+}
+
+// TestReplay tests replaying history and refusing when it's been lost.
+func TestReplay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	b := &Broker{DB: db, History: time.Hour, HistoryLimit: 2}
+	ctx := context.Background()
+	created := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	expectHistory := func(after int64, inHistory bool) {
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM events WHERE commit_seq = \\$1").WithArgs(after, 3600.0).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(inHistory))
+	}
+
+	expectHistory(4, true)
+	mock.ExpectQuery("SELECT id, type, payload, created_at, commit_seq FROM events WHERE commit_seq > \\$1 ORDER BY commit_seq").WithArgs(4, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "payload", "created_at", "commit_seq"}).
+			AddRow(5, "course.created", []byte(`{"id":4}`), created, 6))
+	events, ok, err := b.Replay(ctx, 4)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []Event{{ID: 5, Type: "course.created", CreatedAt: created, Data: []byte(`{"id":4}`), Seq: 6}}, events)
+
+	// more than HistoryLimit events behind
+	expectHistory(1, true)
+	mock.ExpectQuery("SELECT id, type, payload, created_at, commit_seq FROM events WHERE commit_seq > \\$1 ORDER BY commit_seq").WithArgs(1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "payload", "created_at", "commit_seq"}).
+			AddRow(2, "course.created", []byte(`{}`), created, 2).
+			AddRow(3, "course.created", []byte(`{}`), created, 3).
+			AddRow(4, "course.created", []byte(`{}`), created, 4))
+	_, ok, err = b.Replay(ctx, 1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// older than History
+	expectHistory(1, false)
+	_, ok, err = b.Replay(ctx, 1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPrune tests that events older than History are deleted after their
+// finished deliveries.
+func TestPrune(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	b := &Broker{DB: db, History: time.Hour}
+	mock.ExpectExec("DELETE FROM webhook_deliveries d USING events e WHERE .* AND d.status <> 'pending'").WithArgs(3600.0).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM events e WHERE e.created_at < now\\(\\) - make_interval\\(secs => \\$1\\) AND NOT EXISTS").WithArgs(3600.0).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	assert.NoError(t, b.Prune(context.Background()))
+
+	mock.ExpectExec("DELETE FROM webhook_deliveries").WithArgs(3600.0).WillReturnError(assert.AnError)
+	assert.Equal(t, assert.AnError, b.Prune(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/database"
+)
+
+// Install an in-memory exporter as the global tracer provider for a test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+// TestSpans tests the server span, the child query spans and traceparent
+// propagation.
+func TestSpans(t *testing.T) {
+	exporter := recordSpans(t)
+
+	mockDB, mock, err := sqlmock.NewWithDSN("tracing-spans")
+	assert.NoError(t, err)
+	defer mockDB.Close()
+	db, err := database.Open(mockDB.Driver(), "tracing-spans", QueryHook)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, name FROM course WHERE id = \\$1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Math"))
+	mock.ExpectExec("UPDATE course SET name = \\$1 WHERE id = \\$2").WillReturnError(assert.AnError)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Put("/api/course/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var id int
+		var name string
+		db.QueryRowContext(r.Context(), "SELECT id, name FROM course WHERE id = $1", 1).Scan(&id, &name)
+		if _, err := db.ExecContext(r.Context(), "UPDATE course SET name = $1 WHERE id = $2", "Art", 1); err != nil {
+			http.Error(w, "Error updating course: "+err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	req := httptest.NewRequest("PUT", "/api/course/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	server, ok := byName["PUT /api/course/{id}"]
+	assert.True(t, ok)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.True(t, server.Parent.IsRemote())
+	assert.Equal(t, codes.Error, server.Status.Code)
+
+	for _, name := range []string{"select course", "update course"} {
+		query, ok := byName[name]
+		assert.True(t, ok, name)
+		assert.Equal(t, trace.SpanKindClient, query.SpanKind)
+		assert.Equal(t, server.SpanContext.SpanID(), query.Parent.SpanID())
+		assert.Equal(t, server.SpanContext.TraceID(), query.SpanContext.TraceID())
+	}
+	assert.Equal(t, codes.Error, byName["update course"].Status.Code)
+	assert.Equal(t, codes.Unset, byName["select course"].Status.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSetup tests exporter selection.
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := Setup(context.Background(), "")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	shutdown, err = Setup(context.Background(), "stdout")
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), "zipkin")
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+// background delivery of queued webhooks with retries and dead-lettering
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Delivery statuses stored in webhook_deliveries.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead" // gave up after MaxAttempts, retried only by hand
+)
+
+// Dispatcher sends the deliveries queued in webhook_deliveries. Several
+// replicas may run one each: deliveries are claimed with SKIP LOCKED and
+// leased, so each attempt is made by one dispatcher and a crashed one's
+// claims are retried once the lease expires.
+type Dispatcher struct {
+	DB          *sql.DB
+	Client      *http.Client
+	Interval    time.Duration // how often to look for due deliveries
+	BatchSize   int           // most deliveries claimed per poll
+	Lease       time.Duration // how long a claim lasts, longer than the client timeout
+	MaxAttempts int           // failed attempts before a delivery is dead
+	Backoff     func(attempt int) time.Duration
+}
+
+// NewDispatcher returns a dispatcher for db with the default settings.
+func NewDispatcher(db *sql.DB) *Dispatcher {
+	return &Dispatcher{
+		DB:          db,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Interval:    time.Second,
+		BatchSize:   20,
+		Lease:       time.Minute,
+		MaxAttempts: 10,
+		Backoff:     Backoff,
+	}
+}
+
+// envelope is the body of every delivery.
+type envelope struct {
+	ID        int64           `json:"id"` // event id
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// a claimed delivery and what's needed to send it
+type delivery struct {
+	id       int64
+	attempts int
+	url      string
+	secret   string
+	event    envelope
+}
+
+// Run delivers due webhooks every Interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Error delivering webhooks", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims up to BatchSize due deliveries, sends them concurrently
+// and records the outcomes. It returns how many were attempted.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, dl := range deliveries {
+		wg.Add(1)
+		go func(dl delivery) {
+			defer wg.Done()
+			code, sendErr := d.send(ctx, dl)
+			if err := d.record(ctx, dl, code, sendErr); err != nil {
+				slog.Error("Error recording webhook delivery", "delivery", dl.id, "error", err)
+			}
+		}(dl)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// Claim due pending deliveries by pushing their next attempt past the lease.
+func (d *Dispatcher) claim(ctx context.Context) ([]delivery, error) {
+	rows, err := d.DB.QueryContext(ctx, `
+        WITH due AS (
+            SELECT id FROM webhook_deliveries
+            WHERE status = 'pending' AND next_attempt_at <= now()
+            ORDER BY next_attempt_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        ), claimed AS (
+            UPDATE webhook_deliveries d
+            SET next_attempt_at = now() + make_interval(secs => $2)
+            FROM due WHERE d.id = due.id
+            RETURNING d.id, d.subscription_id, d.event_id, d.attempts
+        )
+        SELECT c.id, c.attempts, s.url, s.secret, e.id, e.type, e.created_at, e.payload
+        FROM claimed c
+        JOIN webhook_subscriptions s ON s.id = c.subscription_id
+        JOIN events e ON e.id = c.event_id
+        ORDER BY c.id`, d.BatchSize, d.Lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []delivery
+	for rows.Next() {
+		var dl delivery
+		var payload []byte
+		err := rows.Scan(&dl.id, &dl.attempts, &dl.url, &dl.secret, &dl.event.ID, &dl.event.Type, &dl.event.CreatedAt, &payload)
+		if err != nil {
+			return nil, err
+		}
+		dl.event.Data = payload
+		deliveries = append(deliveries, dl)
+	}
+	return deliveries, rows.Err()
+}
+
+// Post the signed event, returning the response status code.
+func (d *Dispatcher) send(ctx context.Context, dl delivery) (int, error) {
+	body, err := json.Marshal(dl.event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "college-api-webhooks")
+	req.Header.Set(HeaderEvent, dl.event.Type)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(dl.id, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(dl.secret, now, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// Record an attempt: delivered on a 2xx response, otherwise retried after
+// Backoff or, once MaxAttempts have failed, dead.
+func (d *Dispatcher) record(ctx context.Context, dl delivery, code int, sendErr error) error {
+	attempts := dl.attempts + 1
+	status, next, lastError := StatusDelivered, time.Now(), ""
+	if sendErr != nil || code < 200 || code > 299 {
+		status, next = StatusPending, time.Now().Add(d.Backoff(attempts))
+		if attempts >= d.MaxAttempts {
+			status = StatusDead
+		}
+		lastError = "unexpected response status " + strconv.Itoa(code)
+		if sendErr != nil {
+			lastError = sendErr.Error()
+		}
+		slog.Warn("Webhook delivery failed", "delivery", dl.id, "attempts", attempts, "status", status, "error", lastError)
+	}
+
+	var lastCode sql.NullInt64
+	if code != 0 {
+		lastCode = sql.NullInt64{Int64: int64(code), Valid: true}
+	}
+	_, err := d.DB.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6,
+            delivered_at = CASE WHEN $2 = 'delivered' THEN now() END
+        WHERE id = $1`, dl.id, status, attempts, next, lastCode, lastError)
+	return err
+}
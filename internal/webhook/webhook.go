@@ -0,0 +1,58 @@
+// signing and retry schedule of outbound webhook deliveries
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"     // event type, e.g. enrollment.added
+	HeaderDelivery  = "X-Webhook-Delivery"  // delivery id, the same on every retry
+	HeaderTimestamp = "X-Webhook-Timestamp" // unix seconds the attempt was signed at
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" and the hex HMAC of timestamp "." body
+)
+
+// NewSecret returns a random signing secret for a subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+// Receivers recompute it with their copy of the secret and should reject
+// old timestamps to stop replays.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for body sent at timestamp.
+func Verify(secret string, timestamp time.Time, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Backoff returns how long to wait before retrying after attempt failures:
+// 30s doubling each time, capped at 6h.
+func Backoff(attempt int) time.Duration {
+	const max = 6 * time.Hour
+	delay := 30 * time.Second
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
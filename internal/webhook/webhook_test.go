@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSign tests that signatures verify only with the same secret,
+// timestamp and body.
+func TestSign(t *testing.T) {
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+	assert.Len(t, secret, len("whsec_")+64)
+
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	signature := Sign(secret, now, body)
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	assert.True(t, Verify(secret, now, body, signature))
+	assert.False(t, Verify("whsec_other", now, body, signature))
+	assert.False(t, Verify(secret, now.Add(time.Second), body, signature))
+	assert.False(t, Verify(secret, now, []byte(`{"id":2}`), signature))
+}
+
+// TestBackoff tests that the delay doubles from 30s up to the 6h cap.
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, 256*time.Minute, Backoff(10))
+	assert.Equal(t, 6*time.Hour, Backoff(11))
+	assert.Equal(t, 6*time.Hour, Backoff(100))
+}
+
+// TestDeliverDue tests sending claimed deliveries and recording a success,
+// a retry and a dead delivery.
+func TestDeliverDue(t *testing.T) {
+	var received []*http.Request
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/ok" {
+			received = append(received, r)
+			bodies = append(bodies, body)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	created := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("WITH due AS").WithArgs(20, 60.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "attempts", "url", "secret", "event_id", "type", "created_at", "payload"}).
+			AddRow(1, 0, receiver.URL+"/ok", "whsec_a", 7, "course.created", created, []byte(`{"id":4,"name":"History"}`)).
+			AddRow(2, 2, receiver.URL+"/fail", "whsec_b", 7, "course.created", created, []byte(`{"id":4,"name":"History"}`)).
+			AddRow(3, 9, receiver.URL+"/fail", "whsec_c", 7, "course.created", created, []byte(`{"id":4,"name":"History"}`)))
+	mock.ExpectExec("UPDATE webhook_deliveries").
+		WithArgs(1, StatusDelivered, 1, sqlmock.AnyArg(), 200, "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE webhook_deliveries").
+		WithArgs(2, StatusPending, 3, sqlmock.AnyArg(), 500, "unexpected response status 500").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE webhook_deliveries").
+		WithArgs(3, StatusDead, 10, sqlmock.AnyArg(), 500, "unexpected response status 500").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := NewDispatcher(db).DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Len(t, received, 1)
+	r := received[0]
+	assert.Equal(t, "course.created", r.Header.Get(HeaderEvent))
+	assert.Equal(t, "1", r.Header.Get(HeaderDelivery))
+	unix, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.True(t, Verify("whsec_a", time.Unix(unix, 0), bodies[0], r.Header.Get(HeaderSignature)))
+
+	var event map[string]interface{}
+	assert.NoError(t, json.Unmarshal(bodies[0], &event))
+	assert.Equal(t, map[string]interface{}{
+		"id":         float64(7),
+		"type":       "course.created",
+		"created_at": "2024-09-01T12:00:00Z",
+		"data":       map[string]interface{}{"id": float64(4), "name": "History"},
+	}, event)
+}
@@ -3,29 +3,216 @@
 package webserver
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
 
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/auth"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/grpcserver"
 	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/handlers"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/health"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/metrics"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/ratelimit"
 	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/routes"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/stream"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/tracing"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/webhook"
 )
 
-func NewServer(db *sql.DB) {
+func NewServer(db *sql.DB, m *metrics.Metrics) {
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	r.Use(logging.Middleware(slog.Default()))
+	r.Use(tracing.Middleware)
+	r.Use(m.Middleware)
 
-	handler := &handlers.RequestHandler{DB: db}
+	// scraped by prometheus and probed by the orchestrator, outside of
+	// authentication and rate limiting
+	m.RegisterDB(db)
+	r.Handle("/metrics", m.Handler())
+	checker := health.NewChecker(db)
+	r.Get("/healthz", checker.Liveness)
+	r.Get("/readyz", checker.Readiness)
+
+	handler := &handlers.RequestHandler{
+		DB:       db,
+		AdminKey: os.Getenv("ADMIN_API_KEY"),
+		JWT:      jwtVerifier(),
+		Limiter:  rateLimiter(db),
+		Events:   eventBroker(db),
+	}
 	routes.GetRoutes(r, handler)
 
-	log.Print("\nStarting server on port :8000\n")
-	err := http.ListenAndServe("localhost:8000", r)
+	server := &http.Server{Addr: "localhost:8000", Handler: r}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// listen for events committed by every replica; streams are closed once
+	// shutdown starts so they don't hold it up, and clients resume elsewhere
+	go handler.Events.Run(ctx)
+
+	go func() {
+		slog.Info("Starting server", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("Listen and serve error", err)
+		}
+	}()
+
+	// the grpc api runs next to the REST api on GRPC_ADDR (default
+	// localhost:9000)
+	grpcAddr := os.Getenv("GRPC_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = "localhost:9000"
+	}
+	grpcServer, grpcHealth := grpcserver.New(handler, handler.Limiter)
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		fatal("Error listening for grpc", err)
+	}
+	go func() {
+		slog.Info("Starting grpc server", "addr", grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			fatal("Grpc serve error", err)
+		}
+	}()
+
+	// deliver webhooks queued by changes until shutdown starts, whatever
+	// isn't sent yet is picked up by the next server
+	go webhook.NewDispatcher(db).Run(ctx)
+
+	<-ctx.Done()
+	shutdown(server, checker, grpcServer, grpcHealth)
+}
+
+// Fail readiness, give the orchestrator SHUTDOWN_DRAIN (default 5s) to stop
+// routing to this server, then wait up to 30s for in-flight requests and
+// calls.
+func shutdown(server *http.Server, checker *health.Checker, grpcServer *grpc.Server, grpcHealth *grpchealth.Server) {
+	drain := 5 * time.Second
+	if value := os.Getenv("SHUTDOWN_DRAIN"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			fatal("Error configuring shutdown", err)
+		}
+		drain = d
+	}
+
+	slog.Info("Shutting down", "drain", drain.String())
+	checker.ShutDown()
+	grpcHealth.Shutdown()
+	time.Sleep(drain)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Error shutting down server", "error", err)
+		return
+	}
+	<-stopped
+	slog.Info("Server stopped")
+}
+
+// Log a startup error and exit.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// Configure jwt bearer authentication from the environment. Returns nil,
+// disabling bearer tokens, when neither a secret nor a JWKS file is set.
+func jwtVerifier() *auth.JWTVerifier {
+	secret := os.Getenv("JWT_HS256_SECRET")
+	jwksFile := os.Getenv("JWT_JWKS_FILE")
+	if secret == "" && jwksFile == "" {
+		return nil
+	}
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+		HMACSecret: []byte(secret),
+		JWKSFile:   jwksFile,
+		Audience:   os.Getenv("JWT_AUDIENCE"),
+		Issuer:     os.Getenv("JWT_ISSUER"),
+		Leeway:     30 * time.Second,
+	})
 	if err != nil {
-		log.Fatal("Listen and server error: ", err)
+		fatal("Error configuring JWT authentication", err)
+	}
+	return verifier
+}
+
+// Configure the event stream broker from the environment. EVENT_HISTORY
+// (default 24h) is how far back a stream can resume with Last-Event-ID.
+func eventBroker(db *sql.DB) *stream.Broker {
+	broker := stream.NewBroker(db)
+	if value := os.Getenv("EVENT_HISTORY"); value != "" {
+		history, err := time.ParseDuration(value)
+		if err != nil {
+			fatal("Error configuring event history", err)
+		}
+		broker.History = history
+	}
+	return broker
+}
+
+// default rate limit of each route group in routes.GetRoutes
+var defaultRateLimits = map[string]string{
+	"ip":      "600/1m", // every api route per client IP, before authentication
+	"course":  "120/1m",
+	"person":  "60/1m",
+	"export":  "10/1m",
+	"admin":   "30/1m",
+	"graphql": "60/1m",
+	"events":  "30/1m", // per connection, streams stay open
+}
+
+// Configure rate limiting from the environment. RATE_LIMIT_BACKEND selects
+// memory (the default), postgres to share budgets across replicas, or off.
+// RATE_LIMIT_<GROUP> overrides a group's limit, e.g. RATE_LIMIT_PERSON=30/1m.
+func rateLimiter(db *sql.DB) *ratelimit.Limiter {
+	limiter := &ratelimit.Limiter{Limits: map[string]ratelimit.Limit{}}
+
+	switch backend := os.Getenv("RATE_LIMIT_BACKEND"); backend {
+	case "", "memory":
+		limiter.Store = ratelimit.NewMemoryStore()
+	case "postgres":
+		limiter.Store = &ratelimit.PostgresStore{DB: db}
+	case "off":
+		return nil
+	default:
+		fatal("Error configuring rate limits", fmt.Errorf("unknown RATE_LIMIT_BACKEND %q", backend))
 	}
 
+	for group, value := range defaultRateLimits {
+		if override := os.Getenv("RATE_LIMIT_" + strings.ToUpper(group)); override != "" {
+			value = override
+		}
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			fatal("Error configuring rate limits", err)
+		}
+		limiter.Limits[group] = limit
+	}
+	return limiter
 }
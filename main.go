@@ -1,18 +1,50 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"os"
 
 	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/database"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/logging"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/metrics"
+	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/tracing"
 	"github.com/maya-kuzak/Go-API-Tech-Challenge/internal/webserver"
 )
 
 func main() {
-	db, err := database.Init()
+	// .env is loaded first so every setting below can come from it
+	if err := database.LoadEnv(); err != nil {
+		slog.Error("Error loading environment", "error", err)
+		os.Exit(1)
+	}
+
+	// json logs on stdout, LOG_LEVEL is debug, info (the default), warn or error
+	level, err := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		slog.Error("Error configuring logging", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logging.New(os.Stdout, level))
+
+	// OTEL_TRACES_EXPORTER is stdout, otlp or unset to disable tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), os.Getenv("OTEL_TRACES_EXPORTER"))
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("Error configuring tracing", "error", err)
+		os.Exit(1)
 	}
 
-	webserver.NewServer(db)
+	m := metrics.New()
+	db, err := database.Init(m.ObserveQuery, tracing.QueryHook)
+	if err != nil {
+		slog.Error("Error initializing database", "error", err)
+		os.Exit(1)
+	}
+
+	webserver.NewServer(db, m)
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("Error flushing traces", "error", err)
+	}
 
 }
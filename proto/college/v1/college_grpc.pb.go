@@ -0,0 +1,1199 @@
+// grpc api mirroring the REST course, person and roster routes
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/college/v1/college.proto
+
+package collegev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CollegeService_ListCourses_FullMethodName        = "/college.v1.CollegeService/ListCourses"
+	CollegeService_GetCourse_FullMethodName          = "/college.v1.CollegeService/GetCourse"
+	CollegeService_CreateCourse_FullMethodName       = "/college.v1.CollegeService/CreateCourse"
+	CollegeService_UpdateCourse_FullMethodName       = "/college.v1.CollegeService/UpdateCourse"
+	CollegeService_DeleteCourse_FullMethodName       = "/college.v1.CollegeService/DeleteCourse"
+	CollegeService_ListRoster_FullMethodName         = "/college.v1.CollegeService/ListRoster"
+	CollegeService_Enroll_FullMethodName             = "/college.v1.CollegeService/Enroll"
+	CollegeService_Unenroll_FullMethodName           = "/college.v1.CollegeService/Unenroll"
+	CollegeService_ListPrerequisites_FullMethodName  = "/college.v1.CollegeService/ListPrerequisites"
+	CollegeService_AddPrerequisite_FullMethodName    = "/college.v1.CollegeService/AddPrerequisite"
+	CollegeService_RemovePrerequisite_FullMethodName = "/college.v1.CollegeService/RemovePrerequisite"
+	CollegeService_ListMeetings_FullMethodName       = "/college.v1.CollegeService/ListMeetings"
+	CollegeService_AddMeeting_FullMethodName         = "/college.v1.CollegeService/AddMeeting"
+	CollegeService_RemoveMeeting_FullMethodName      = "/college.v1.CollegeService/RemoveMeeting"
+	CollegeService_ListPeople_FullMethodName         = "/college.v1.CollegeService/ListPeople"
+	CollegeService_GetPerson_FullMethodName          = "/college.v1.CollegeService/GetPerson"
+	CollegeService_CreatePerson_FullMethodName       = "/college.v1.CollegeService/CreatePerson"
+	CollegeService_UpdatePerson_FullMethodName       = "/college.v1.CollegeService/UpdatePerson"
+	CollegeService_DeletePerson_FullMethodName       = "/college.v1.CollegeService/DeletePerson"
+	CollegeService_ListTerms_FullMethodName          = "/college.v1.CollegeService/ListTerms"
+	CollegeService_GetTerm_FullMethodName            = "/college.v1.CollegeService/GetTerm"
+	CollegeService_CreateTerm_FullMethodName         = "/college.v1.CollegeService/CreateTerm"
+	CollegeService_UpdateTerm_FullMethodName         = "/college.v1.CollegeService/UpdateTerm"
+	CollegeService_DeleteTerm_FullMethodName         = "/college.v1.CollegeService/DeleteTerm"
+	CollegeService_GetGradeScale_FullMethodName      = "/college.v1.CollegeService/GetGradeScale"
+	CollegeService_SetGradeScale_FullMethodName      = "/college.v1.CollegeService/SetGradeScale"
+	CollegeService_SetGrade_FullMethodName           = "/college.v1.CollegeService/SetGrade"
+	CollegeService_GetGPA_FullMethodName             = "/college.v1.CollegeService/GetGPA"
+)
+
+// CollegeServiceClient is the client API for CollegeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CollegeService serves the same operations as the REST api with the same
+// roles: anyone may read courses, staff may read people and rosters, the
+// registrar manages terms, courses, prerequisites, meetings, people and the
+// grade scale, professors manage the rosters of the courses they teach, and
+// only those professors grade them. Rosters and
+// people's courses belong to a term; term_id 0 means the current term, the
+// latest to have started.
+type CollegeServiceClient interface {
+	ListCourses(ctx context.Context, in *ListCoursesRequest, opts ...grpc.CallOption) (*ListCoursesResponse, error)
+	GetCourse(ctx context.Context, in *GetCourseRequest, opts ...grpc.CallOption) (*Course, error)
+	CreateCourse(ctx context.Context, in *CreateCourseRequest, opts ...grpc.CallOption) (*Course, error)
+	UpdateCourse(ctx context.Context, in *UpdateCourseRequest, opts ...grpc.CallOption) (*Course, error)
+	DeleteCourse(ctx context.Context, in *DeleteCourseRequest, opts ...grpc.CallOption) (*DeleteCourseResponse, error)
+	ListRoster(ctx context.Context, in *ListRosterRequest, opts ...grpc.CallOption) (*ListRosterResponse, error)
+	Enroll(ctx context.Context, in *EnrollRequest, opts ...grpc.CallOption) (*Enrollment, error)
+	Unenroll(ctx context.Context, in *UnenrollRequest, opts ...grpc.CallOption) (*UnenrollResponse, error)
+	ListPrerequisites(ctx context.Context, in *ListPrerequisitesRequest, opts ...grpc.CallOption) (*ListPrerequisitesResponse, error)
+	// AddPrerequisite fails with FAILED_PRECONDITION when the prerequisite
+	// would form a cycle.
+	AddPrerequisite(ctx context.Context, in *AddPrerequisiteRequest, opts ...grpc.CallOption) (*AddPrerequisiteResponse, error)
+	RemovePrerequisite(ctx context.Context, in *RemovePrerequisiteRequest, opts ...grpc.CallOption) (*RemovePrerequisiteResponse, error)
+	ListMeetings(ctx context.Context, in *ListMeetingsRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error)
+	// AddMeeting fails with FAILED_PRECONDITION when the meeting clashes with
+	// the schedule of anyone on the course.
+	AddMeeting(ctx context.Context, in *AddMeetingRequest, opts ...grpc.CallOption) (*Meeting, error)
+	RemoveMeeting(ctx context.Context, in *RemoveMeetingRequest, opts ...grpc.CallOption) (*RemoveMeetingResponse, error)
+	// ListPeople streams each matching person with their course ids.
+	ListPeople(ctx context.Context, in *ListPeopleRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Person], error)
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error)
+	CreatePerson(ctx context.Context, in *CreatePersonRequest, opts ...grpc.CallOption) (*Person, error)
+	UpdatePerson(ctx context.Context, in *UpdatePersonRequest, opts ...grpc.CallOption) (*Person, error)
+	DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*DeletePersonResponse, error)
+	ListTerms(ctx context.Context, in *ListTermsRequest, opts ...grpc.CallOption) (*ListTermsResponse, error)
+	// GetTerm returns the current term when id is 0.
+	GetTerm(ctx context.Context, in *GetTermRequest, opts ...grpc.CallOption) (*Term, error)
+	// CreateTerm and UpdateTerm fail with FAILED_PRECONDITION when another
+	// term has the name or overlaps the dates.
+	CreateTerm(ctx context.Context, in *CreateTermRequest, opts ...grpc.CallOption) (*Term, error)
+	UpdateTerm(ctx context.Context, in *UpdateTermRequest, opts ...grpc.CallOption) (*Term, error)
+	// DeleteTerm fails with FAILED_PRECONDITION when anyone is enrolled in the
+	// term.
+	DeleteTerm(ctx context.Context, in *DeleteTermRequest, opts ...grpc.CallOption) (*DeleteTermResponse, error)
+	GetGradeScale(ctx context.Context, in *GetGradeScaleRequest, opts ...grpc.CallOption) (*GradeScale, error)
+	// SetGradeScale replaces the grade scale; grades already given keep their
+	// points.
+	SetGradeScale(ctx context.Context, in *SetGradeScaleRequest, opts ...grpc.CallOption) (*GradeScale, error)
+	// SetGrade fails with NOT_FOUND when the person isn't on the course's
+	// roster in the term.
+	SetGrade(ctx context.Context, in *SetGradeRequest, opts ...grpc.CallOption) (*Grade, error)
+	// GetGPA is open to staff and to students for themselves.
+	GetGPA(ctx context.Context, in *GetGPARequest, opts ...grpc.CallOption) (*GPA, error)
+}
+
+type collegeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCollegeServiceClient(cc grpc.ClientConnInterface) CollegeServiceClient {
+	return &collegeServiceClient{cc}
+}
+
+func (c *collegeServiceClient) ListCourses(ctx context.Context, in *ListCoursesRequest, opts ...grpc.CallOption) (*ListCoursesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCoursesResponse)
+	err := c.cc.Invoke(ctx, CollegeService_ListCourses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) GetCourse(ctx context.Context, in *GetCourseRequest, opts ...grpc.CallOption) (*Course, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Course)
+	err := c.cc.Invoke(ctx, CollegeService_GetCourse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) CreateCourse(ctx context.Context, in *CreateCourseRequest, opts ...grpc.CallOption) (*Course, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Course)
+	err := c.cc.Invoke(ctx, CollegeService_CreateCourse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) UpdateCourse(ctx context.Context, in *UpdateCourseRequest, opts ...grpc.CallOption) (*Course, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Course)
+	err := c.cc.Invoke(ctx, CollegeService_UpdateCourse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) DeleteCourse(ctx context.Context, in *DeleteCourseRequest, opts ...grpc.CallOption) (*DeleteCourseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCourseResponse)
+	err := c.cc.Invoke(ctx, CollegeService_DeleteCourse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) ListRoster(ctx context.Context, in *ListRosterRequest, opts ...grpc.CallOption) (*ListRosterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRosterResponse)
+	err := c.cc.Invoke(ctx, CollegeService_ListRoster_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) Enroll(ctx context.Context, in *EnrollRequest, opts ...grpc.CallOption) (*Enrollment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Enrollment)
+	err := c.cc.Invoke(ctx, CollegeService_Enroll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) Unenroll(ctx context.Context, in *UnenrollRequest, opts ...grpc.CallOption) (*UnenrollResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnenrollResponse)
+	err := c.cc.Invoke(ctx, CollegeService_Unenroll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) ListPrerequisites(ctx context.Context, in *ListPrerequisitesRequest, opts ...grpc.CallOption) (*ListPrerequisitesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPrerequisitesResponse)
+	err := c.cc.Invoke(ctx, CollegeService_ListPrerequisites_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) AddPrerequisite(ctx context.Context, in *AddPrerequisiteRequest, opts ...grpc.CallOption) (*AddPrerequisiteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddPrerequisiteResponse)
+	err := c.cc.Invoke(ctx, CollegeService_AddPrerequisite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) RemovePrerequisite(ctx context.Context, in *RemovePrerequisiteRequest, opts ...grpc.CallOption) (*RemovePrerequisiteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemovePrerequisiteResponse)
+	err := c.cc.Invoke(ctx, CollegeService_RemovePrerequisite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) ListMeetings(ctx context.Context, in *ListMeetingsRequest, opts ...grpc.CallOption) (*ListMeetingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMeetingsResponse)
+	err := c.cc.Invoke(ctx, CollegeService_ListMeetings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) AddMeeting(ctx context.Context, in *AddMeetingRequest, opts ...grpc.CallOption) (*Meeting, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Meeting)
+	err := c.cc.Invoke(ctx, CollegeService_AddMeeting_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) RemoveMeeting(ctx context.Context, in *RemoveMeetingRequest, opts ...grpc.CallOption) (*RemoveMeetingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMeetingResponse)
+	err := c.cc.Invoke(ctx, CollegeService_RemoveMeeting_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) ListPeople(ctx context.Context, in *ListPeopleRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Person], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollegeService_ServiceDesc.Streams[0], CollegeService_ListPeople_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListPeopleRequest, Person]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollegeService_ListPeopleClient = grpc.ServerStreamingClient[Person]
+
+func (c *collegeServiceClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Person)
+	err := c.cc.Invoke(ctx, CollegeService_GetPerson_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) CreatePerson(ctx context.Context, in *CreatePersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Person)
+	err := c.cc.Invoke(ctx, CollegeService_CreatePerson_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) UpdatePerson(ctx context.Context, in *UpdatePersonRequest, opts ...grpc.CallOption) (*Person, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Person)
+	err := c.cc.Invoke(ctx, CollegeService_UpdatePerson_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) DeletePerson(ctx context.Context, in *DeletePersonRequest, opts ...grpc.CallOption) (*DeletePersonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePersonResponse)
+	err := c.cc.Invoke(ctx, CollegeService_DeletePerson_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) ListTerms(ctx context.Context, in *ListTermsRequest, opts ...grpc.CallOption) (*ListTermsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTermsResponse)
+	err := c.cc.Invoke(ctx, CollegeService_ListTerms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) GetTerm(ctx context.Context, in *GetTermRequest, opts ...grpc.CallOption) (*Term, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Term)
+	err := c.cc.Invoke(ctx, CollegeService_GetTerm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) CreateTerm(ctx context.Context, in *CreateTermRequest, opts ...grpc.CallOption) (*Term, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Term)
+	err := c.cc.Invoke(ctx, CollegeService_CreateTerm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) UpdateTerm(ctx context.Context, in *UpdateTermRequest, opts ...grpc.CallOption) (*Term, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Term)
+	err := c.cc.Invoke(ctx, CollegeService_UpdateTerm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) DeleteTerm(ctx context.Context, in *DeleteTermRequest, opts ...grpc.CallOption) (*DeleteTermResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTermResponse)
+	err := c.cc.Invoke(ctx, CollegeService_DeleteTerm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) GetGradeScale(ctx context.Context, in *GetGradeScaleRequest, opts ...grpc.CallOption) (*GradeScale, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GradeScale)
+	err := c.cc.Invoke(ctx, CollegeService_GetGradeScale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) SetGradeScale(ctx context.Context, in *SetGradeScaleRequest, opts ...grpc.CallOption) (*GradeScale, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GradeScale)
+	err := c.cc.Invoke(ctx, CollegeService_SetGradeScale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) SetGrade(ctx context.Context, in *SetGradeRequest, opts ...grpc.CallOption) (*Grade, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Grade)
+	err := c.cc.Invoke(ctx, CollegeService_SetGrade_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collegeServiceClient) GetGPA(ctx context.Context, in *GetGPARequest, opts ...grpc.CallOption) (*GPA, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GPA)
+	err := c.cc.Invoke(ctx, CollegeService_GetGPA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CollegeServiceServer is the server API for CollegeService service.
+// All implementations must embed UnimplementedCollegeServiceServer
+// for forward compatibility.
+//
+// CollegeService serves the same operations as the REST api with the same
+// roles: anyone may read courses, staff may read people and rosters, the
+// registrar manages terms, courses, prerequisites, meetings, people and the
+// grade scale, professors manage the rosters of the courses they teach, and
+// only those professors grade them. Rosters and
+// people's courses belong to a term; term_id 0 means the current term, the
+// latest to have started.
+type CollegeServiceServer interface {
+	ListCourses(context.Context, *ListCoursesRequest) (*ListCoursesResponse, error)
+	GetCourse(context.Context, *GetCourseRequest) (*Course, error)
+	CreateCourse(context.Context, *CreateCourseRequest) (*Course, error)
+	UpdateCourse(context.Context, *UpdateCourseRequest) (*Course, error)
+	DeleteCourse(context.Context, *DeleteCourseRequest) (*DeleteCourseResponse, error)
+	ListRoster(context.Context, *ListRosterRequest) (*ListRosterResponse, error)
+	Enroll(context.Context, *EnrollRequest) (*Enrollment, error)
+	Unenroll(context.Context, *UnenrollRequest) (*UnenrollResponse, error)
+	ListPrerequisites(context.Context, *ListPrerequisitesRequest) (*ListPrerequisitesResponse, error)
+	// AddPrerequisite fails with FAILED_PRECONDITION when the prerequisite
+	// would form a cycle.
+	AddPrerequisite(context.Context, *AddPrerequisiteRequest) (*AddPrerequisiteResponse, error)
+	RemovePrerequisite(context.Context, *RemovePrerequisiteRequest) (*RemovePrerequisiteResponse, error)
+	ListMeetings(context.Context, *ListMeetingsRequest) (*ListMeetingsResponse, error)
+	// AddMeeting fails with FAILED_PRECONDITION when the meeting clashes with
+	// the schedule of anyone on the course.
+	AddMeeting(context.Context, *AddMeetingRequest) (*Meeting, error)
+	RemoveMeeting(context.Context, *RemoveMeetingRequest) (*RemoveMeetingResponse, error)
+	// ListPeople streams each matching person with their course ids.
+	ListPeople(*ListPeopleRequest, grpc.ServerStreamingServer[Person]) error
+	GetPerson(context.Context, *GetPersonRequest) (*Person, error)
+	CreatePerson(context.Context, *CreatePersonRequest) (*Person, error)
+	UpdatePerson(context.Context, *UpdatePersonRequest) (*Person, error)
+	DeletePerson(context.Context, *DeletePersonRequest) (*DeletePersonResponse, error)
+	ListTerms(context.Context, *ListTermsRequest) (*ListTermsResponse, error)
+	// GetTerm returns the current term when id is 0.
+	GetTerm(context.Context, *GetTermRequest) (*Term, error)
+	// CreateTerm and UpdateTerm fail with FAILED_PRECONDITION when another
+	// term has the name or overlaps the dates.
+	CreateTerm(context.Context, *CreateTermRequest) (*Term, error)
+	UpdateTerm(context.Context, *UpdateTermRequest) (*Term, error)
+	// DeleteTerm fails with FAILED_PRECONDITION when anyone is enrolled in the
+	// term.
+	DeleteTerm(context.Context, *DeleteTermRequest) (*DeleteTermResponse, error)
+	GetGradeScale(context.Context, *GetGradeScaleRequest) (*GradeScale, error)
+	// SetGradeScale replaces the grade scale; grades already given keep their
+	// points.
+	SetGradeScale(context.Context, *SetGradeScaleRequest) (*GradeScale, error)
+	// SetGrade fails with NOT_FOUND when the person isn't on the course's
+	// roster in the term.
+	SetGrade(context.Context, *SetGradeRequest) (*Grade, error)
+	// GetGPA is open to staff and to students for themselves.
+	GetGPA(context.Context, *GetGPARequest) (*GPA, error)
+	mustEmbedUnimplementedCollegeServiceServer()
+}
+
+// UnimplementedCollegeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCollegeServiceServer struct{}
+
+func (UnimplementedCollegeServiceServer) ListCourses(context.Context, *ListCoursesRequest) (*ListCoursesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCourses not implemented")
+}
+func (UnimplementedCollegeServiceServer) GetCourse(context.Context, *GetCourseRequest) (*Course, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCourse not implemented")
+}
+func (UnimplementedCollegeServiceServer) CreateCourse(context.Context, *CreateCourseRequest) (*Course, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCourse not implemented")
+}
+func (UnimplementedCollegeServiceServer) UpdateCourse(context.Context, *UpdateCourseRequest) (*Course, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCourse not implemented")
+}
+func (UnimplementedCollegeServiceServer) DeleteCourse(context.Context, *DeleteCourseRequest) (*DeleteCourseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCourse not implemented")
+}
+func (UnimplementedCollegeServiceServer) ListRoster(context.Context, *ListRosterRequest) (*ListRosterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRoster not implemented")
+}
+func (UnimplementedCollegeServiceServer) Enroll(context.Context, *EnrollRequest) (*Enrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Enroll not implemented")
+}
+func (UnimplementedCollegeServiceServer) Unenroll(context.Context, *UnenrollRequest) (*UnenrollResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unenroll not implemented")
+}
+func (UnimplementedCollegeServiceServer) ListPrerequisites(context.Context, *ListPrerequisitesRequest) (*ListPrerequisitesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPrerequisites not implemented")
+}
+func (UnimplementedCollegeServiceServer) AddPrerequisite(context.Context, *AddPrerequisiteRequest) (*AddPrerequisiteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPrerequisite not implemented")
+}
+func (UnimplementedCollegeServiceServer) RemovePrerequisite(context.Context, *RemovePrerequisiteRequest) (*RemovePrerequisiteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemovePrerequisite not implemented")
+}
+func (UnimplementedCollegeServiceServer) ListMeetings(context.Context, *ListMeetingsRequest) (*ListMeetingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMeetings not implemented")
+}
+func (UnimplementedCollegeServiceServer) AddMeeting(context.Context, *AddMeetingRequest) (*Meeting, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMeeting not implemented")
+}
+func (UnimplementedCollegeServiceServer) RemoveMeeting(context.Context, *RemoveMeetingRequest) (*RemoveMeetingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMeeting not implemented")
+}
+func (UnimplementedCollegeServiceServer) ListPeople(*ListPeopleRequest, grpc.ServerStreamingServer[Person]) error {
+	return status.Errorf(codes.Unimplemented, "method ListPeople not implemented")
+}
+func (UnimplementedCollegeServiceServer) GetPerson(context.Context, *GetPersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedCollegeServiceServer) CreatePerson(context.Context, *CreatePersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePerson not implemented")
+}
+func (UnimplementedCollegeServiceServer) UpdatePerson(context.Context, *UpdatePersonRequest) (*Person, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePerson not implemented")
+}
+func (UnimplementedCollegeServiceServer) DeletePerson(context.Context, *DeletePersonRequest) (*DeletePersonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePerson not implemented")
+}
+func (UnimplementedCollegeServiceServer) ListTerms(context.Context, *ListTermsRequest) (*ListTermsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTerms not implemented")
+}
+func (UnimplementedCollegeServiceServer) GetTerm(context.Context, *GetTermRequest) (*Term, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTerm not implemented")
+}
+func (UnimplementedCollegeServiceServer) CreateTerm(context.Context, *CreateTermRequest) (*Term, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTerm not implemented")
+}
+func (UnimplementedCollegeServiceServer) UpdateTerm(context.Context, *UpdateTermRequest) (*Term, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTerm not implemented")
+}
+func (UnimplementedCollegeServiceServer) DeleteTerm(context.Context, *DeleteTermRequest) (*DeleteTermResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTerm not implemented")
+}
+func (UnimplementedCollegeServiceServer) GetGradeScale(context.Context, *GetGradeScaleRequest) (*GradeScale, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGradeScale not implemented")
+}
+func (UnimplementedCollegeServiceServer) SetGradeScale(context.Context, *SetGradeScaleRequest) (*GradeScale, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGradeScale not implemented")
+}
+func (UnimplementedCollegeServiceServer) SetGrade(context.Context, *SetGradeRequest) (*Grade, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetGrade not implemented")
+}
+func (UnimplementedCollegeServiceServer) GetGPA(context.Context, *GetGPARequest) (*GPA, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGPA not implemented")
+}
+func (UnimplementedCollegeServiceServer) mustEmbedUnimplementedCollegeServiceServer() {}
+func (UnimplementedCollegeServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeCollegeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CollegeServiceServer will
+// result in compilation errors.
+type UnsafeCollegeServiceServer interface {
+	mustEmbedUnimplementedCollegeServiceServer()
+}
+
+func RegisterCollegeServiceServer(s grpc.ServiceRegistrar, srv CollegeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCollegeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CollegeService_ServiceDesc, srv)
+}
+
+func _CollegeService_ListCourses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCoursesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).ListCourses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_ListCourses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).ListCourses(ctx, req.(*ListCoursesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_GetCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).GetCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_GetCourse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).GetCourse(ctx, req.(*GetCourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_CreateCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).CreateCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_CreateCourse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).CreateCourse(ctx, req.(*CreateCourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_UpdateCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).UpdateCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_UpdateCourse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).UpdateCourse(ctx, req.(*UpdateCourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_DeleteCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).DeleteCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_DeleteCourse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).DeleteCourse(ctx, req.(*DeleteCourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_ListRoster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRosterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).ListRoster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_ListRoster_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).ListRoster(ctx, req.(*ListRosterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_Enroll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).Enroll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_Enroll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).Enroll(ctx, req.(*EnrollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_Unenroll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnenrollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).Unenroll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_Unenroll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).Unenroll(ctx, req.(*UnenrollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_ListPrerequisites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPrerequisitesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).ListPrerequisites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_ListPrerequisites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).ListPrerequisites(ctx, req.(*ListPrerequisitesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_AddPrerequisite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddPrerequisiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).AddPrerequisite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_AddPrerequisite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).AddPrerequisite(ctx, req.(*AddPrerequisiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_RemovePrerequisite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemovePrerequisiteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).RemovePrerequisite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_RemovePrerequisite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).RemovePrerequisite(ctx, req.(*RemovePrerequisiteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_ListMeetings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMeetingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).ListMeetings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_ListMeetings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).ListMeetings(ctx, req.(*ListMeetingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_AddMeeting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMeetingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).AddMeeting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_AddMeeting_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).AddMeeting(ctx, req.(*AddMeetingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_RemoveMeeting_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMeetingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).RemoveMeeting(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_RemoveMeeting_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).RemoveMeeting(ctx, req.(*RemoveMeetingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_ListPeople_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPeopleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollegeServiceServer).ListPeople(m, &grpc.GenericServerStream[ListPeopleRequest, Person]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollegeService_ListPeopleServer = grpc.ServerStreamingServer[Person]
+
+func _CollegeService_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_CreatePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).CreatePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_CreatePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).CreatePerson(ctx, req.(*CreatePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_UpdatePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).UpdatePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_UpdatePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).UpdatePerson(ctx, req.(*UpdatePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_DeletePerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).DeletePerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_DeletePerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).DeletePerson(ctx, req.(*DeletePersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_ListTerms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTermsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).ListTerms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_ListTerms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).ListTerms(ctx, req.(*ListTermsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_GetTerm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTermRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).GetTerm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_GetTerm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).GetTerm(ctx, req.(*GetTermRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_CreateTerm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTermRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).CreateTerm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_CreateTerm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).CreateTerm(ctx, req.(*CreateTermRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_UpdateTerm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTermRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).UpdateTerm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_UpdateTerm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).UpdateTerm(ctx, req.(*UpdateTermRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_DeleteTerm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTermRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).DeleteTerm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_DeleteTerm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).DeleteTerm(ctx, req.(*DeleteTermRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_GetGradeScale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGradeScaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).GetGradeScale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_GetGradeScale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).GetGradeScale(ctx, req.(*GetGradeScaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_SetGradeScale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetGradeScaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).SetGradeScale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_SetGradeScale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).SetGradeScale(ctx, req.(*SetGradeScaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_SetGrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetGradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).SetGrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_SetGrade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).SetGrade(ctx, req.(*SetGradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollegeService_GetGPA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGPARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollegeServiceServer).GetGPA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollegeService_GetGPA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollegeServiceServer).GetGPA(ctx, req.(*GetGPARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CollegeService_ServiceDesc is the grpc.ServiceDesc for CollegeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CollegeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "college.v1.CollegeService",
+	HandlerType: (*CollegeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCourses",
+			Handler:    _CollegeService_ListCourses_Handler,
+		},
+		{
+			MethodName: "GetCourse",
+			Handler:    _CollegeService_GetCourse_Handler,
+		},
+		{
+			MethodName: "CreateCourse",
+			Handler:    _CollegeService_CreateCourse_Handler,
+		},
+		{
+			MethodName: "UpdateCourse",
+			Handler:    _CollegeService_UpdateCourse_Handler,
+		},
+		{
+			MethodName: "DeleteCourse",
+			Handler:    _CollegeService_DeleteCourse_Handler,
+		},
+		{
+			MethodName: "ListRoster",
+			Handler:    _CollegeService_ListRoster_Handler,
+		},
+		{
+			MethodName: "Enroll",
+			Handler:    _CollegeService_Enroll_Handler,
+		},
+		{
+			MethodName: "Unenroll",
+			Handler:    _CollegeService_Unenroll_Handler,
+		},
+		{
+			MethodName: "ListPrerequisites",
+			Handler:    _CollegeService_ListPrerequisites_Handler,
+		},
+		{
+			MethodName: "AddPrerequisite",
+			Handler:    _CollegeService_AddPrerequisite_Handler,
+		},
+		{
+			MethodName: "RemovePrerequisite",
+			Handler:    _CollegeService_RemovePrerequisite_Handler,
+		},
+		{
+			MethodName: "ListMeetings",
+			Handler:    _CollegeService_ListMeetings_Handler,
+		},
+		{
+			MethodName: "AddMeeting",
+			Handler:    _CollegeService_AddMeeting_Handler,
+		},
+		{
+			MethodName: "RemoveMeeting",
+			Handler:    _CollegeService_RemoveMeeting_Handler,
+		},
+		{
+			MethodName: "GetPerson",
+			Handler:    _CollegeService_GetPerson_Handler,
+		},
+		{
+			MethodName: "CreatePerson",
+			Handler:    _CollegeService_CreatePerson_Handler,
+		},
+		{
+			MethodName: "UpdatePerson",
+			Handler:    _CollegeService_UpdatePerson_Handler,
+		},
+		{
+			MethodName: "DeletePerson",
+			Handler:    _CollegeService_DeletePerson_Handler,
+		},
+		{
+			MethodName: "ListTerms",
+			Handler:    _CollegeService_ListTerms_Handler,
+		},
+		{
+			MethodName: "GetTerm",
+			Handler:    _CollegeService_GetTerm_Handler,
+		},
+		{
+			MethodName: "CreateTerm",
+			Handler:    _CollegeService_CreateTerm_Handler,
+		},
+		{
+			MethodName: "UpdateTerm",
+			Handler:    _CollegeService_UpdateTerm_Handler,
+		},
+		{
+			MethodName: "DeleteTerm",
+			Handler:    _CollegeService_DeleteTerm_Handler,
+		},
+		{
+			MethodName: "GetGradeScale",
+			Handler:    _CollegeService_GetGradeScale_Handler,
+		},
+		{
+			MethodName: "SetGradeScale",
+			Handler:    _CollegeService_SetGradeScale_Handler,
+		},
+		{
+			MethodName: "SetGrade",
+			Handler:    _CollegeService_SetGrade_Handler,
+		},
+		{
+			MethodName: "GetGPA",
+			Handler:    _CollegeService_GetGPA_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListPeople",
+			Handler:       _CollegeService_ListPeople_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/college/v1/college.proto",
+}